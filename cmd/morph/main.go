@@ -5,6 +5,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/VzoelFox/morphlang/pkg/analysis"
@@ -25,7 +26,12 @@ func main() {
 		}
 	}()
 
-	var debugMode, checkMode, useVMMode bool
+	if len(os.Args) > 1 && os.Args[1] == "ast" {
+		runAST(os.Args[2:])
+		return
+	}
+
+	var debugMode, checkMode, useVMMode, strictMode bool
 	var filename string
 
 	// Hybrid Flag Parsing: Support both `morph compile --debug` and `morph --debug compile`
@@ -35,6 +41,7 @@ func main() {
 		compileCmd.BoolVar(&debugMode, "debug", false, "Enable debug output")
 		compileCmd.BoolVar(&checkMode, "check", false, "Check syntax only")
 		compileCmd.BoolVar(&useVMMode, "vm", false, "Run using Bytecode VM")
+		compileCmd.BoolVar(&strictMode, "strict", false, "Treat type annotation mismatches as errors")
 
 		compileCmd.Parse(os.Args[2:])
 		args := compileCmd.Args()
@@ -49,6 +56,7 @@ func main() {
 		flag.BoolVar(&debugMode, "debug", false, "Enable debug output")
 		flag.BoolVar(&checkMode, "check", false, "Check syntax only")
 		flag.BoolVar(&useVMMode, "vm", false, "Run using Bytecode VM")
+		flag.BoolVar(&strictMode, "strict", false, "Treat type annotation mismatches as errors")
 		flag.Parse()
 
 		args := flag.Args()
@@ -76,6 +84,21 @@ func main() {
 	}
 	input := string(content)
 
+	// An AST exported by `morph ast --json` is compiled as is
+	if strings.HasSuffix(filename, ".json") {
+		program := &parser.Program{}
+		if err := json.Unmarshal(content, program); err != nil {
+			fmt.Printf("Error reading AST: %v\n", err)
+			os.Exit(1)
+		}
+		if debugMode {
+			fmt.Println("\n--- Parser Output ---")
+			fmt.Println(program.String())
+		}
+		compileAndRun(program, filename, useVMMode, checkMode, strictMode)
+		return
+	}
+
 	// Lexer for Parsing
 	l := lexer.New(input)
 
@@ -144,9 +167,14 @@ func main() {
 		os.Exit(1)
 	}
 
+	compileAndRun(program, filename, useVMMode, checkMode, strictMode)
+}
+
+func compileAndRun(program *parser.Program, filename string, useVMMode, checkMode, strictMode bool) {
 	// VM Execution
 	if useVMMode {
 		comp := compiler.New()
+		comp.Strict = strictMode
 		err := comp.Compile(program)
 		if err != nil {
 			fmt.Printf("Compilation failed:\n%s\n", err)
@@ -164,7 +192,8 @@ func main() {
 
 	// Compilation Check (Ensure logic validity)
 	comp := compiler.New()
-	err = comp.Compile(program)
+	comp.Strict = strictMode
+	err := comp.Compile(program)
 	if err != nil {
 		fmt.Printf("Compilation failed:\n%s\n", err)
 		os.Exit(1)
@@ -174,3 +203,45 @@ func main() {
 		fmt.Printf("Successfully compiled %s\n", filename)
 	}
 }
+
+// runAST implements `morph ast [--json] <file>`: it prints the syntax tree of
+// a source file, as JSON that `morph <file>.json` accepts back.
+func runAST(args []string) {
+	astCmd := flag.NewFlagSet("ast", flag.ExitOnError)
+	jsonMode := astCmd.Bool("json", false, "Print the AST as JSON")
+	astCmd.Parse(args)
+
+	if astCmd.NArg() < 1 {
+		fmt.Println("Usage: morph ast [--json] <file>")
+		os.Exit(1)
+	}
+	filename := astCmd.Arg(0)
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		fmt.Printf("Error reading file: %v\n", err)
+		os.Exit(1)
+	}
+
+	p := parser.New(lexer.New(string(content)))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		fmt.Printf("Parsing failed with %d errors.\n", len(p.Errors()))
+		for _, msg := range p.Errors() {
+			fmt.Println(msg)
+		}
+		os.Exit(1)
+	}
+
+	if !*jsonMode {
+		fmt.Println(program.String())
+		return
+	}
+
+	out, err := json.MarshalIndent(program, "", "  ")
+	if err != nil {
+		fmt.Printf("Error encoding AST: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(string(out))
+}
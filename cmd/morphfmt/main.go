@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/VzoelFox/morphlang/pkg/format"
+)
+
+var (
+	write = flag.Bool("w", false, "Write the result back to the source file instead of stdout")
+	diff  = flag.Bool("d", false, "Print a diff instead of the formatted source")
+	check = flag.Bool("check", false, "List unformatted files and exit with status 1 if there are any")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: morphfmt [-w | -d | -check] [path ...]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		if *write {
+			fmt.Fprintln(os.Stderr, "morphfmt: cannot use -w with standard input")
+			os.Exit(2)
+		}
+		src, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "morphfmt: %v\n", err)
+			os.Exit(2)
+		}
+		os.Exit(processFile("<stdin>", src))
+	}
+
+	status := 0
+	for _, path := range flag.Args() {
+		err := filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			// Directories are searched for .fox files; named files are always formatted
+			if d.IsDir() || (file != path && !strings.HasSuffix(file, ".fox")) {
+				return nil
+			}
+			src, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			if s := processFile(file, src); s > status {
+				status = s
+			}
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "morphfmt: %v\n", err)
+			status = 2
+		}
+	}
+	os.Exit(status)
+}
+
+// processFile formats one file according to the flags and returns its exit
+// status: 0 when done, 1 when -check found it unformatted, 2 on errors.
+func processFile(name string, src []byte) int {
+	out, err := format.Source(src)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+		return 2
+	}
+	changed := !bytes.Equal(src, out)
+
+	switch {
+	case *check:
+		if changed {
+			fmt.Println(name)
+			return 1
+		}
+	case *diff:
+		if changed {
+			fmt.Print(unifiedDiff(name, string(src), string(out)))
+		}
+	case *write:
+		if changed {
+			info, err := os.Stat(name)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "morphfmt: %v\n", err)
+				return 2
+			}
+			if err := os.WriteFile(name, out, info.Mode().Perm()); err != nil {
+				fmt.Fprintf(os.Stderr, "morphfmt: %v\n", err)
+				return 2
+			}
+		}
+	default:
+		os.Stdout.Write(out)
+	}
+	return 0
+}
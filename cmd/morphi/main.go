@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/VzoelFox/morphlang/pkg/analysis"
+	"github.com/VzoelFox/morphlang/pkg/compiler"
+	"github.com/VzoelFox/morphlang/pkg/lexer"
+	"github.com/VzoelFox/morphlang/pkg/memory"
+	"github.com/VzoelFox/morphlang/pkg/object"
+	"github.com/VzoelFox/morphlang/pkg/parser"
+	"github.com/VzoelFox/morphlang/pkg/vm"
+)
+
+const (
+	prompt         = ">> "
+	continuePrompt = ".. "
+)
+
+const help = `Perintah:
+  :ast [kode]       tampilkan AST (default: input terakhir)
+  :bytecode         tampilkan bytecode input terakhir
+  :context          tampilkan konteks analisis sesi ini
+  :reset            hapus semua definisi
+  :keluar           keluar (atau Ctrl-D)`
+
+// session holds everything that must survive between inputs: the constant
+// pool, the global symbol table and the VM globals they index.
+type session struct {
+	state   *compiler.CompilerState
+	symbols *compiler.SymbolTable
+	globals []memory.Ptr
+
+	source    string             // Every input that ran, for :context
+	last      string             // Last input that ran
+	lastCode  *compiler.Bytecode // Its bytecode, for :bytecode
+	lastConst int                // First constant added by it
+	out       io.Writer
+}
+
+func newSession(out io.Writer) *session {
+	return &session{
+		state:   compiler.NewState(),
+		symbols: compiler.NewSymbolTable(),
+		globals: make([]memory.Ptr, vm.GlobalSize),
+		out:     out,
+	}
+}
+
+func main() {
+	fmt.Println("Morph REPL. Ketik :bantuan untuk daftar perintah.")
+	run(os.Stdin, os.Stdout)
+}
+
+// run reads inputs until EOF. Lines are buffered while the parser only
+// complains about reaching EOF, so blocks can span several lines.
+func run(in io.Reader, out io.Writer) {
+	s := newSession(out)
+	scanner := bufio.NewScanner(in)
+	buffer := ""
+
+	fmt.Fprint(out, prompt)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if buffer == "" && strings.HasPrefix(strings.TrimSpace(line), ":") {
+			if !s.command(strings.TrimSpace(line)) {
+				return
+			}
+			fmt.Fprint(out, prompt)
+			continue
+		}
+
+		buffer += line + "\n"
+		if strings.TrimSpace(buffer) == "" {
+			buffer = ""
+		} else if program, errs := parse(buffer); !incomplete(errs) {
+			s.eval(buffer, program, errs)
+			buffer = ""
+		}
+
+		if buffer == "" {
+			fmt.Fprint(out, prompt)
+		} else {
+			fmt.Fprint(out, continuePrompt)
+		}
+	}
+	fmt.Fprintln(out)
+}
+
+func parse(input string) (*parser.Program, []parser.ParserError) {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	return program, p.Errors()
+}
+
+// incomplete reports whether the input stopped in the middle of a construct,
+// such as a block without its `akhir` or an unclosed bracket.
+func incomplete(errs []parser.ParserError) bool {
+	for _, e := range errs {
+		if e.Found == string(lexer.EOF) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *session) eval(input string, program *parser.Program, errs []parser.ParserError) {
+	if len(errs) > 0 {
+		for _, e := range errs {
+			fmt.Fprintln(s.out, e)
+		}
+		return
+	}
+
+	firstConst := len(s.state.Constants)
+	// A failed input must not leave half of its definitions behind
+	restoreState := s.state.Snapshot()
+	restoreSymbols := s.symbols.Snapshot()
+	comp := compiler.NewWithSymbols(s.state, s.symbols)
+	if err := comp.Compile(program); err != nil {
+		restoreState()
+		restoreSymbols()
+		fmt.Fprintf(s.out, "Compilation failed: %s\n", err)
+		return
+	}
+	s.source += input
+	s.last = input
+	s.lastCode = comp.Bytecode()
+	s.lastConst = firstConst
+
+	machine := vm.NewWithGlobals(s.lastCode, s.globals)
+	defer machine.Close()
+	if err := machine.Run(); err != nil {
+		fmt.Fprintf(s.out, "Runtime error: %s\n", err)
+		return
+	}
+
+	// Only expression statements leave a value worth echoing
+	n := len(program.Statements)
+	if n == 0 || machine.LastPoppedPtr == memory.NilPtr {
+		return
+	}
+	stmt, ok := program.Statements[n-1].(*parser.ExpressionStatement)
+	if !ok {
+		return
+	}
+	if fn, ok := stmt.Expression.(*parser.FunctionLiteral); ok && fn.Name != "" {
+		return
+	}
+	if obj := machine.GetLastPopped(); obj != nil {
+		fmt.Fprintln(s.out, obj.Inspect())
+	}
+}
+
+// command runs a meta-command. It returns false when the REPL should exit.
+func (s *session) command(line string) bool {
+	name, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+
+	switch name {
+	case ":ast":
+		if program, ok := s.target(arg); ok {
+			fmt.Fprintln(s.out, program.String())
+		}
+
+	case ":bytecode":
+		if s.lastCode == nil {
+			fmt.Fprintln(s.out, "Belum ada input.")
+			return true
+		}
+		fmt.Fprint(s.out, s.lastCode.Instructions.String())
+		for i := s.lastConst; i < len(s.lastCode.Constants); i++ {
+			if fn, ok := s.lastCode.Constants[i].(*object.CompiledFunction); ok {
+				fmt.Fprintf(s.out, "\nconstant %d (fungsi):\n%s", i, compiler.Instructions(fn.Instructions()).String())
+			}
+		}
+
+	case ":context":
+		program, errs := parse(s.source)
+		ctx, err := analysis.GenerateContext(program, "<repl>", s.source, errs)
+		if err != nil {
+			fmt.Fprintf(s.out, "Analysis error: %v\n", err)
+			return true
+		}
+		out, err := json.MarshalIndent(ctx, "", "  ")
+		if err != nil {
+			fmt.Fprintf(s.out, "Error writing context: %v\n", err)
+			return true
+		}
+		fmt.Fprintln(s.out, string(out))
+
+	case ":reset":
+		*s = *newSession(s.out)
+		fmt.Fprintln(s.out, "Sesi direset.")
+
+	case ":keluar":
+		return false
+
+	case ":bantuan":
+		fmt.Fprintln(s.out, help)
+
+	default:
+		fmt.Fprintf(s.out, "Perintah tidak dikenal: %s (ketik :bantuan)\n", name)
+	}
+	return true
+}
+
+// target parses the argument of :ast, or the last input when there is none.
+func (s *session) target(arg string) (*parser.Program, bool) {
+	input := arg
+	if input == "" {
+		input = s.last
+	}
+	if input == "" {
+		fmt.Fprintln(s.out, "Belum ada input.")
+		return nil, false
+	}
+	program, errs := parse(input)
+	if len(errs) > 0 {
+		for _, e := range errs {
+			fmt.Fprintln(s.out, e)
+		}
+		return nil, false
+	}
+	return program, true
+}
@@ -3,6 +3,7 @@ package analysis
 import (
 	"crypto/sha256"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -10,12 +11,20 @@ import (
 )
 
 type Analyzer struct {
-	program    *parser.Program
-	filename   string
-	input      string
-	context    *Context
-	currFunc   string
-	scopeStack []map[string]bool // Stack of defined variables to simulate scope lookups
+	program     *parser.Program
+	filename    string
+	input       string
+	context     *Context
+	currFunc    string
+	scopeStack  []map[string]bool // Stack of defined variables to simulate scope lookups; true marks a constant
+	namedCalls  []namedCall       // Calls with named arguments, checked once every symbol is known
+	variantRefs []variantRef      // Member accesses that may name a variant of a pilihan
+	ifChains    []ifChain         // jika chains that may test a pilihan value
+	typedCalls  []typedCall       // Calls to check against declared parameter and field types
+	typeRefs    []typeRef         // Type annotations, checked once every struktur and pilihan is known
+	imports     map[string]bool   // Names brought in by `dari ... ambil`
+	importsAll  bool              // Whether a plain `ambil` may define any name
+	currParams  []Parameter       // Parameters of the function being analyzed
 }
 
 func GenerateContext(program *parser.Program, filename string, input string, parserErrors []parser.ParserError) (*Context, error) {
@@ -44,12 +53,16 @@ func GenerateContext(program *parser.Program, filename string, input string, par
 	// Parse Errors
 	for _, err := range parserErrors {
 		ctx.Errors = append(ctx.Errors, ParserError{
-			Level:   string(err.Level),
-			Message: err.Message,
-			Line:    err.Line,
-			Column:  err.Column,
-			File:    filename,
-			Context: err.Context,
+			Level:    string(err.Level),
+			Code:     err.Code,
+			Message:  err.Message,
+			Line:     err.Line,
+			Column:   err.Column,
+			File:     filename,
+			Context:  err.Context,
+			Expected: err.Expected,
+			Found:    err.Found,
+			Span:     newSpan(err.Span),
 		})
 	}
 
@@ -93,6 +106,10 @@ func (a *Analyzer) analyze() {
 	for _, stmt := range a.program.Statements {
 		a.analyzeTopLevel(stmt)
 	}
+	a.checkNamedArguments()
+	a.checkCallTypes()
+	a.checkVariants()
+	a.checkTypeNames()
 	// Calculate complexity summary
 	a.context.Complexity.LinesOfCode = a.context.Statistics.CodeLines
 }
@@ -111,12 +128,25 @@ func (a *Analyzer) isDefined(name string) bool {
 // defineInCurrentScope marks a variable as defined in the current top scope
 func (a *Analyzer) defineInCurrentScope(name string) {
 	if len(a.scopeStack) > 0 {
-		a.scopeStack[len(a.scopeStack)-1][name] = true
+		scope := a.scopeStack[len(a.scopeStack)-1]
+		if _, ok := scope[name]; !ok {
+			scope[name] = false
+		}
 	}
 }
 
 func (a *Analyzer) analyzeTopLevel(stmt parser.Statement) {
 	switch s := stmt.(type) {
+	case *parser.ImportStatement:
+		if len(s.Identifiers) == 0 {
+			a.importsAll = true
+		}
+		for _, name := range s.Identifiers {
+			if a.imports == nil {
+				a.imports = make(map[string]bool)
+			}
+			a.imports[name] = true
+		}
 	case *parser.ExpressionStatement:
 		// Check for function literal: `fungsi name(...) ...`
 		if fn, ok := s.Expression.(*parser.FunctionLiteral); ok {
@@ -127,17 +157,108 @@ func (a *Analyzer) analyzeTopLevel(stmt parser.Statement) {
 		}
 	case *parser.AssignmentStatement:
 		// Global variable
-		if ident, ok := s.Name.(*parser.Identifier); ok {
+		if ident, ok := s.Name.(*parser.Identifier); ok && !a.checkMutation(ident) {
 			name := ident.Value
 			inferredType := a.inferType(s.Value)
+			declared := a.checkAssignmentType(s)
+			if prev, ok := a.context.GlobalVars[name]; ok && declared == nil {
+				declared = prev.Declared
+			}
 
-			a.context.GlobalVars[name] = &Variable{
-				Line: s.Token.Line,
-				Type: inferredType,
+			v := &Variable{
+				Line:     s.Token.Line,
+				Type:     inferredType,
+				Declared: declared,
 			}
+			if declared != nil {
+				v.Type = declaredType(declared)
+			}
+			a.context.GlobalVars[name] = v
 			a.defineInCurrentScope(name) // Mark global var
 		}
 		a.walkExpression(s.Value, func(node parser.Node) {})
+	case *parser.ConstStatement:
+		name := s.Name.Value
+		if a.isDefined(name) {
+			a.checkMutation(s.Name)
+		} else {
+			a.context.GlobalVars[name] = &Variable{
+				Line:     s.Token.Line,
+				Type:     a.inferType(s.Value),
+				Constant: true,
+			}
+			a.defineConstant(name)
+		}
+		a.walkExpression(s.Value, func(node parser.Node) {})
+	case *parser.DestructuringStatement:
+		for _, ident := range patternBindings(s.Target) {
+			if a.checkMutation(ident) {
+				continue
+			}
+			a.context.GlobalVars[ident.Value] = &Variable{
+				Line: ident.Token.Line,
+				Type: "any",
+			}
+			a.defineInCurrentScope(ident.Value)
+		}
+		a.walkExpression(s.Value, func(node parser.Node) {})
+	case *parser.EnumStatement:
+		sym := &Symbol{
+			Type:   "enum",
+			Line:   s.Token.Line,
+			Column: s.Token.Column,
+			Span:   newSpan(s.Span()),
+			Doc:    s.Doc,
+		}
+		for _, v := range s.Variants {
+			variant := EnumVariant{Name: v.Name.Value}
+			if v.Fields != nil {
+				variant.Fields = []string{}
+			}
+			for _, f := range v.Fields {
+				variant.Fields = append(variant.Fields, f.Value)
+			}
+			sym.Variants = append(sym.Variants, variant)
+		}
+		a.context.Symbols[s.Name.Value] = sym
+		a.defineConstant(s.Name.Value)
+
+	case *parser.StructStatement:
+		fields := []string{}
+		for _, f := range s.Fields {
+			fields = append(fields, f.Value)
+		}
+		sym := &Symbol{
+			Type:   "struct",
+			Line:   s.Token.Line,
+			Column: s.Token.Column,
+			Span:   newSpan(s.Span()),
+			Doc:    s.Doc,
+			Fields: fields,
+		}
+		for i, f := range s.Fields {
+			if t := s.FieldType(i); t != nil {
+				a.recordTypeRef(t, a.currFunc)
+				if sym.FieldTypes == nil {
+					sym.FieldTypes = make(map[string][]string)
+				}
+				sym.FieldTypes[f.Value] = t.Types()
+			}
+		}
+		a.context.Symbols[s.Name.Value] = sym
+		a.defineInCurrentScope(s.Name.Value)
+
+		// Methods are analyzed as functions named Struktur.metode, with the
+		// implicit receiver left out of their parameters
+		if len(s.Methods) > 0 {
+			sym.Methods = make(map[string]*Symbol)
+		}
+		for _, m := range s.Methods {
+			method := a.functionSymbol(m.WithReceiver(), s.Name.Value+"."+m.Name)
+			method.Type = "method"
+			method.Parameters = method.Parameters[1:]
+			sym.Methods[m.Name] = method
+		}
 	}
 }
 
@@ -146,11 +267,17 @@ func (a *Analyzer) analyzeFunction(fn *parser.FunctionLiteral) {
 	if name == "" {
 		name = "<anonymous>"
 	}
+	a.context.Symbols[name] = a.functionSymbol(fn, name)
+}
 
+// functionSymbol analyzes the body of fn, recording its call graph and local
+// scope under name, and returns its symbol.
+func (a *Analyzer) functionSymbol(fn *parser.FunctionLiteral, name string) *Symbol {
 	sym := &Symbol{
 		Type:            "function",
 		Line:            fn.Token.Line,
 		Column:          fn.Token.Column,
+		Span:            newSpan(fn.Span()),
 		Parameters:      []Parameter{},
 		LocalVars:       []string{},
 		Calls:           []string{},
@@ -158,21 +285,38 @@ func (a *Analyzer) analyzeFunction(fn *parser.FunctionLiteral) {
 		Doc:             fn.Doc,
 	}
 
-	for _, p := range fn.Parameters {
-		sym.Parameters = append(sym.Parameters, Parameter{
+	for i, p := range fn.Parameters {
+		param := Parameter{
 			Name:         p.Value,
 			InferredType: "any",
 			Line:         p.Token.Line,
 			Column:       p.Token.Column,
-		})
+		}
+		if def := fn.Default(i); def != nil {
+			param.Default = def.String()
+			if t := a.inferType(def); t != "unknown" {
+				param.InferredType = t
+			}
+		}
+		if t := fn.ParamType(i); t != nil {
+			a.recordTypeRef(t, name)
+			param.Types = t.Types()
+			param.InferredType = declaredType(param.Types)
+		}
+		if fn.Variadic && i == len(fn.Parameters)-1 {
+			param.Variadic = true
+			param.InferredType = "array"
+		}
+		sym.Parameters = append(sym.Parameters, param)
 	}
 
-	a.context.Symbols[name] = sym
+	a.recordTypeRef(fn.ReturnType, name)
+
 	a.context.CallGraph[name] = []string{}
 	a.context.LocalScopes[name] = make(LocalScope)
 
-	prevFunc := a.currFunc
-	a.currFunc = name
+	prevFunc, prevParams := a.currFunc, a.currParams
+	a.currFunc, a.currParams = name, sym.Parameters
 
 	// Push new scope for function
 	a.scopeStack = append(a.scopeStack, make(map[string]bool))
@@ -206,6 +350,10 @@ func (a *Analyzer) analyzeFunction(fn *parser.FunctionLiteral) {
 				canError = true
 			}
 		}
+		// Returned values must have the declared type
+		if ret, ok := node.(*parser.ReturnStatement); ok && fn.ReturnType != nil && ret.ReturnValue != nil {
+			a.checkType(ret.ReturnValue, fn.ReturnType.Types(), "return value of "+name)
+		}
 		// Check for returns galat (direct)
 		if ret, ok := node.(*parser.ReturnStatement); ok {
 			if ret.ReturnValue != nil {
@@ -218,21 +366,37 @@ func (a *Analyzer) analyzeFunction(fn *parser.FunctionLiteral) {
 				}
 			}
 		}
+		// `coba` returns any galat it sees from this function
+		if try, ok := node.(*parser.TryExpression); ok {
+			sym.ErrorConditions = append(sym.ErrorConditions, ErrorCond{
+				Condition: "adalah_galat(" + try.Value.String() + ")",
+				Message:   "propagated by coba",
+				Line:      try.Token.Line,
+			})
+			canError = true
+		}
 		// Local vars logic (Closure Aware)
 		if assign, ok := node.(*parser.AssignmentStatement); ok {
-			if ident, ok := assign.Name.(*parser.Identifier); ok {
+			if ident, ok := assign.Name.(*parser.Identifier); ok && !a.checkMutation(ident) {
 				varName := ident.Value
 
 				// Infer Type
 				inferred := a.inferType(assign.Value)
+				declared := a.checkAssignmentType(assign)
+				if declared != nil {
+					inferred = declaredType(declared)
+				}
 
 				// Check if variable is defined in any scope up the chain
 				if a.isDefined(varName) {
 					// It's an UPDATE. Check if it's local in CURRENT function scope to update type
 					if v, ok := a.context.LocalScopes[name][varName]; ok {
-						if v.Type == "inferred" || v.Type == "unknown" {
+						if v.Type == "inferred" || v.Type == "unknown" || declared != nil {
 							v.Type = inferred
 						}
+						if declared != nil {
+							v.Declared = declared
+						}
 					}
 				} else {
 					// It's a NEW declaration in this scope
@@ -249,13 +413,56 @@ func (a *Analyzer) analyzeFunction(fn *parser.FunctionLiteral) {
 					if !found {
 						sym.LocalVars = append(sym.LocalVars, varName)
 						a.context.LocalScopes[name][varName] = &Variable{
-							Line: assign.Token.Line,
-							Type: inferred,
+							Line:     assign.Token.Line,
+							Type:     inferred,
+							Declared: declared,
 						}
 					}
 				}
 			}
 		}
+		if constant, ok := node.(*parser.ConstStatement); ok {
+			varName := constant.Name.Value
+			if _, ok := a.scopeStack[len(a.scopeStack)-1][varName]; ok {
+				a.checkMutation(constant.Name)
+			} else {
+				a.defineConstant(varName)
+				sym.LocalVars = append(sym.LocalVars, varName)
+				a.context.LocalScopes[name][varName] = &Variable{
+					Line:     constant.Token.Line,
+					Type:     a.inferType(constant.Value),
+					Constant: true,
+				}
+			}
+		}
+		// Loop variables of `untuk` and names bound by `kasus` patterns or
+		// destructuring are locals too
+		boundVars := []*parser.Identifier{}
+		if destructure, ok := node.(*parser.DestructuringStatement); ok {
+			boundVars = append(boundVars, patternBindings(destructure.Target)...)
+		}
+		if forIn, ok := node.(*parser.ForInExpression); ok {
+			boundVars = append(boundVars, forIn.Value)
+			if forIn.Key != nil {
+				boundVars = append(boundVars, forIn.Key)
+			}
+		}
+		if match, ok := node.(*parser.MatchExpression); ok {
+			for _, arm := range match.Arms {
+				boundVars = append(boundVars, patternBindings(arm.Pattern)...)
+			}
+		}
+		for _, ident := range boundVars {
+			if a.checkMutation(ident) || a.isDefined(ident.Value) {
+				continue
+			}
+			a.defineInCurrentScope(ident.Value)
+			sym.LocalVars = append(sym.LocalVars, ident.Value)
+			a.context.LocalScopes[name][ident.Value] = &Variable{
+				Line: ident.Token.Line,
+				Type: "any",
+			}
+		}
 		// Complexity
 		if _, ok := node.(*parser.IfExpression); ok {
 			complexity++
@@ -263,22 +470,38 @@ func (a *Analyzer) analyzeFunction(fn *parser.FunctionLiteral) {
 		if _, ok := node.(*parser.WhileExpression); ok {
 			complexity++
 		}
+		if _, ok := node.(*parser.ForInExpression); ok {
+			complexity++
+		}
+		if match, ok := node.(*parser.MatchExpression); ok {
+			complexity += len(match.Arms)
+		}
 	})
 
 	// Pop scope
 	a.scopeStack = a.scopeStack[:len(a.scopeStack)-1]
 
+	// The last expression is returned too, so a trailing galat(...) counts
+	// like `kembalikan galat(...)`
+	if endsWithError(fn.Body) {
+		canError = true
+	}
 	sym.CanError = canError
 	if canError {
 		sym.Returns = &TypeInfo{Type: "union", Types: []string{"any", "error"}}
 	}
+	if fn.ReturnType != nil {
+		types := fn.ReturnType.Types()
+		sym.Returns = &TypeInfo{Type: declaredType(types), Types: types}
+	}
 
 	if complexity > a.context.Complexity.Cyclomatic {
 		a.context.Complexity.Cyclomatic += complexity
 	}
 
-	a.currFunc = prevFunc
+	a.currFunc, a.currParams = prevFunc, prevParams
 	a.context.Complexity.Functions++
+	return sym
 }
 
 func (a *Analyzer) walkBlock(block *parser.BlockStatement, visitor func(parser.Node)) {
@@ -302,6 +525,10 @@ func (a *Analyzer) walkStatement(stmt parser.Statement, visitor func(parser.Node
 	case *parser.AssignmentStatement:
 		a.walkExpression(s.Name, visitor)
 		a.walkExpression(s.Value, visitor)
+	case *parser.DestructuringStatement:
+		a.walkExpression(s.Value, visitor)
+	case *parser.ConstStatement:
+		a.walkExpression(s.Value, visitor)
 	}
 }
 
@@ -317,18 +544,64 @@ func (a *Analyzer) walkExpression(expr parser.Expression, visitor func(parser.No
 		a.walkExpression(e.Right, visitor)
 	case *parser.PrefixExpression:
 		a.walkExpression(e.Right, visitor)
+	case *parser.TryExpression:
+		a.walkExpression(e.Value, visitor)
+	case *parser.IndexExpression:
+		a.recordVariantRef(e)
+		a.walkExpression(e.Left, visitor)
+		a.walkExpression(e.Index, visitor)
+	case *parser.SliceExpression:
+		a.walkExpression(e.Left, visitor)
+		a.walkExpression(e.Start, visitor)
+		a.walkExpression(e.End, visitor)
+	case *parser.RangeExpression:
+		a.walkExpression(e.Start, visitor)
+		a.walkExpression(e.End, visitor)
+	case *parser.ArrayLiteral:
+		for _, el := range e.Elements {
+			a.walkExpression(el, visitor)
+		}
+	case *parser.HashLiteral:
+		// Same key order as the compiler so results are deterministic
+		keys := []parser.Expression{}
+		for k := range e.Pairs {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		for _, k := range keys {
+			a.walkExpression(k, visitor)
+			a.walkExpression(e.Pairs[k], visitor)
+		}
 	case *parser.IfExpression:
+		a.recordIfChain(e)
 		a.walkExpression(e.Condition, visitor)
 		a.walkBlock(e.Consequence, visitor)
 		a.walkBlock(e.Alternative, visitor)
 	case *parser.WhileExpression:
 		a.walkExpression(e.Condition, visitor)
 		a.walkBlock(e.Body, visitor)
+	case *parser.ForInExpression:
+		a.walkExpression(e.Iterable, visitor)
+		a.walkBlock(e.Body, visitor)
+	case *parser.MatchExpression:
+		a.walkExpression(e.Subject, visitor)
+		for _, arm := range e.Arms {
+			a.walkExpression(arm.Guard, visitor)
+			a.walkBlock(arm.Body, visitor)
+		}
+		a.walkBlock(e.Alternative, visitor)
+		a.checkMatchExhaustive(e)
 	case *parser.CallExpression:
 		a.walkExpression(e.Function, visitor)
 		for _, arg := range e.Arguments {
 			a.walkExpression(arg, visitor)
 		}
+		if len(e.NamedArguments()) > 0 {
+			a.namedCalls = append(a.namedCalls, namedCall{call: e, function: a.currFunc})
+		}
+		a.recordCall(e)
+	case *parser.NamedArgument:
+		a.walkExpression(e.Value, visitor)
 	case *parser.InterpolatedString:
 		for _, part := range e.Parts {
 			a.walkExpression(part, visitor)
@@ -348,6 +621,16 @@ func (a *Analyzer) inferType(expr parser.Expression) string {
 		return "string"
 	case *parser.BooleanLiteral:
 		return "boolean"
+	case *parser.NullLiteral:
+		return "kosong"
+	case *parser.ArrayLiteral:
+		return "array"
+	case *parser.HashLiteral:
+		return "hash"
+	case *parser.FunctionLiteral:
+		return "function"
+	case *parser.RangeExpression:
+		return "range"
 	case *parser.Identifier:
 		// Check local scope
 		if a.currFunc != "" {
@@ -356,6 +639,11 @@ func (a *Analyzer) inferType(expr parser.Expression) string {
 					return v.Type
 				}
 			}
+			for _, p := range a.currParams {
+				if p.Name == e.Value {
+					return p.InferredType
+				}
+			}
 			// Check params
 			if sym, ok := a.context.Symbols[a.currFunc]; ok {
 				for _, p := range sym.Parameters {
@@ -370,12 +658,27 @@ func (a *Analyzer) inferType(expr parser.Expression) string {
 			return v.Type
 		}
 		return "unknown"
+	case *parser.IndexExpression:
+		// Variants are typed by their pilihan, like tipe() does
+		if enum, _, _, ok := a.enumVariant(e); ok {
+			return enum
+		}
+		return "unknown"
 	case *parser.CallExpression:
+		if enum, _, _, ok := a.enumVariant(e.Function); ok {
+			return enum
+		}
 		if ident, ok := e.Function.(*parser.Identifier); ok {
 			if ident.Value == "galat" {
 				return "error"
 			}
+			if ident.Value == "desimal" || ident.Value == "bagi_desimal" {
+				return "decimal"
+			}
 			if sym, ok := a.context.Symbols[ident.Value]; ok {
+				if sym.Type == "struct" {
+					return ident.Value
+				}
 				if sym.Returns != nil && len(sym.Returns.Types) > 0 {
 					if len(sym.Returns.Types) == 1 {
 						return sym.Returns.Types[0]
@@ -390,8 +693,26 @@ func (a *Analyzer) inferType(expr parser.Expression) string {
 		case "==", "!=", "<", ">", "<=", ">=":
 			return "boolean"
 		default:
-			return a.inferType(e.Left)
+			left, right := a.inferType(e.Left), a.inferType(e.Right)
+			switch {
+			case left == right:
+				return left
+			case e.Operator == "??" && left == "kosong", e.Operator == "?!" && left == "error":
+				return right
+			case e.Operator == "+" && (left == "string" || right == "string"):
+				return "string"
+			case (left == "integer" && right == "float") || (left == "float" && right == "integer"):
+				return "float"
+			case (left == "integer" && right == "decimal") || (left == "decimal" && right == "integer"):
+				return "decimal"
+			}
+			return "unknown"
+		}
+	case *parser.PrefixExpression:
+		if e.Operator == "!" {
+			return "boolean"
 		}
+		return a.inferType(e.Right)
 	}
 	return "unknown"
 }
@@ -416,6 +737,26 @@ func containsReturnError(block *parser.BlockStatement) bool {
 	return false
 }
 
+// endsWithError reports whether the implicit result of block, its last
+// expression or that of either branch of a trailing jika, is galat(...).
+func endsWithError(block *parser.BlockStatement) bool {
+	if block == nil || len(block.Statements) == 0 {
+		return false
+	}
+	stmt, ok := block.Statements[len(block.Statements)-1].(*parser.ExpressionStatement)
+	if !ok {
+		return false
+	}
+	switch e := stmt.Expression.(type) {
+	case *parser.CallExpression:
+		ident, ok := e.Function.(*parser.Identifier)
+		return ok && ident.Value == "galat"
+	case *parser.IfExpression:
+		return endsWithError(e.Consequence) || endsWithError(e.Alternative)
+	}
+	return false
+}
+
 func extractErrorMessage(block *parser.BlockStatement) string {
 	if block == nil {
 		return ""
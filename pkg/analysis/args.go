@@ -0,0 +1,85 @@
+package analysis
+
+import (
+	"fmt"
+
+	"github.com/VzoelFox/morphlang/pkg/parser"
+)
+
+// WarnBadNamedArgument flags a named argument the callee has no slot for,
+// or one whose slot is already filled.
+const WarnBadNamedArgument = "W002"
+
+type namedCall struct {
+	call     *parser.CallExpression
+	function string // Enclosing function, for the warning
+}
+
+// checkNamedArguments validates the names used in calls to functions and
+// structs defined in this file. Calls through other values are only checked
+// at runtime.
+func (a *Analyzer) checkNamedArguments() {
+	for _, nc := range a.namedCalls {
+		ident, ok := nc.call.Function.(*parser.Identifier)
+		if !ok {
+			continue
+		}
+		sym, ok := a.context.Symbols[ident.Value]
+		if !ok {
+			continue
+		}
+
+		var slots []string
+		switch sym.Type {
+		case "function":
+			for _, p := range sym.Parameters {
+				if !p.Variadic {
+					slots = append(slots, p.Name)
+				}
+			}
+		case "struct":
+			slots = sym.Fields
+		default:
+			continue
+		}
+
+		filled := map[string]bool{}
+		positional := len(nc.call.Arguments) - len(nc.call.NamedArguments())
+		for i := 0; i < positional && i < len(slots); i++ {
+			filled[slots[i]] = true
+		}
+
+		for _, arg := range nc.call.NamedArguments() {
+			name := arg.Name.Value
+			switch {
+			case !contains(slots, name):
+				a.addArgumentWarning(nc, arg, fmt.Sprintf("%s has no parameter or field named '%s'", ident.Value, name))
+			case filled[name]:
+				a.addArgumentWarning(nc, arg, fmt.Sprintf("argument '%s' of %s is given more than once", name, ident.Value))
+			}
+			filled[name] = true
+		}
+	}
+}
+
+func (a *Analyzer) addArgumentWarning(nc namedCall, arg *parser.NamedArgument, msg string) {
+	a.context.Warnings = append(a.context.Warnings, Warning{
+		Code:     WarnBadNamedArgument,
+		Type:     "bad_named_argument",
+		Line:     arg.Token.Line,
+		Column:   arg.Token.Column,
+		Message:  msg,
+		Severity: "warning",
+		Function: nc.function,
+		Span:     newSpan(arg.Span()),
+	})
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package analysis
+
+import (
+	"fmt"
+
+	"github.com/VzoelFox/morphlang/pkg/parser"
+)
+
+// WarnConstantMutation flags an assignment to a `tetap` binding, which the
+// compiler rejects.
+const WarnConstantMutation = "W003"
+
+// defineConstant marks name as a constant of the current scope.
+func (a *Analyzer) defineConstant(name string) {
+	if len(a.scopeStack) > 0 {
+		a.scopeStack[len(a.scopeStack)-1][name] = true
+	}
+}
+
+// isConstant reports whether name resolves to a constant. Parameters and
+// locals of inner functions shadow outer constants.
+func (a *Analyzer) isConstant(name string) bool {
+	for i := len(a.scopeStack) - 1; i >= 0; i-- {
+		if constant, ok := a.scopeStack[i][name]; ok {
+			return constant
+		}
+	}
+	return false
+}
+
+// checkMutation warns when ident, the target of an assignment or binding,
+// is a constant. It reports whether a warning was added.
+func (a *Analyzer) checkMutation(ident *parser.Identifier) bool {
+	if !a.isConstant(ident.Value) {
+		return false
+	}
+	a.context.Warnings = append(a.context.Warnings, Warning{
+		Code:     WarnConstantMutation,
+		Type:     "constant_mutation",
+		Line:     ident.Token.Line,
+		Column:   ident.Token.Column,
+		Message:  fmt.Sprintf("cannot assign to constant %s", ident.Value),
+		Severity: "error",
+		Function: a.currFunc,
+		Variable: ident.Value,
+		Span:     newSpan(ident.Span()),
+	})
+	return true
+}
@@ -2,6 +2,8 @@ package analysis
 
 import (
 	"time"
+
+	"github.com/VzoelFox/morphlang/pkg/parser"
 )
 
 type Context struct {
@@ -22,31 +24,48 @@ type Context struct {
 }
 
 type Symbol struct {
-	Type            string      `json:"type"` // "function", "variable"
-	Line            int         `json:"line"`
-	Column          int         `json:"column"`
-	Parameters      []Parameter `json:"parameters,omitempty"`
-	Returns         *TypeInfo   `json:"returns,omitempty"`
-	CanError        bool        `json:"can_error,omitempty"`
-	ErrorConditions []ErrorCond `json:"error_conditions,omitempty"`
-	Doc             string      `json:"doc,omitempty"`
-	Calls           []string    `json:"calls,omitempty"`
-	LocalVars       []string    `json:"local_variables,omitempty"`
+	Type            string              `json:"type"` // "function", "variable", "struct", "enum", "method"
+	Line            int                 `json:"line"`
+	Column          int                 `json:"column"`
+	Span            *Span               `json:"span,omitempty"`
+	Parameters      []Parameter         `json:"parameters,omitempty"`
+	Returns         *TypeInfo           `json:"returns,omitempty"`
+	CanError        bool                `json:"can_error,omitempty"`
+	ErrorConditions []ErrorCond         `json:"error_conditions,omitempty"`
+	Doc             string              `json:"doc,omitempty"`
+	Calls           []string            `json:"calls,omitempty"`
+	LocalVars       []string            `json:"local_variables,omitempty"`
+	Fields          []string            `json:"fields,omitempty"`      // Struct fields, in order
+	FieldTypes      map[string][]string `json:"field_types,omitempty"` // Declared types of struct fields
+	Methods         map[string]*Symbol  `json:"methods,omitempty"`     // Struct methods, without the `ini` parameter
+	Variants        []EnumVariant       `json:"variants,omitempty"`    // Variants of a pilihan, in order
+}
+
+// EnumVariant is a variant of a pilihan. Fields is nil for a variant value
+// and non-nil for a constructor.
+type EnumVariant struct {
+	Name   string   `json:"name"`
+	Fields []string `json:"fields"`
 }
 
 type Variable struct {
-	Line         int    `json:"line"`
-	Type         string `json:"type"` // inferred type
-	InitialValue string `json:"initial_value,omitempty"`
+	Line         int      `json:"line"`
+	Type         string   `json:"type"` // inferred type
+	InitialValue string   `json:"initial_value,omitempty"`
+	Constant     bool     `json:"constant,omitempty"`       // Declared with `tetap`
+	Declared     []string `json:"declared_types,omitempty"` // As in `x: string = ...`
 }
 
 type LocalScope map[string]*Variable
 
 type Parameter struct {
-	Name         string `json:"name"`
-	InferredType string `json:"inferred_type"`
-	Line         int    `json:"line"`
-	Column       int    `json:"column"`
+	Name         string   `json:"name"`
+	InferredType string   `json:"inferred_type"`
+	Line         int      `json:"line"`
+	Column       int      `json:"column"`
+	Default      string   `json:"default,omitempty"`        // Source of the default value, if optional
+	Variadic     bool     `json:"variadic,omitempty"`       // Collects the remaining arguments as an array
+	Types        []string `json:"declared_types,omitempty"` // Declared as in `a: integer`
 }
 
 type TypeInfo struct {
@@ -61,12 +80,16 @@ type ErrorCond struct {
 }
 
 type ParserError struct {
-	Level   string `json:"level"`
-	Line    int    `json:"line"`
-	Column  int    `json:"column"`
-	Message string `json:"message"`
-	File    string `json:"file"`
-	Context string `json:"context"`
+	Level    string `json:"level"`
+	Code     string `json:"code,omitempty"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Message  string `json:"message"`
+	File     string `json:"file"`
+	Context  string `json:"context"`
+	Expected string `json:"expected,omitempty"`
+	Found    string `json:"found,omitempty"`
+	Span     *Span  `json:"span,omitempty"`
 }
 
 type Warning struct {
@@ -78,6 +101,34 @@ type Warning struct {
 	Severity string `json:"severity"`
 	Function string `json:"function,omitempty"`
 	Variable string `json:"variable,omitempty"`
+	Span     *Span  `json:"span,omitempty"`
+}
+
+// Span is the source range of a symbol or diagnostic. EndCol points just
+// past the last character; Offset and Length are in bytes.
+type Span struct {
+	StartLine int `json:"start_line"`
+	StartCol  int `json:"start_col"`
+	EndLine   int `json:"end_line"`
+	EndCol    int `json:"end_col"`
+	Offset    int `json:"offset"`
+	Length    int `json:"length"`
+}
+
+// newSpan converts a parser span, returning nil for nodes the parser did not
+// position.
+func newSpan(s parser.Span) *Span {
+	if s.IsZero() {
+		return nil
+	}
+	return &Span{
+		StartLine: s.StartLine,
+		StartCol:  s.StartCol,
+		EndLine:   s.EndLine,
+		EndCol:    s.EndCol,
+		Offset:    s.Offset,
+		Length:    s.Length,
+	}
 }
 
 type ComplexityMetrics struct {
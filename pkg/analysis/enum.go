@@ -0,0 +1,224 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/VzoelFox/morphlang/pkg/parser"
+)
+
+// WarnUnknownVariant flags `Status.X` where the pilihan Status has no
+// variant X, which evaluates to kosong at runtime.
+const WarnUnknownVariant = "W004"
+
+// WarnNonExhaustive flags a jika/atau_jika chain that compares one value
+// against variants of a pilihan but neither handles them all nor has a
+// lainnya branch.
+const WarnNonExhaustive = "W005"
+
+type variantRef struct {
+	expr     *parser.IndexExpression
+	function string // Enclosing function, for the warning
+}
+
+type ifChain struct {
+	expr     *parser.IfExpression
+	function string
+}
+
+func (a *Analyzer) recordVariantRef(e *parser.IndexExpression) {
+	if _, _, ok := memberAccess(e); ok {
+		a.variantRefs = append(a.variantRefs, variantRef{expr: e, function: a.currFunc})
+	}
+}
+
+func (a *Analyzer) recordIfChain(e *parser.IfExpression) {
+	a.ifChains = append(a.ifChains, ifChain{expr: e, function: a.currFunc})
+}
+
+// memberAccess splits `Left.name` (or `Left["name"]`) when Left is a name.
+func memberAccess(e *parser.IndexExpression) (left, name string, ok bool) {
+	ident, ok := e.Left.(*parser.Identifier)
+	if !ok {
+		return "", "", false
+	}
+	key, ok := e.Index.(*parser.StringLiteral)
+	if !ok {
+		return "", "", false
+	}
+	return ident.Value, key.Value, true
+}
+
+// enumVariant resolves expr to a variant of a pilihan defined in this file.
+func (a *Analyzer) enumVariant(expr parser.Expression) (enum string, sym *Symbol, variant string, ok bool) {
+	index, ok := expr.(*parser.IndexExpression)
+	if !ok {
+		return "", nil, "", false
+	}
+	enum, variant, ok = memberAccess(index)
+	if !ok {
+		return "", nil, "", false
+	}
+	sym, ok = a.context.Symbols[enum]
+	if !ok || sym.Type != "enum" {
+		return "", nil, "", false
+	}
+	return enum, sym, variant, true
+}
+
+// checkVariants runs once every pilihan in the file is known.
+func (a *Analyzer) checkVariants() {
+	for _, ref := range a.variantRefs {
+		enum, sym, variant, ok := a.enumVariant(ref.expr)
+		if !ok || hasVariant(sym, variant) {
+			continue
+		}
+		msg := fmt.Sprintf("pilihan %s has no variant '%s'", enum, variant)
+		if guess := closestVariant(sym, variant); guess != "" {
+			msg += fmt.Sprintf(" (did you mean %s?)", guess)
+		}
+		key := ref.expr.Index
+		a.context.Warnings = append(a.context.Warnings, Warning{
+			Code:     WarnUnknownVariant,
+			Type:     "unknown_variant",
+			Line:     key.Span().StartLine,
+			Column:   key.Span().StartCol,
+			Message:  msg,
+			Severity: "error",
+			Function: ref.function,
+			Span:     newSpan(key.Span()),
+		})
+	}
+
+	// An atau_jika is an IfExpression alone in the lainnya block of the
+	// previous one; it is checked as part of the chain that starts above it
+	inner := map[*parser.IfExpression]bool{}
+	for _, chain := range a.ifChains {
+		if inner[chain.expr] {
+			continue
+		}
+		ifs, exhaustive := flattenIf(chain.expr)
+		for _, ie := range ifs[1:] {
+			inner[ie] = true
+		}
+		if !exhaustive && len(ifs) > 1 {
+			a.checkExhaustive(chain, ifs)
+		}
+	}
+}
+
+// flattenIf lists the branches of a jika/atau_jika chain and reports whether
+// it ends with a lainnya block.
+func flattenIf(ie *parser.IfExpression) ([]*parser.IfExpression, bool) {
+	ifs := []*parser.IfExpression{ie}
+	for {
+		alt := ie.Alternative
+		if alt == nil {
+			return ifs, false
+		}
+		if len(alt.Statements) != 1 {
+			return ifs, true
+		}
+		stmt, ok := alt.Statements[0].(*parser.ExpressionStatement)
+		if !ok {
+			return ifs, true
+		}
+		next, ok := stmt.Expression.(*parser.IfExpression)
+		if !ok {
+			return ifs, true
+		}
+		ie = next
+		ifs = append(ifs, ie)
+	}
+}
+
+// checkExhaustive warns when every condition of the chain is `x == P.V` (or
+// `P.V == x`) for the same x and pilihan P, and some variant of P is missing.
+func (a *Analyzer) checkExhaustive(chain ifChain, ifs []*parser.IfExpression) {
+	var enum, subject string
+	var sym *Symbol
+	handled := map[string]bool{}
+
+	for _, ie := range ifs {
+		cond, ok := ie.Condition.(*parser.InfixExpression)
+		if !ok || cond.Operator != "==" {
+			return
+		}
+		e, s, variant, ok := a.enumVariant(cond.Right)
+		other := cond.Left
+		if !ok {
+			if e, s, variant, ok = a.enumVariant(cond.Left); !ok {
+				return
+			}
+			other = cond.Right
+		}
+		if enum == "" {
+			enum, sym, subject = e, s, other.String()
+		} else if e != enum || other.String() != subject {
+			return
+		}
+		handled[variant] = true
+	}
+
+	missing := []string{}
+	for _, v := range sym.Variants {
+		if !handled[v.Name] {
+			missing = append(missing, v.Name)
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+	a.context.Warnings = append(a.context.Warnings, Warning{
+		Code:     WarnNonExhaustive,
+		Type:     "non_exhaustive",
+		Line:     chain.expr.Token.Line,
+		Column:   chain.expr.Token.Column,
+		Message:  fmt.Sprintf("jika over %s does not handle %s and has no lainnya branch", enum, strings.Join(missing, ", ")),
+		Severity: "warning",
+		Function: chain.function,
+		Variable: subject,
+		Span:     newSpan(chain.expr.Span()),
+	})
+}
+
+func hasVariant(sym *Symbol, name string) bool {
+	for _, v := range sym.Variants {
+		if v.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// closestVariant suggests a variant at most two edits away from name.
+func closestVariant(sym *Symbol, name string) string {
+	best, bestDist := "", 3
+	for _, v := range sym.Variants {
+		if d := editDistance(strings.ToLower(name), strings.ToLower(v.Name)); d < bestDist {
+			best, bestDist = v.Name, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr := make([]int, len(b)+1)
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev = curr
+	}
+	return prev[len(b)]
+}
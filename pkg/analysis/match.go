@@ -0,0 +1,122 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/VzoelFox/morphlang/pkg/parser"
+)
+
+// WarnNonExhaustiveMatch flags a `cocokkan` that can fall through every arm.
+const WarnNonExhaustiveMatch = "W001"
+
+// patternBindings returns the identifiers a pattern binds, in source order.
+func patternBindings(pattern parser.Pattern) []*parser.Identifier {
+	names := []*parser.Identifier{}
+	switch p := pattern.(type) {
+	case *parser.BindingPattern:
+		if !p.IsWildcard() {
+			names = append(names, p.Name)
+		}
+	case *parser.ArrayPattern:
+		for _, el := range p.Elements {
+			names = append(names, patternBindings(el)...)
+		}
+		if p.Rest != nil && p.Rest.Value != "_" {
+			names = append(names, p.Rest)
+		}
+	case *parser.HashPattern:
+		for _, v := range p.Values {
+			names = append(names, patternBindings(v)...)
+		}
+	case *parser.ErrorPattern:
+		if p.Message != nil {
+			names = append(names, patternBindings(p.Message)...)
+		}
+		if p.Code != nil {
+			names = append(names, patternBindings(p.Code)...)
+		}
+	}
+	return names
+}
+
+// checkMatchExhaustive warns when a match over struct schemas or a set of
+// literals has no arm for some values. Matches without a catch-all arm that
+// mix other pattern kinds are not checked.
+func (a *Analyzer) checkMatchExhaustive(m *parser.MatchExpression) {
+	if m.Alternative != nil {
+		return
+	}
+
+	schemas := []string{}
+	covered := map[string]bool{}
+	allLiterals := len(m.Arms) > 0
+	bools := map[string]bool{}
+
+	for _, arm := range m.Arms {
+		switch p := arm.Pattern.(type) {
+		case *parser.BindingPattern:
+			if arm.Guard == nil {
+				return // Catch-all arm
+			}
+			allLiterals = false
+		case *parser.HashPattern:
+			allLiterals = false
+			if p.Schema == nil {
+				continue
+			}
+			if _, seen := covered[p.Schema.Value]; !seen {
+				schemas = append(schemas, p.Schema.Value)
+				covered[p.Schema.Value] = false
+			}
+			if arm.Guard == nil && irrefutableFields(p) {
+				covered[p.Schema.Value] = true
+			}
+		case *parser.LiteralPattern:
+			if b, ok := p.Value.(*parser.BooleanLiteral); ok && arm.Guard == nil {
+				bools[b.String()] = true
+			}
+		default:
+			allLiterals = false
+		}
+	}
+
+	missing := []string{}
+	for _, name := range schemas {
+		if !covered[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		a.addMatchWarning(m, fmt.Sprintf("cocokkan is not exhaustive: some %s values match no arm; add kasus _ or lainnya", strings.Join(missing, ", ")))
+		return
+	}
+
+	if allLiterals && !(bools["benar"] && bools["salah"]) {
+		a.addMatchWarning(m, "cocokkan over literals is not exhaustive; add kasus _ or lainnya")
+	}
+}
+
+// irrefutableFields reports whether every field pattern of a struct pattern
+// always matches.
+func irrefutableFields(p *parser.HashPattern) bool {
+	for _, v := range p.Values {
+		if _, ok := v.(*parser.BindingPattern); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (a *Analyzer) addMatchWarning(m *parser.MatchExpression, msg string) {
+	a.context.Warnings = append(a.context.Warnings, Warning{
+		Code:     WarnNonExhaustiveMatch,
+		Type:     "non_exhaustive_match",
+		Line:     m.Token.Line,
+		Column:   m.Token.Column,
+		Message:  msg,
+		Severity: "warning",
+		Function: a.currFunc,
+		Span:     newSpan(m.Span()),
+	})
+}
@@ -0,0 +1,76 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/VzoelFox/morphlang/pkg/lexer"
+	"github.com/VzoelFox/morphlang/pkg/parser"
+)
+
+func analyzeSource(t *testing.T, input string) *Context {
+	t.Helper()
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parser has errors: %v", p.Errors())
+	}
+
+	ctx, err := GenerateContext(program, "test.fox", input, nil)
+	if err != nil {
+		t.Fatalf("GenerateContext failed: %v", err)
+	}
+	return ctx
+}
+
+func TestMatchExhaustiveness(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		warnings int
+	}{
+		{"literals without catch-all", `cocokkan x kasus 1 "a" kasus 2 "b" akhir`, 1},
+		{"literals with wildcard", `cocokkan x kasus 1 "a" kasus _ "b" akhir`, 0},
+		{"literals with lainnya", `cocokkan x kasus 1 "a" lainnya "b" akhir`, 0},
+		{"both booleans", `cocokkan x kasus benar 1 kasus salah 2 akhir`, 0},
+		{"one boolean", `cocokkan x kasus benar 1 akhir`, 1},
+		{"guarded binding", `cocokkan x kasus n jika n > 0 1 akhir`, 0},
+		{"struct with literal field", `cocokkan p kasus Titik{x: 0} 1 akhir`, 1},
+		{"struct covered", `cocokkan p kasus Titik{x: 0} 1 kasus Titik{x} x akhir`, 0},
+		{"struct guarded", `cocokkan p kasus Titik{x} jika x > 0 1 akhir`, 1},
+		{"arrays are not checked", `cocokkan p kasus [a] a akhir`, 0},
+	}
+
+	for _, tt := range tests {
+		ctx := analyzeSource(t, tt.input)
+		if len(ctx.Warnings) != tt.warnings {
+			t.Errorf("%s: expected %d warnings, got %d: %+v", tt.name, tt.warnings, len(ctx.Warnings), ctx.Warnings)
+			continue
+		}
+		for _, w := range ctx.Warnings {
+			if w.Code != WarnNonExhaustiveMatch || w.Line != 1 || w.Column != 1 {
+				t.Errorf("%s: unexpected warning %+v", tt.name, w)
+			}
+		}
+	}
+}
+
+func TestMatchBindingsAreLocals(t *testing.T) {
+	input := `
+fungsi kepala(xs)
+  cocokkan xs
+    kasus [h, ...t] h
+    kasus {nama} nama
+    kasus _ kosong
+  akhir
+akhir
+`
+	ctx := analyzeSource(t, input)
+
+	locals := ctx.LocalScopes["kepala"]
+	for _, name := range []string{"h", "t", "nama"} {
+		if _, ok := locals[name]; !ok {
+			t.Errorf("expected %q to be a local of kepala, got %v", name, ctx.Symbols["kepala"].LocalVars)
+		}
+	}
+}
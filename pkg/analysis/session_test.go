@@ -137,6 +137,37 @@ akhir
 	}
 }
 
+func TestImplicitErrorReturnCanError(t *testing.T) {
+	input := `
+fungsi gagal()
+  galat("selalu")
+akhir
+
+fungsi cek(x)
+  jika x < 0
+    galat("negatif")
+  lainnya
+    x
+  akhir
+akhir
+
+fungsi simpan(x)
+  galat("diabaikan")
+  x
+akhir
+`
+	ctx := analyzeSource(t, input)
+
+	for _, name := range []string{"gagal", "cek"} {
+		if !ctx.Symbols[name].CanError {
+			t.Errorf("expected %s to be CanError", name)
+		}
+	}
+	if ctx.Symbols["simpan"].CanError {
+		t.Errorf("expected simpan not to be CanError")
+	}
+}
+
 func TestAnalysisVariableTypes(t *testing.T) {
 	input := `
 # Global variables type test
@@ -178,3 +209,425 @@ z = benar
 		t.Errorf("Expected z to be boolean, got %s", v.Type)
 	}
 }
+
+func TestTryExpressionCanError(t *testing.T) {
+	input := `
+fungsi baca_angka(path)
+  isi = coba baca_file(path)
+  isi
+akhir
+
+fungsi aman(x)
+  x
+akhir
+`
+	ctx := analyzeSource(t, input)
+
+	sym := ctx.Symbols["baca_angka"]
+	if !sym.CanError {
+		t.Errorf("expected baca_angka to be CanError")
+	}
+	if len(sym.ErrorConditions) != 1 || sym.ErrorConditions[0].Line != 3 {
+		t.Errorf("unexpected error conditions: %+v", sym.ErrorConditions)
+	}
+	if ctx.Symbols["aman"].CanError {
+		t.Errorf("expected aman not to be CanError")
+	}
+}
+
+func TestOptionalParameters(t *testing.T) {
+	input := `
+fungsi sapa(nama, salam = "Halo", ...tambahan)
+  salam
+akhir
+`
+	ctx := analyzeSource(t, input)
+
+	params := ctx.Symbols["sapa"].Parameters
+	if len(params) != 3 {
+		t.Fatalf("expected 3 parameters, got %d", len(params))
+	}
+	if params[0].Default != "" || params[0].Variadic {
+		t.Errorf("nama should be required: %+v", params[0])
+	}
+	if params[1].Default != "Halo" || params[1].InferredType != "string" {
+		t.Errorf("salam default wrong: %+v", params[1])
+	}
+	if !params[2].Variadic || params[2].InferredType != "array" {
+		t.Errorf("tambahan should be variadic: %+v", params[2])
+	}
+}
+
+func TestNamedArgumentWarnings(t *testing.T) {
+	input := `
+struktur Titik
+  x
+  y
+akhir
+
+fungsi geser(titik, dx = 0, ...lain)
+  titik
+akhir
+
+a = Titik(x: 1, y: 2)
+b = Titik(1, x: 2)
+c = Titik(x: 1, z: 2)
+d = geser(a, dx: 1)
+e = geser(a, lain: 1)
+`
+	ctx := analyzeSource(t, input)
+
+	if sym := ctx.Symbols["Titik"]; sym == nil || sym.Type != "struct" || len(sym.Fields) != 2 {
+		t.Fatalf("expected struct symbol Titik with 2 fields, got %+v", sym)
+	}
+
+	lines := []int{}
+	for _, w := range ctx.Warnings {
+		if w.Code == WarnBadNamedArgument {
+			lines = append(lines, w.Line)
+		}
+	}
+	if len(lines) != 3 || lines[0] != 12 || lines[1] != 13 || lines[2] != 15 {
+		t.Errorf("expected named argument warnings on lines 12, 13 and 15, got %v (%+v)", lines, ctx.Warnings)
+	}
+}
+
+func TestStructMethodSymbols(t *testing.T) {
+	input := `
+struktur Akun
+  saldo
+
+  # Menambah saldo
+  fungsi setor(jumlah)
+    baru = ini.saldo + jumlah
+    kembalikan Akun(baru)
+  akhir
+akhir
+`
+	ctx := analyzeSource(t, input)
+
+	sym := ctx.Symbols["Akun"]
+	if sym == nil || sym.Methods["setor"] == nil {
+		t.Fatalf("expected method setor under struct Akun, got %+v", sym)
+	}
+	setor := sym.Methods["setor"]
+	if setor.Type != "method" || setor.Doc != "Menambah saldo" {
+		t.Errorf("wrong method symbol: %+v", setor)
+	}
+	if len(setor.Parameters) != 1 || setor.Parameters[0].Name != "jumlah" {
+		t.Errorf("the receiver should not be listed as a parameter: %+v", setor.Parameters)
+	}
+	if _, ok := ctx.LocalScopes["Akun.setor"]["baru"]; !ok {
+		t.Errorf("expected local baru in scope Akun.setor, got %v", ctx.LocalScopes)
+	}
+	if _, ok := ctx.Symbols["setor"]; ok {
+		t.Errorf("methods should not be top-level symbols")
+	}
+}
+
+func TestDestructuringBindings(t *testing.T) {
+	input := `
+[kepala, ...sisa] = [1, 2, 3]
+fungsi pisah(o)
+  {nama, umur: u} = o
+  kembalikan nama
+akhir
+`
+	ctx := analyzeSource(t, input)
+
+	for _, name := range []string{"kepala", "sisa"} {
+		if _, ok := ctx.GlobalVars[name]; !ok {
+			t.Errorf("expected global %s, got %v", name, ctx.GlobalVars)
+		}
+	}
+	for _, name := range []string{"nama", "u"} {
+		if _, ok := ctx.LocalScopes["pisah"][name]; !ok {
+			t.Errorf("expected local %s in scope pisah, got %v", name, ctx.LocalScopes["pisah"])
+		}
+	}
+}
+
+func TestConstantMutationWarnings(t *testing.T) {
+	input := `tetap BATAS = 10
+BATAS = 11
+fungsi f(BATAS)
+  BATAS = 1
+akhir
+fungsi g()
+  tetap N = 1
+  untuk N dalam [1, 2]
+    BATAS += N
+  akhir
+akhir
+`
+	ctx := analyzeSource(t, input)
+
+	if v := ctx.GlobalVars["BATAS"]; v == nil || !v.Constant || v.Type != "integer" {
+		t.Errorf("expected constant BATAS, got %+v", v)
+	}
+	if v := ctx.LocalScopes["g"]["N"]; v == nil || !v.Constant {
+		t.Errorf("expected local constant N in g, got %+v", v)
+	}
+
+	want := []struct {
+		line     int
+		variable string
+	}{{2, "BATAS"}, {8, "N"}, {9, "BATAS"}}
+	got := []Warning{}
+	for _, w := range ctx.Warnings {
+		if w.Code == WarnConstantMutation {
+			got = append(got, w)
+		}
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d mutation warnings, got %+v", len(want), got)
+	}
+	for i, w := range want {
+		if got[i].Line != w.line || got[i].Variable != w.variable {
+			t.Errorf("warning %d: want %s on line %d, got %+v", i, w.variable, w.line, got[i])
+		}
+	}
+}
+
+func TestTypeMismatchWarnings(t *testing.T) {
+	input := `struktur Titik
+  x: integer
+akhir
+fungsi bagi(a: integer, b: integer) -> integer | galat
+  jika b == 0
+    kembalikan galat("nol")
+  akhir
+  kembalikan a / b
+akhir
+fungsi sapa(nama: string) -> string
+  pesan: string = "halo " + nama
+  pesan = 1
+  kembalikan 0
+akhir
+jumlah: integer = 1
+jumlah = "dua"
+bagi("x", b: 2.5)
+bagi(jumlah, 2)
+t = Titik(x: "a")
+`
+	ctx := analyzeSource(t, input)
+
+	bagi := ctx.Symbols["bagi"]
+	if bagi.Parameters[0].InferredType != "integer" || len(bagi.Parameters[0].Types) != 1 {
+		t.Errorf("expected declared integer parameter, got %+v", bagi.Parameters[0])
+	}
+	if r := bagi.Returns; r == nil || r.Type != "union" || len(r.Types) != 2 || r.Types[1] != "error" {
+		t.Errorf("expected declared return type integer | error, got %+v", r)
+	}
+	if ft := ctx.Symbols["Titik"].FieldTypes["x"]; len(ft) != 1 || ft[0] != "integer" {
+		t.Errorf("expected field type integer, got %v", ft)
+	}
+	if v := ctx.GlobalVars["jumlah"]; v == nil || v.Type != "integer" || len(v.Declared) != 1 {
+		t.Errorf("expected declared global jumlah, got %+v", v)
+	}
+
+	want := []string{
+		"pesan must be string, got integer",
+		"return value of sapa must be string, got integer",
+		"jumlah must be integer, got string",
+		"argument a of bagi must be integer, got string",
+		"argument b of bagi must be integer, got float",
+		"field x of Titik must be integer, got string",
+	}
+	got := []string{}
+	for _, w := range ctx.Warnings {
+		if w.Code == WarnTypeMismatch {
+			got = append(got, w.Message)
+		}
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d type warnings, got %q", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("warning %d: want %q, got %q", i, want[i], got[i])
+		}
+	}
+}
+
+func TestDecimalTypeInference(t *testing.T) {
+	input := `harga: decimal = desimal("1.50") * 2
+total: float = desimal("3")
+`
+	ctx := analyzeSource(t, input)
+
+	got := []string{}
+	for _, w := range ctx.Warnings {
+		if w.Code == WarnTypeMismatch {
+			got = append(got, w.Message)
+		}
+	}
+	if len(got) != 1 || got[0] != "total must be float, got decimal" {
+		t.Errorf("expected one decimal type warning, got %q", got)
+	}
+}
+
+func TestUnknownTypeWarnings(t *testing.T) {
+	input := `dari "bentuk.fox" ambil Lingkaran
+struktur Titik
+  x: integer
+  y: Titk
+akhir
+pilihan Warna
+  Merah
+akhir
+fungsi f(a: desimal, b: Warna | galat, c: Lingkaran) -> strng
+  d: fungsi = f
+  kembalikan ""
+akhir
+`
+	ctx := analyzeSource(t, input)
+
+	want := []string{"unknown type Titk", "unknown type strng"}
+	got := []string{}
+	for _, w := range ctx.Warnings {
+		if w.Code == WarnUnknownType {
+			got = append(got, w.Message)
+		}
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d unknown type warnings, got %q", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("warning %d: want %q, got %q", i, want[i], got[i])
+		}
+	}
+	if p := ctx.Symbols["f"].Parameters[0]; p.InferredType != "decimal" {
+		t.Errorf("expected desimal to read as decimal, got %+v", p)
+	}
+}
+
+func TestCoalesceTypeInference(t *testing.T) {
+	input := `port: integer = kosong ?? 80
+nama: integer = galat("x") ?! "tamu"
+`
+	ctx := analyzeSource(t, input)
+
+	got := []string{}
+	for _, w := range ctx.Warnings {
+		if w.Code == WarnTypeMismatch {
+			got = append(got, w.Message)
+		}
+	}
+	if len(got) != 1 || got[0] != "nama must be integer, got string" {
+		t.Errorf("expected one fallback type warning, got %q", got)
+	}
+}
+
+func TestEnumWarnings(t *testing.T) {
+	input := `pilihan Status
+  Menunggu
+  Berjalan(pid)
+  Selesai
+akhir
+fungsi label(s)
+  jika s == Status.Menunggu
+    kembalikan "menunggu"
+  atau_jika Status.Berjalan == s
+    kembalikan "berjalan"
+  akhir
+akhir
+fungsi lengkap(s)
+  jika s == Status.Menunggu
+    kembalikan 1
+  atau_jika s == Status.Berjalan
+    kembalikan 2
+  lainnya
+    kembalikan 3
+  akhir
+akhir
+x = Status.Selsai
+y = Status.Dibatalkan
+`
+	ctx := analyzeSource(t, input)
+
+	sym := ctx.Symbols["Status"]
+	if sym == nil || sym.Type != "enum" || len(sym.Variants) != 3 {
+		t.Fatalf("expected enum Status with 3 variants, got %+v", sym)
+	}
+	if sym.Variants[0].Fields != nil || len(sym.Variants[1].Fields) != 1 {
+		t.Errorf("wrong variant fields: %+v", sym.Variants)
+	}
+
+	want := []struct {
+		code    string
+		line    int
+		message string
+	}{
+		{WarnUnknownVariant, 22, "pilihan Status has no variant 'Selsai' (did you mean Selesai?)"},
+		{WarnUnknownVariant, 23, "pilihan Status has no variant 'Dibatalkan'"},
+		{WarnNonExhaustive, 7, "jika over Status does not handle Selesai and has no lainnya branch"},
+	}
+	got := []Warning{}
+	for _, w := range ctx.Warnings {
+		if w.Code == WarnUnknownVariant || w.Code == WarnNonExhaustive {
+			got = append(got, w)
+		}
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d warnings, got %+v", len(want), got)
+	}
+	for i, w := range want {
+		if got[i].Code != w.code || got[i].Line != w.line || got[i].Message != w.message {
+			t.Errorf("warning %d: want %s on line %d %q, got %+v", i, w.code, w.line, w.message, got[i])
+		}
+	}
+	if got[2].Function != "label" || got[2].Variable != "s" {
+		t.Errorf("expected the chain in label over s, got %+v", got[2])
+	}
+}
+
+func TestContextParserErrors(t *testing.T) {
+	input := `x = (1 + 2
+y = ]
+`
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+
+	ctx, err := GenerateContext(program, "test.fox", input, p.Errors())
+	if err != nil {
+		t.Fatalf("GenerateContext failed: %v", err)
+	}
+
+	if len(ctx.Errors) != 2 {
+		t.Fatalf("expected 2 errors, got %+v", ctx.Errors)
+	}
+	if ctx.Errors[0].Expected != ")" || ctx.Errors[0].Found != "IDENT" {
+		t.Errorf("unexpected first error: %+v", ctx.Errors[0])
+	}
+	if ctx.Errors[1].Line != 2 || ctx.Errors[1].Found != "]" {
+		t.Errorf("unexpected second error: %+v", ctx.Errors[1])
+	}
+	want := Span{StartLine: 2, StartCol: 5, EndLine: 2, EndCol: 6, Offset: 15, Length: 1}
+	if ctx.Errors[1].Span == nil || *ctx.Errors[1].Span != want {
+		t.Errorf("second error span wrong. want=%+v, got=%+v", want, ctx.Errors[1].Span)
+	}
+}
+
+func TestContextSpans(t *testing.T) {
+	input := `fungsi f(x)
+  cocokkan x kasus 1 2 akhir
+akhir`
+	ctx := analyzeSource(t, input)
+
+	fn := ctx.Symbols["f"]
+	want := Span{StartLine: 1, StartCol: 1, EndLine: 3, EndCol: 6, Offset: 0, Length: len(input)}
+	if fn.Span == nil || *fn.Span != want {
+		t.Errorf("symbol span wrong. want=%+v, got=%+v", want, fn.Span)
+	}
+
+	if len(ctx.Warnings) != 1 {
+		t.Fatalf("expected 1 warning, got %+v", ctx.Warnings)
+	}
+	want = Span{StartLine: 2, StartCol: 3, EndLine: 2, EndCol: 29, Offset: 14, Length: 26}
+	if ctx.Warnings[0].Span == nil || *ctx.Warnings[0].Span != want {
+		t.Errorf("warning span wrong. want=%+v, got=%+v", want, ctx.Warnings[0].Span)
+	}
+}
@@ -0,0 +1,216 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/VzoelFox/morphlang/pkg/parser"
+)
+
+// WarnTypeMismatch flags a value whose inferred type is not one of the types
+// declared for its parameter, field, variable or return value.
+const WarnTypeMismatch = "E003"
+
+// WarnUnknownType flags a type annotation naming neither a built-in type nor
+// a struktur or pilihan the file defines or imports. Strict mode would reject
+// every value passed to it.
+const WarnUnknownType = "W006"
+
+// builtinTypes are the names typeNameOf in the VM can report, plus "any".
+var builtinTypes = map[string]bool{
+	"integer": true, "float": true, "decimal": true, "string": true,
+	"boolean": true, "kosong": true, "array": true, "hash": true,
+	"range": true, "error": true, "function": true, "any": true,
+}
+
+type typeRef struct {
+	ann      *parser.TypeAnnotation
+	function string // Enclosing function, for the warning
+}
+
+type typedCall struct {
+	call     *parser.CallExpression
+	function string   // Enclosing function, for the warning
+	args     []string // Inferred argument types, in call order
+}
+
+// declaredType is the Type recorded for a value with the given declared types.
+func declaredType(types []string) string {
+	if len(types) == 1 {
+		return types[0]
+	}
+	return "union"
+}
+
+// typeAllowed reports whether a value inferred as t fits the declared types.
+// Values the analyzer cannot type are given the benefit of the doubt.
+func typeAllowed(t string, declared []string) bool {
+	switch t {
+	case "", "any", "unknown", "union", "inferred":
+		return true
+	}
+	for _, d := range declared {
+		if d == t || d == "any" {
+			return true
+		}
+	}
+	return false
+}
+
+// checkType warns when value cannot have one of the declared types. what
+// names the slot in the message, as in "return value of bagi".
+func (a *Analyzer) checkType(value parser.Expression, declared []string, what string) {
+	a.checkInferred(value, a.inferType(value), declared, what, a.currFunc)
+}
+
+func (a *Analyzer) checkInferred(node parser.Node, t string, declared []string, what, function string) {
+	if typeAllowed(t, declared) {
+		return
+	}
+	a.context.Warnings = append(a.context.Warnings, Warning{
+		Code:     WarnTypeMismatch,
+		Type:     "type_mismatch",
+		Line:     node.Span().StartLine,
+		Column:   node.Span().StartCol,
+		Message:  fmt.Sprintf("%s must be %s, got %s", what, strings.Join(declared, " | "), t),
+		Severity: "warning",
+		Function: function,
+		Span:     newSpan(node.Span()),
+	})
+}
+
+// checkAssignmentType checks a plain assignment against the type it declares
+// or, failing that, the type its variable was declared with. It returns the
+// declared types of a typed assignment.
+func (a *Analyzer) checkAssignmentType(s *parser.AssignmentStatement) []string {
+	ident, ok := s.Name.(*parser.Identifier)
+	if !ok || s.Operator() != "" {
+		return nil
+	}
+	if s.Type != nil {
+		a.recordTypeRef(s.Type, a.currFunc)
+		declared := s.Type.Types()
+		a.checkType(s.Value, declared, ident.Value)
+		return declared
+	}
+	if declared := a.declaredTypes(ident.Value); declared != nil {
+		a.checkType(s.Value, declared, ident.Value)
+	}
+	return nil
+}
+
+// declaredTypes finds the types a visible variable was declared with.
+func (a *Analyzer) declaredTypes(name string) []string {
+	if a.currFunc != "" {
+		if v, ok := a.context.LocalScopes[a.currFunc][name]; ok {
+			return v.Declared
+		}
+		for _, p := range a.currParams {
+			if p.Name == name {
+				return p.Types
+			}
+		}
+	}
+	if v, ok := a.context.GlobalVars[name]; ok {
+		return v.Declared
+	}
+	return nil
+}
+
+// recordCall remembers the argument types of a call so they can be checked
+// once every function and struct in the file is known.
+func (a *Analyzer) recordCall(call *parser.CallExpression) {
+	if _, ok := call.Function.(*parser.Identifier); !ok {
+		return
+	}
+	tc := typedCall{call: call, function: a.currFunc}
+	for _, arg := range call.Arguments {
+		if named, ok := arg.(*parser.NamedArgument); ok {
+			arg = named.Value
+		}
+		tc.args = append(tc.args, a.inferType(arg))
+	}
+	a.typedCalls = append(a.typedCalls, tc)
+}
+
+// checkCallTypes validates arguments passed to typed parameters and fields.
+func (a *Analyzer) checkCallTypes() {
+	for _, tc := range a.typedCalls {
+		name := tc.call.Function.(*parser.Identifier).Value
+		sym, ok := a.context.Symbols[name]
+		if !ok {
+			continue
+		}
+
+		var slots []string
+		types := map[string][]string{}
+		switch sym.Type {
+		case "function":
+			for _, p := range sym.Parameters {
+				if p.Variadic {
+					break
+				}
+				slots = append(slots, p.Name)
+				types[p.Name] = p.Types
+			}
+		case "struct":
+			slots = sym.Fields
+			types = sym.FieldTypes
+		default:
+			continue
+		}
+
+		kind := "argument"
+		if sym.Type == "struct" {
+			kind = "field"
+		}
+		for i, arg := range tc.call.Arguments {
+			slot := ""
+			if named, ok := arg.(*parser.NamedArgument); ok {
+				slot = named.Name.Value
+			} else if i < len(slots) {
+				slot = slots[i]
+			}
+			if declared := types[slot]; declared != nil {
+				what := fmt.Sprintf("%s %s of %s", kind, slot, name)
+				a.checkInferred(arg, tc.args[i], declared, what, tc.function)
+			}
+		}
+	}
+}
+
+// recordTypeRef remembers an annotation so its names can be checked once
+// every struktur and pilihan in the file is known.
+func (a *Analyzer) recordTypeRef(ann *parser.TypeAnnotation, function string) {
+	if ann != nil {
+		a.typeRefs = append(a.typeRefs, typeRef{ann: ann, function: function})
+	}
+}
+
+// checkTypeNames warns on annotation names the file cannot resolve. A plain
+// `ambil` may bring in any name, so nothing is flagged after one.
+func (a *Analyzer) checkTypeNames() {
+	if a.importsAll {
+		return
+	}
+	for _, ref := range a.typeRefs {
+		for i, name := range ref.ann.Types() {
+			if builtinTypes[name] || a.imports[name] {
+				continue
+			}
+			if sym, ok := a.context.Symbols[name]; ok && (sym.Type == "struct" || sym.Type == "enum") {
+				continue
+			}
+			a.context.Warnings = append(a.context.Warnings, Warning{
+				Code:     WarnUnknownType,
+				Type:     "unknown_type",
+				Line:     ref.ann.Span().StartLine,
+				Column:   ref.ann.Span().StartCol,
+				Message:  fmt.Sprintf("unknown type %s", ref.ann.Names[i]),
+				Severity: "warning",
+				Function: ref.function,
+				Span:     newSpan(ref.ann.Span()),
+			})
+		}
+	}
+}
@@ -35,6 +35,9 @@ type CompilerState struct {
 	Constants    []object.Object
 	ModuleCache  map[string]int
 	LoadingStack map[string]bool
+
+	// ModuleConstants holds the names each module declares with `tetap`
+	ModuleConstants map[string]map[string]bool
 }
 
 type Compiler struct {
@@ -50,18 +53,60 @@ type Compiler struct {
 	Filename string
 	analyzed bool
 
+	// Strict makes type mismatches found by the analyzer compile errors and
+	// checks the arguments of typed parameters when functions are called.
+	Strict bool
+
 	loadingStack map[string]bool
+
+	// Jumps of `?.` links to the end of the chain being compiled
+	chain *[]int
 }
 
 func New() *Compiler {
-	return NewWithState(&CompilerState{
+	return NewWithState(NewState())
+}
+
+// NewState returns an empty constant pool and module cache.
+func NewState() *CompilerState {
+	return &CompilerState{
 		Constants:    []object.Object{},
 		ModuleCache:  make(map[string]int),
 		LoadingStack: make(map[string]bool),
-	})
+
+		ModuleConstants: make(map[string]map[string]bool),
+	}
+}
+
+// Snapshot returns a function that drops every constant and module added to
+// the state since, as after a compilation that failed halfway.
+func (s *CompilerState) Snapshot() func() {
+	numConstants := len(s.Constants)
+	moduleCache := make(map[string]int, len(s.ModuleCache))
+	for path, idx := range s.ModuleCache {
+		moduleCache[path] = idx
+	}
+	moduleConstants := make(map[string]map[string]bool, len(s.ModuleConstants))
+	for path, names := range s.ModuleConstants {
+		moduleConstants[path] = names
+	}
+
+	return func() {
+		s.Constants = s.Constants[:numConstants]
+		s.ModuleCache = moduleCache
+		s.ModuleConstants = moduleConstants
+		s.LoadingStack = make(map[string]bool)
+	}
 }
 
 func NewWithState(state *CompilerState) *Compiler {
+	return NewWithSymbols(state, NewSymbolTable())
+}
+
+// NewWithSymbols returns a compiler that keeps adding to state and defines
+// globals in symbols, so that successive programs (REPL inputs) see each
+// other's definitions.
+func NewWithSymbols(state *CompilerState, symbols *SymbolTable) *Compiler {
 	mainScope := CompilationScope{
 		instructions:        Instructions{},
 		lastInstruction:     EmittedInstruction{},
@@ -71,7 +116,7 @@ func NewWithState(state *CompilerState) *Compiler {
 
 	return &Compiler{
 		state:        state,
-		symbolTable:  NewSymbolTable(),
+		symbolTable:  symbols,
 		scopes:       []CompilationScope{mainScope},
 		scopeIndex:   0,
 	}
@@ -83,13 +128,20 @@ func (c *Compiler) SetSource(filename, input string) {
 }
 
 func (c *Compiler) Compile(node parser.Node) error {
-	if c.Input != "" && !c.analyzed {
+	if (c.Input != "" || c.Strict) && !c.analyzed {
 		c.analyzed = true
 		if prog, ok := node.(*parser.Program); ok {
 			ctx, _ := analysis.GenerateContext(prog, c.Filename, c.Input, []parser.ParserError{})
 			if len(ctx.Errors) > 0 {
 				return fmt.Errorf("safety check failed: %s", ctx.Errors[0].Message)
 			}
+			if c.Strict {
+				for _, w := range ctx.Warnings {
+					if w.Code == analysis.WarnTypeMismatch {
+						return fmt.Errorf("type check failed at line %d: %s", w.Line, w.Message)
+					}
+				}
+			}
 		}
 	}
 
@@ -108,9 +160,12 @@ func (c *Compiler) Compile(node parser.Node) error {
 		}
 
 		if fn, ok := node.Expression.(*parser.FunctionLiteral); ok && fn.Name != "" {
-			symbol := c.symbolTable.Define(fn.Name)
+			symbol, err := c.symbolTable.Bind(fn.Name)
+			if err != nil {
+				return err
+			}
 
-			err := c.Compile(fn)
+			err = c.Compile(fn)
 			if err != nil {
 				return err
 			}
@@ -140,16 +195,28 @@ func (c *Compiler) Compile(node parser.Node) error {
 		}
 
 	case *parser.AssignmentStatement:
+		// A compound assignment (x += v) combines the current value with v
+		op := node.Operator()
 		switch name := node.Name.(type) {
 		case *parser.Identifier:
+			if op != "" {
+				if err := c.Compile(name); err != nil {
+					return err
+				}
+			}
+
 			err := c.Compile(node.Value)
 			if err != nil {
 				return err
 			}
 
-			symbol, ok := c.symbolTable.Resolve(name.Value)
-			if !ok {
-				symbol = c.symbolTable.Define(name.Value)
+			if op != "" {
+				c.emit(compoundOps[op])
+			}
+
+			symbol, err := c.symbolTable.Assign(name.Value)
+			if err != nil {
+				return err
 			}
 
 			if symbol.Scope == GlobalScope {
@@ -161,6 +228,13 @@ func (c *Compiler) Compile(node parser.Node) error {
 			}
 
 		case *parser.IndexExpression:
+			if name.Optional() {
+				return fmt.Errorf("cannot assign to %s: ?. is read-only", name.String())
+			}
+			if err := c.checkReadOnly(name); err != nil {
+				return err
+			}
+
 			err := c.Compile(name.Left)
 			if err != nil {
 				return err
@@ -171,17 +245,37 @@ func (c *Compiler) Compile(node parser.Node) error {
 				return err
 			}
 
+			// Container and key are evaluated once, then read and written
+			if op != "" {
+				c.emit(OpDup2)
+				c.emit(OpIndex)
+			}
+
 			err = c.Compile(node.Value)
 			if err != nil {
 				return err
 			}
 
+			if op != "" {
+				c.emit(compoundOps[op])
+			}
+
+			// A failed write (such as an unknown struct field) returns its Error,
+			// or fails the program at top level
 			c.emit(OpSetIndex)
+			c.emit(OpPropagate)
+			c.emit(OpPop)
 
 		default:
 			return fmt.Errorf("assignment to %T not supported", node.Name)
 		}
 
+	case *parser.DestructuringStatement:
+		return c.compileDestructuring(node)
+
+	case *parser.ConstStatement:
+		return c.compileConstant(node)
+
 	case *parser.Identifier:
 		symbol, ok := c.symbolTable.Resolve(node.Value)
 		if !ok {
@@ -193,13 +287,7 @@ func (c *Compiler) Compile(node parser.Node) error {
 			return fmt.Errorf("undefined variable %s", node.Value)
 		}
 
-		if symbol.Scope == GlobalScope {
-			c.emit(OpLoadGlobal, symbol.Index)
-		} else if symbol.Scope == LocalScope {
-			c.emit(OpLoadLocal, symbol.Index)
-		} else if symbol.Scope == FreeScope {
-			c.emit(OpGetFree, symbol.Index)
-		}
+		c.loadSymbol(symbol)
 
 	case *parser.IfExpression:
 		err := c.Compile(node.Condition)
@@ -285,6 +373,80 @@ func (c *Compiler) Compile(node parser.Node) error {
 			c.changeOperand(pos, loopStartPos)
 		}
 
+	case *parser.ForInExpression:
+		err := c.Compile(node.Iterable)
+		if err != nil {
+			return err
+		}
+
+		// Leaves the initial loop value (null, or an Error for non-iterables)
+		// below the iterator, which lives in a hidden variable. The Error is
+		// returned from the current function, or fails the program at top level.
+		c.emit(OpIter)
+		iterSymbol := c.symbolTable.Define(fmt.Sprintf("$iter%d", len(c.currentInstructions())))
+		c.storeSymbol(iterSymbol)
+		c.emit(OpPropagate)
+
+		loopStartPos := len(c.currentInstructions())
+
+		c.loadSymbol(iterSymbol)
+		numVars := 1
+		if node.Key != nil {
+			numVars = 2
+		}
+		c.emit(OpIterNext, numVars)
+
+		jumpNotTruthyPos := c.emit(OpJumpNotTruthy, 9999)
+
+		if err := c.storeName(node.Value.Value); err != nil {
+			return err
+		}
+		if node.Key != nil {
+			if err := c.storeName(node.Key.Value); err != nil {
+				return err
+			}
+		}
+
+		c.enterLoop()
+
+		c.emit(OpPop)
+
+		err = c.Compile(node.Body)
+		if err != nil {
+			return err
+		}
+
+		if c.scopes[c.scopeIndex].lastInstruction.Opcode == OpPop {
+			c.removeLastPop()
+		} else {
+			c.emit(OpLoadConst, c.addConstant(object.NewNull()))
+		}
+
+		c.emit(OpJump, loopStartPos)
+
+		loopScope := c.leaveLoop()
+		afterLoopPos := len(c.currentInstructions())
+
+		c.changeOperand(jumpNotTruthyPos, afterLoopPos)
+
+		for _, pos := range loopScope.BreakPos {
+			c.changeOperand(pos, afterLoopPos)
+		}
+
+		for _, pos := range loopScope.ContinuePos {
+			c.changeOperand(pos, loopStartPos)
+		}
+
+	case *parser.MatchExpression:
+		return c.compileMatch(node)
+
+	case *parser.TryExpression:
+		err := c.Compile(node.Value)
+		if err != nil {
+			return err
+		}
+		c.emit(OpPropagate)
+
 	case *parser.InfixExpression:
 		if node.Operator == "<" {
 			err := c.Compile(node.Right)
@@ -333,6 +495,33 @@ func (c *Compiler) Compile(node parser.Node) error {
 			return nil
 		}
 
+		// x ?? d and x ?! d keep x unless it is kosong (or a galat)
+		if node.Token.Type == lexer.NULL_COALESCE || node.Token.Type == lexer.ERROR_COALESCE {
+			err := c.Compile(node.Left)
+			if err != nil {
+				return err
+			}
+
+			tag := memory.TagNull
+			if node.Token.Type == lexer.ERROR_COALESCE {
+				tag = memory.TagError
+			}
+			c.emit(OpDup)
+			c.emit(OpMatchType, int(tag))
+			keepLeftPos := c.emit(OpJumpNotTruthy, 9999)
+
+			c.emit(OpPop)
+
+			err = c.Compile(node.Right)
+			if err != nil {
+				return err
+			}
+
+			afterRightPos := len(c.currentInstructions())
+			c.changeOperand(keepLeftPos, afterRightPos)
+			return nil
+		}
+
 		if node.Token.Type == lexer.ATAU {
 			err := c.Compile(node.Left)
 			if err != nil {
@@ -377,6 +566,12 @@ func (c *Compiler) Compile(node parser.Node) error {
 			c.emit(OpMul)
 		case "/":
 			c.emit(OpDiv)
+		case "%":
+			c.emit(OpMod)
+		case "//":
+			c.emit(OpFloorDiv)
+		case "**":
+			c.emit(OpPow)
 		case ">":
 			c.emit(OpGreaterThan)
 		case ">=":
@@ -489,15 +684,46 @@ func (c *Compiler) Compile(node parser.Node) error {
 		c.emit(OpHash, len(node.Pairs)*2)
 
 	case *parser.IndexExpression:
-		err := c.Compile(node.Left)
-		if err != nil {
+		return c.compileChain(func() error {
+			if err := c.compileLinkLeft(node.Left, node.Optional()); err != nil {
+				return err
+			}
+			if err := c.compileOperand(node.Index); err != nil {
+				return err
+			}
+			c.emit(OpIndex)
+			return nil
+		})
+
+	case *parser.SliceExpression:
+		return c.compileChain(func() error {
+			if err := c.compileLinkLeft(node.Left, false); err != nil {
+				return err
+			}
+			// A missing bound is passed as kosong
+			for _, bound := range []parser.Expression{node.Start, node.End} {
+				if bound == nil {
+					c.emit(OpLoadConst, c.addConstant(object.NewNull()))
+				} else if err := c.compileOperand(bound); err != nil {
+					return err
+				}
+			}
+			c.emit(OpSlice)
+			return nil
+		})
+
+	case *parser.RangeExpression:
+		if err := c.Compile(node.Start); err != nil {
 			return err
 		}
-		err = c.Compile(node.Index)
-		if err != nil {
+		if err := c.Compile(node.End); err != nil {
 			return err
 		}
-		c.emit(OpIndex)
+		exclusive := 0
+		if node.Exclusive {
+			exclusive = 1
+		}
+		c.emit(OpRange, exclusive)
 
 	case *parser.BreakStatement:
 		scope := c.currentLoopScope()
@@ -530,12 +756,27 @@ func (c *Compiler) Compile(node parser.Node) error {
 		if err != nil {
 			return err
 		}
+		readOnly := c.state.ModuleConstants[modulePath(path)]
 
 		c.emit(OpLoadConst, modIdx)
 		c.emit(OpCall, 0)
 
+		// The exports hash can be reached through other names at runtime, so
+		// its constants are frozen there too
+		constants := make([]string, 0, len(readOnly))
+		for name := range readOnly {
+			constants = append(constants, name)
+		}
+		sort.Strings(constants)
+		for _, name := range constants {
+			c.emit(OpFreezeKey, c.addConstant(object.NewString(name)))
+		}
+
 		if len(node.Identifiers) == 0 {
-			symbol := c.symbolTable.Define(moduleName)
+			symbol, err := c.symbolTable.BindModule(moduleName, readOnly)
+			if err != nil {
+				return err
+			}
 			if symbol.Scope == GlobalScope {
 				c.emit(OpStoreGlobal, symbol.Index)
 			} else {
@@ -547,7 +788,15 @@ func (c *Compiler) Compile(node parser.Node) error {
 				c.emit(OpLoadConst, c.addConstant(object.NewString(ident)))
 				c.emit(OpIndex)
 
-				symbol := c.symbolTable.Define(ident)
+				var symbol Symbol
+				if readOnly[ident] {
+					symbol, err = c.symbolTable.DefineConstant(ident, -1)
+				} else {
+					symbol, err = c.symbolTable.Bind(ident)
+				}
+				if err != nil {
+					return err
+				}
 				if symbol.Scope == GlobalScope {
 					c.emit(OpStoreGlobal, symbol.Index)
 				} else {
@@ -557,6 +806,9 @@ func (c *Compiler) Compile(node parser.Node) error {
 			c.emit(OpPop)
 		}
 
+	case *parser.EnumStatement:
+		return c.compileEnum(node)
+
 	case *parser.StructStatement:
 		for _, field := range node.Fields {
 			str := object.NewString(field.Value)
@@ -564,12 +816,25 @@ func (c *Compiler) Compile(node parser.Node) error {
 			c.emit(OpLoadConst, idx)
 		}
 
+		// Defined first so that methods can refer to their own struktur
+		symbol, err := c.symbolTable.Bind(node.Name.Value)
+		if err != nil {
+			return err
+		}
+
+		// Each method is a (name, closure) pair taking the receiver first
+		for _, m := range node.Methods {
+			c.emit(OpLoadConst, c.addConstant(object.NewString(m.Name)))
+			if err := c.Compile(m.WithReceiver()); err != nil {
+				return err
+			}
+		}
+
 		nameStr := object.NewString(node.Name.Value)
 		nameIdx := c.addConstant(nameStr)
 
-		c.emit(OpStruct, nameIdx, len(node.Fields))
+		c.emit(OpStruct, nameIdx, len(node.Fields), len(node.Methods))
 
-		symbol := c.symbolTable.Define(node.Name.Value)
 		if symbol.Scope == GlobalScope {
 			c.emit(OpStoreGlobal, symbol.Index)
 		} else {
@@ -583,6 +848,31 @@ func (c *Compiler) Compile(node parser.Node) error {
 			c.symbolTable.Define(p.Value)
 		}
 
+		// Parameters left out by the caller arrive empty. Fill them in order,
+		// so a default value can use the parameters before it.
+		for i := range node.Parameters {
+			def := node.Default(i)
+			if def == nil {
+				continue
+			}
+			pos := c.emit(OpJumpIfPassed, i, 9999)
+			if err := c.Compile(def); err != nil {
+				return err
+			}
+			c.emit(OpStoreLocal, i)
+			c.replaceInstruction(pos, Make(OpJumpIfPassed, i, len(c.currentInstructions())))
+		}
+
+		// Checked once defaults are filled in, so a wrong default is caught too
+		if c.Strict {
+			for i := range node.Parameters {
+				if t := node.ParamType(i); t != nil {
+					types := c.addConstant(object.NewString(strings.Join(t.Types(), "|")))
+					c.emit(OpCheckType, i, types)
+				}
+			}
+		}
+
 		err := c.Compile(node.Body)
 		if err != nil {
 			return err
@@ -614,7 +904,14 @@ func (c *Compiler) Compile(node parser.Node) error {
 			}
 		}
 
-		ptr, err := memory.AllocCompiledFunction(instructions, numLocals, len(node.Parameters))
+		// Parameter names, for calls with named arguments
+		names := make([]object.Object, len(node.Parameters))
+		for i, p := range node.Parameters {
+			names[i] = object.NewString(p.Value)
+		}
+		paramNames := object.NewArray(names)
+
+		ptr, err := memory.AllocFunctionWithArity(instructions, numLocals, len(node.Parameters), node.MinArgs(), node.Variadic, paramNames.Address)
 		if err != nil {
 			return err
 		}
@@ -632,24 +929,122 @@ func (c *Compiler) Compile(node parser.Node) error {
 		c.emit(OpReturnValue)
 
 	case *parser.CallExpression:
-		err := c.Compile(node.Function)
-		if err != nil {
-			return err
-		}
-
-		for _, a := range node.Arguments {
-			err := c.Compile(a)
-			if err != nil {
+		return c.compileChain(func() error {
+			// obj.metode(...) looks the method up at run time, see OpCallMethod
+			method, isMethod := methodCallee(node)
+			if isMethod {
+				if err := c.compileLinkLeft(method.Left, method.Optional()); err != nil {
+					return err
+				}
+			} else if err := c.compileLinkLeft(node.Function, false); err != nil {
 				return err
 			}
-		}
 
-		c.emit(OpCall, len(node.Arguments))
+			// A named argument pushes its name before its value
+			for _, a := range node.Arguments {
+				if named, ok := a.(*parser.NamedArgument); ok {
+					c.emit(OpLoadConst, c.addConstant(object.NewString(named.Name.Value)))
+					a = named.Value
+				}
+				err := c.compileOperand(a)
+				if err != nil {
+					return err
+				}
+			}
+
+			named := len(node.NamedArguments())
+			if isMethod {
+				nameIdx := c.addConstant(object.NewString(method.Index.(*parser.StringLiteral).Value))
+				c.emit(OpCallMethod, nameIdx, len(node.Arguments)-named, named)
+			} else if named > 0 {
+				c.emit(OpCallNamed, len(node.Arguments)-named, named)
+			} else {
+				c.emit(OpCall, len(node.Arguments))
+			}
+			return nil
+		})
+
+	case *parser.NamedArgument:
+		return fmt.Errorf("named argument %s outside of a call", node.Name.Value)
 	}
 
 	return nil
 }
 
+// compileChain compiles an index, slice or call expression as a link of a
+// chain: a `?.` anywhere on its left jumps past the whole chain, so a?.b.c
+// is kosong when a is.
+func (c *Compiler) compileChain(compile func() error) error {
+	if c.chain != nil {
+		return compile()
+	}
+	c.chain = &[]int{}
+	defer func() { c.chain = nil }()
+
+	if err := compile(); err != nil {
+		return err
+	}
+	end := len(c.currentInstructions())
+	for _, pos := range *c.chain {
+		c.changeOperand(pos, end)
+	}
+	return nil
+}
+
+// compileLinkLeft compiles the left side of a chain link. Only index, slice
+// and call expressions continue the chain; when optional, a kosong left side
+// is left on the stack as the value of the chain.
+func (c *Compiler) compileLinkLeft(left parser.Expression, optional bool) error {
+	var err error
+	switch left.(type) {
+	case *parser.IndexExpression, *parser.SliceExpression, *parser.CallExpression:
+		err = c.Compile(left)
+	default:
+		err = c.compileOperand(left)
+	}
+	if err != nil || !optional {
+		return err
+	}
+
+	c.emit(OpDup)
+	c.emit(OpMatchType, int(memory.TagNull))
+	notNullPos := c.emit(OpJumpNotTruthy, 9999)
+	*c.chain = append(*c.chain, c.emit(OpJump, 9999))
+	c.changeOperand(notNullPos, len(c.currentInstructions()))
+	return nil
+}
+
+// compileOperand compiles an expression outside of the current chain, such
+// as an index or an argument.
+func (c *Compiler) compileOperand(node parser.Node) error {
+	saved := c.chain
+	c.chain = nil
+	err := c.Compile(node)
+	c.chain = saved
+	return err
+}
+
+// methodCallee returns the dot expression of a call like `obj.metode(...)`
+// or `obj?.metode(...)`.
+func methodCallee(call *parser.CallExpression) (*parser.IndexExpression, bool) {
+	dot, ok := call.Function.(*parser.IndexExpression)
+	if !ok || dot.Token.Type != lexer.DOT && !dot.Optional() {
+		return nil, false
+	}
+	if _, ok := dot.Index.(*parser.StringLiteral); !ok {
+		return nil, false
+	}
+	return dot, true
+}
+
+// compoundOps maps the operator of a compound assignment to its opcode.
+var compoundOps = map[string]Opcode{
+	"+": OpAdd,
+	"-": OpSub,
+	"*": OpMul,
+	"/": OpDiv,
+}
+
 func (c *Compiler) Bytecode() *Bytecode {
 	return &Bytecode{
 		Instructions: c.scopes[0].instructions,
@@ -745,10 +1140,17 @@ func (c *Compiler) changeOperand(opPos int, operand int) {
 	c.replaceInstruction(opPos, newInstruction)
 }
 
-func (c *Compiler) loadModule(path string) (int, error) {
+// modulePath maps an import path to the file it loads. The standard
+// library lives under lib/.
+func modulePath(path string) string {
 	if strings.HasPrefix(path, "cotc/") {
-		path = "lib/" + path
+		return "lib/" + path
 	}
+	return path
+}
+
+func (c *Compiler) loadModule(path string) (int, error) {
+	path = modulePath(path)
 
 	// Check Cache
 	if idx, ok := c.state.ModuleCache[path]; ok {
@@ -774,12 +1176,32 @@ func (c *Compiler) loadModule(path string) (int, error) {
 
 	exports := make(map[parser.Expression]parser.Expression)
 	dummyToken := lexer.Token{Type: lexer.STRING, Literal: "dummy"}
+	// Keys keep their name as the literal: hash literals compile in String() order
+	keyToken := func(name string) lexer.Token { return lexer.Token{Type: lexer.STRING, Literal: name} }
+
+	// Constants are exported too, but importers may not assign to them
+	constants := make(map[string]bool)
+	c.state.ModuleConstants[path] = constants
 
 	for _, stmt := range prog.Statements {
+		if constant, ok := stmt.(*parser.ConstStatement); ok {
+			name := constant.Name.Value
+			key := &parser.StringLiteral{Token: keyToken(name), Value: name}
+			val := &parser.Identifier{Token: dummyToken, Value: name}
+			exports[key] = val
+			constants[name] = true
+		}
+		if enum, ok := stmt.(*parser.EnumStatement); ok {
+			name := enum.Name.Value
+			key := &parser.StringLiteral{Token: keyToken(name), Value: name}
+			val := &parser.Identifier{Token: dummyToken, Value: name}
+			exports[key] = val
+			constants[name] = true
+		}
 		if assign, ok := stmt.(*parser.AssignmentStatement); ok {
 			if ident, ok := assign.Name.(*parser.Identifier); ok {
 				name := ident.Value
-				key := &parser.StringLiteral{Token: dummyToken, Value: name}
+				key := &parser.StringLiteral{Token: keyToken(name), Value: name}
 				val := &parser.Identifier{Token: dummyToken, Value: name}
 				exports[key] = val
 			}
@@ -788,7 +1210,7 @@ func (c *Compiler) loadModule(path string) (int, error) {
 			if fn, ok := exprStmt.Expression.(*parser.FunctionLiteral); ok {
 				if fn.Name != "" {
 					name := fn.Name
-					key := &parser.StringLiteral{Token: dummyToken, Value: name}
+					key := &parser.StringLiteral{Token: keyToken(name), Value: name}
 					val := &parser.Identifier{Token: dummyToken, Value: name}
 					exports[key] = val
 				}
@@ -808,6 +1230,7 @@ func (c *Compiler) loadModule(path string) (int, error) {
 	}
 
 	subComp := NewWithState(c.state)
+	subComp.Strict = c.Strict
 	err = subComp.Compile(wrapperFn)
 	if err != nil {
 		return 0, fmt.Errorf("import compile error in %s: %v", path, err)
@@ -833,6 +1256,61 @@ func (c *Compiler) loadModule(path string) (int, error) {
 	return modIdx, nil
 }
 
+// storeName stores the value on top of the stack in the variable name,
+// defining it when it is new.
+func (c *Compiler) storeName(name string) error {
+	symbol, err := c.symbolTable.Assign(name)
+	if err != nil {
+		return err
+	}
+	c.storeSymbol(symbol)
+	return nil
+}
+
+// checkReadOnly rejects writes to the constant exports of a module, as in
+// `modul.BATAS = 1`, and to the variants of a pilihan, as in
+// `Status.Baru = 1`.
+func (c *Compiler) checkReadOnly(target *parser.IndexExpression) error {
+	ident, ok := target.Left.(*parser.Identifier)
+	if !ok {
+		return nil
+	}
+	symbol, ok := c.symbolTable.Resolve(ident.Value)
+	if !ok {
+		return nil
+	}
+	if symbol.Enum {
+		return fmt.Errorf("cannot assign to pilihan %s: its variants are fixed", ident.Value)
+	}
+	key, ok := target.Index.(*parser.StringLiteral)
+	if ok && symbol.ReadOnly[key.Value] {
+		return fmt.Errorf("cannot assign to constant %s.%s", ident.Value, key.Value)
+	}
+	return nil
+}
+
+func (c *Compiler) storeSymbol(symbol Symbol) {
+	if symbol.Scope == GlobalScope {
+		c.emit(OpStoreGlobal, symbol.Index)
+	} else if symbol.Scope == FreeScope {
+		c.emit(OpSetFree, symbol.Index)
+	} else {
+		c.emit(OpStoreLocal, symbol.Index)
+	}
+}
+
+func (c *Compiler) loadSymbol(symbol Symbol) {
+	if symbol.Folded() {
+		c.emit(OpLoadConst, symbol.ConstIndex)
+	} else if symbol.Scope == GlobalScope {
+		c.emit(OpLoadGlobal, symbol.Index)
+	} else if symbol.Scope == LocalScope {
+		c.emit(OpLoadLocal, symbol.Index)
+	} else if symbol.Scope == FreeScope {
+		c.emit(OpGetFree, symbol.Index)
+	}
+}
+
 func (c *Compiler) currentLoopScope() *LoopScope {
 	scopes := c.scopes[c.scopeIndex].loopScopes
 	if len(scopes) == 0 {
@@ -0,0 +1,51 @@
+package compiler
+
+import (
+	"testing"
+
+	"github.com/VzoelFox/morphlang/pkg/object"
+)
+
+func TestStrictTypeCheck(t *testing.T) {
+	input := "fungsi f(a: integer) -> string\n  kembalikan a\nakhir\nf(\"x\")\n"
+
+	if err := New().Compile(parse(input)); err != nil {
+		t.Fatalf("mismatches are only warnings by default, got %v", err)
+	}
+
+	comp := New()
+	comp.Strict = true
+	err := comp.Compile(parse(input))
+	want := "type check failed at line 2: return value of f must be string, got integer"
+	if err == nil || err.Error() != want {
+		t.Errorf("expected %q, got %v", want, err)
+	}
+}
+
+func TestStrictParameterGuards(t *testing.T) {
+	input := "fungsi f(a, b: integer | galat = 1) a akhir"
+
+	tests := []struct {
+		strict bool
+		guards int
+	}{{false, 0}, {true, 1}}
+
+	for _, tt := range tests {
+		comp := New()
+		comp.Strict = tt.strict
+		if err := comp.Compile(parse(input)); err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+
+		guards := 0
+		for _, c := range comp.Bytecode().Constants {
+			// The guard's operand names the allowed types
+			if s, ok := c.(*object.String); ok && s.GetValue() == "integer|error" {
+				guards++
+			}
+		}
+		if guards != tt.guards {
+			t.Errorf("strict=%v: expected %d type guards, got %d", tt.strict, tt.guards, guards)
+		}
+	}
+}
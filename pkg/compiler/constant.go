@@ -0,0 +1,162 @@
+package compiler
+
+import (
+	"math"
+
+	"github.com/VzoelFox/morphlang/pkg/object"
+	"github.com/VzoelFox/morphlang/pkg/parser"
+)
+
+// compileConstant binds a `tetap` declaration. A value that folds to a
+// literal goes to the constant pool and its uses load it from there;
+// anything else is stored like a variable that cannot be assigned again.
+func (c *Compiler) compileConstant(node *parser.ConstStatement) error {
+	name := node.Name.Value
+
+	if value, ok := c.fold(node.Value); ok {
+		_, err := c.symbolTable.DefineConstant(name, c.addConstant(constantObject(value)))
+		return err
+	}
+
+	// The value is compiled first so it cannot refer to the new binding
+	if err := c.Compile(node.Value); err != nil {
+		return err
+	}
+	symbol, err := c.symbolTable.DefineConstant(name, -1)
+	if err != nil {
+		return err
+	}
+	c.storeSymbol(symbol)
+	return nil
+}
+
+// fold evaluates expr at compile time when it is made of literals and folded
+// constants. The result is an int64, float64, string or bool. Operations
+// whose result depends on the VM (overflow, division by zero, mixed types)
+// are left for runtime.
+func (c *Compiler) fold(expr parser.Expression) (interface{}, bool) {
+	switch node := expr.(type) {
+	case *parser.IntegerLiteral:
+		return node.Value, true
+	case *parser.FloatLiteral:
+		return node.Value, true
+	case *parser.StringLiteral:
+		return node.Value, true
+	case *parser.BooleanLiteral:
+		return node.Value, true
+
+	case *parser.Identifier:
+		symbol, ok := c.symbolTable.Resolve(node.Value)
+		if !ok || !symbol.Folded() {
+			return nil, false
+		}
+		switch obj := c.state.Constants[symbol.ConstIndex].(type) {
+		case *object.Integer:
+			return obj.GetValue(), true
+		case *object.Float:
+			return obj.GetValue(), true
+		case *object.String:
+			return obj.GetValue(), true
+		case *object.Boolean:
+			return obj.GetValue(), true
+		}
+
+	case *parser.PrefixExpression:
+		right, ok := c.fold(node.Right)
+		if !ok {
+			return nil, false
+		}
+		switch v := right.(type) {
+		case int64:
+			if node.Operator == "-" && v != math.MinInt64 {
+				return -v, true
+			}
+		case float64:
+			if node.Operator == "-" {
+				return -v, true
+			}
+		case bool:
+			if node.Operator == "!" {
+				return !v, true
+			}
+		}
+
+	case *parser.InfixExpression:
+		left, ok := c.fold(node.Left)
+		if !ok {
+			return nil, false
+		}
+		right, ok := c.fold(node.Right)
+		if !ok {
+			return nil, false
+		}
+		return foldInfix(node.Operator, left, right)
+	}
+	return nil, false
+}
+
+func foldInfix(op string, left, right interface{}) (interface{}, bool) {
+	switch l := left.(type) {
+	case int64:
+		r, ok := right.(int64)
+		if !ok {
+			return nil, false
+		}
+		switch op {
+		case "+":
+			if res := l + r; (res > l) == (r > 0) {
+				return res, true
+			}
+		case "-":
+			if res := l - r; (res < l) == (r > 0) {
+				return res, true
+			}
+		case "*":
+			if l == 0 || r == 0 {
+				return int64(0), true
+			}
+			if res := l * r; res/r == l && !(l == -1 && r == math.MinInt64) && !(r == -1 && l == math.MinInt64) {
+				return res, true
+			}
+		case "/":
+			if r != 0 && !(l == math.MinInt64 && r == -1) {
+				return l / r, true
+			}
+		}
+	case float64:
+		r, ok := right.(float64)
+		if !ok {
+			return nil, false
+		}
+		switch op {
+		case "+":
+			return l + r, true
+		case "-":
+			return l - r, true
+		case "*":
+			return l * r, true
+		case "/":
+			if r != 0 {
+				return l / r, true
+			}
+		}
+	case string:
+		if r, ok := right.(string); ok && op == "+" {
+			return l + r, true
+		}
+	}
+	return nil, false
+}
+
+func constantObject(value interface{}) object.Object {
+	switch v := value.(type) {
+	case int64:
+		return object.NewInteger(v)
+	case float64:
+		return object.NewFloat(v)
+	case string:
+		return object.NewString(v)
+	default:
+		return object.NewBoolean(v.(bool))
+	}
+}
@@ -0,0 +1,106 @@
+package compiler
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConstantFolding(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			// Folded constants take no slot; every use loads the pool entry
+			input:             "tetap MENIT = 60; tetap JAM = MENIT * 60; JAM",
+			expectedConstants: []interface{}{60, 3600},
+			expectedInstructions: []Instructions{
+				Make(OpLoadConst, 1),
+				Make(OpPop),
+			},
+		},
+		{
+			input:             "tetap SETENGAH = -1.0 / 2.0; SETENGAH",
+			expectedConstants: []interface{}{-0.5},
+			expectedInstructions: []Instructions{
+				Make(OpLoadConst, 0),
+				Make(OpPop),
+			},
+		},
+		{
+			// Other values are stored once like a variable
+			input:             "tetap DAFTAR = [1]; DAFTAR",
+			expectedConstants: []interface{}{1},
+			expectedInstructions: []Instructions{
+				Make(OpLoadConst, 0),
+				Make(OpArray, 1),
+				Make(OpStoreGlobal, 0),
+				Make(OpLoadGlobal, 0),
+				Make(OpPop),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestConstantReassignment(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"tetap A = 1; A = 2", "cannot assign to constant A"},
+		{"tetap A = [1]; A += [2]", "cannot assign to constant A"},
+		{"tetap A = 1; fungsi f() A = 2 akhir", "cannot assign to constant A"},
+		{"tetap A = 1; untuk A dalam [1] A akhir", "cannot assign to constant A"},
+		{"tetap A = 1; fungsi A() 1 akhir", "cannot redefine constant A"},
+		{"a = 1; tetap a = 2", "cannot declare constant a"},
+		{"pilihan S\n A\nakhir\nS = 1", "cannot assign to constant S"},
+		{"pilihan S\n A\nakhir\nS.A = 5", "cannot assign to pilihan S: its variants are fixed"},
+		{"pilihan S\n A\nakhir\nS.Baru = 7", "cannot assign to pilihan S: its variants are fixed"},
+		{"pilihan S\n A\nakhir\nS[\"A\"] += 1", "cannot assign to pilihan S: its variants are fixed"},
+		{"pilihan S\n A\nakhir\nfungsi f() S.A = 1 akhir", "cannot assign to pilihan S: its variants are fixed"},
+	}
+
+	for _, tt := range tests {
+		err := New().Compile(parse(tt.input))
+		if err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("%q: expected error %q, got %v", tt.input, tt.expected, err)
+		}
+	}
+
+	// Parameters shadow outer constants
+	if err := New().Compile(parse("tetap A = 1; fungsi f(A) A = 2 akhir")); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestModuleConstantsAreReadOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "modul.fox")
+	if err := os.WriteFile(path, []byte("tetap BATAS = 10\nnilai = 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`ambil "` + path + `"; modul.nilai = 2`, ""},
+		{`ambil "` + path + `"; modul.BATAS = 2`, "cannot assign to constant modul.BATAS"},
+		{`ambil "` + path + `"; modul["BATAS"] += 1`, "cannot assign to constant modul.BATAS"},
+		{`dari "` + path + `" ambil BATAS, nilai; nilai = 2`, ""},
+		{`dari "` + path + `" ambil BATAS; BATAS = 2`, "cannot assign to constant BATAS"},
+	}
+
+	for _, tt := range tests {
+		err := New().Compile(parse(tt.input))
+		if tt.expected == "" {
+			if err != nil {
+				t.Errorf("%q: unexpected error: %v", tt.input, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("%q: expected error %q, got %v", tt.input, tt.expected, err)
+		}
+	}
+}
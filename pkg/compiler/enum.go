@@ -0,0 +1,35 @@
+package compiler
+
+import (
+	"github.com/VzoelFox/morphlang/pkg/object"
+	"github.com/VzoelFox/morphlang/pkg/parser"
+)
+
+// compileEnum binds a `pilihan` to a hash from variant name to variant. A
+// variant written without fields is a ready-made value; the others are
+// constructors called like a struktur: `Status.Berjalan(pid: 1)`. Their
+// schemas are named "Status.Berjalan", which tells the VM to build variants.
+func (c *Compiler) compileEnum(node *parser.EnumStatement) error {
+	for _, v := range node.Variants {
+		c.emit(OpLoadConst, c.addConstant(object.NewString(v.Name.Value)))
+		for _, f := range v.Fields {
+			c.emit(OpLoadConst, c.addConstant(object.NewString(f.Value)))
+		}
+		name := c.addConstant(object.NewString(node.Name.Value + "." + v.Name.Value))
+		c.emit(OpStruct, name, len(v.Fields), 0)
+		if v.Fields == nil {
+			c.emit(OpCall, 0)
+		}
+	}
+	c.emit(OpHash, 2*len(node.Variants))
+	c.emit(OpFreeze)
+
+	// The set of variants is closed: neither the name nor the table can be
+	// assigned again
+	symbol, err := c.symbolTable.DefineEnum(node.Name.Value)
+	if err != nil {
+		return err
+	}
+	c.storeSymbol(symbol)
+	return nil
+}
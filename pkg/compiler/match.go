@@ -0,0 +1,345 @@
+package compiler
+
+import (
+	"fmt"
+
+	"github.com/VzoelFox/morphlang/pkg/memory"
+	"github.com/VzoelFox/morphlang/pkg/object"
+	"github.com/VzoelFox/morphlang/pkg/parser"
+)
+
+// compileMatch lowers `cocokkan` into ordinary jumps. The subject lives in a
+// hidden variable; every check in an arm pushes a boolean and jumps to the
+// next arm when it is false. The match leaves the value of the chosen arm
+// (or null when nothing matched) on the stack.
+func (c *Compiler) compileMatch(node *parser.MatchExpression) error {
+	err := c.Compile(node.Subject)
+	if err != nil {
+		return err
+	}
+
+	subject := c.symbolTable.Define(fmt.Sprintf("$match%d", len(c.currentInstructions())))
+	c.storeSymbol(subject)
+	loadSubject := func() { c.loadSymbol(subject) }
+
+	endJumps := []int{}
+
+	for _, arm := range node.Arms {
+		failJumps := []int{}
+		binds := []patternBinding{}
+
+		err := c.compilePattern(arm.Pattern, loadSubject, &failJumps, &binds, false)
+		if err != nil {
+			return err
+		}
+
+		if arm.Guard != nil {
+			// The guard sees the bindings before they are stored
+			restore := make([]func(), len(binds))
+			for i, b := range binds {
+				restore[i] = c.symbolTable.Shadow(b.name, b.hidden)
+			}
+			err := c.Compile(arm.Guard)
+			for i := len(restore) - 1; i >= 0; i-- {
+				restore[i]()
+			}
+			if err != nil {
+				return err
+			}
+			failJumps = append(failJumps, c.emit(OpJumpNotTruthy, 9999))
+		}
+
+		err = c.storeBindings(binds)
+		if err != nil {
+			return err
+		}
+
+		err = c.compileArmBody(arm.Body)
+		if err != nil {
+			return err
+		}
+
+		endJumps = append(endJumps, c.emit(OpJump, 9999))
+
+		nextArmPos := len(c.currentInstructions())
+		for _, pos := range failJumps {
+			c.changeOperand(pos, nextArmPos)
+		}
+	}
+
+	if node.Alternative != nil {
+		err := c.compileArmBody(node.Alternative)
+		if err != nil {
+			return err
+		}
+	} else {
+		c.emit(OpLoadConst, c.addConstant(object.NewNull()))
+	}
+
+	afterMatchPos := len(c.currentInstructions())
+	for _, pos := range endJumps {
+		c.changeOperand(pos, afterMatchPos)
+	}
+
+	return nil
+}
+
+func (c *Compiler) compileArmBody(body *parser.BlockStatement) error {
+	err := c.Compile(body)
+	if err != nil {
+		return err
+	}
+
+	if c.scopes[c.scopeIndex].lastInstruction.Opcode == OpPop {
+		c.removeLastPop()
+	} else {
+		c.emit(OpLoadConst, c.addConstant(object.NewNull()))
+	}
+	return nil
+}
+
+// patternBinding is a name bound by a pattern, held in a hidden variable
+// until the whole pattern has matched.
+type patternBinding struct {
+	name   string
+	hidden Symbol
+}
+
+// compilePattern emits the checks and bindings for one pattern. load pushes
+// the value being matched; it is re-run for every check so nested patterns
+// simply index further into the subject. Failing checks are collected in
+// fails and patched by the caller. Bound values go to hidden variables listed
+// in binds, so a pattern that fails halfway leaves the named variables alone;
+// the caller stores them with storeBindings. When destructuring, `{...}`
+// patterns take the fields of a struct as well as the keys of a hash.
+func (c *Compiler) compilePattern(pattern parser.Pattern, load func(), fails *[]int, binds *[]patternBinding, destructure bool) error {
+	check := func() {
+		*fails = append(*fails, c.emit(OpJumpNotTruthy, 9999))
+	}
+	bind := func(name string) {
+		hidden := c.symbolTable.Define(fmt.Sprintf("$bind%d", len(c.currentInstructions())))
+		c.storeSymbol(hidden)
+		*binds = append(*binds, patternBinding{name: name, hidden: hidden})
+	}
+
+	switch pat := pattern.(type) {
+	case *parser.BindingPattern:
+		if pat.IsWildcard() {
+			return nil
+		}
+		load()
+		bind(pat.Name.Value)
+
+	case *parser.LiteralPattern:
+		tag, err := literalPatternTag(pat.Value)
+		if err != nil {
+			return err
+		}
+		load()
+		c.emit(OpMatchType, int(tag))
+		check()
+
+		if tag != memory.TagNull {
+			load()
+			err := c.Compile(pat.Value)
+			if err != nil {
+				return err
+			}
+			c.emit(OpEqual)
+			check()
+		}
+
+	case *parser.ArrayPattern:
+		load()
+		c.emit(OpMatchType, int(memory.TagArray))
+		check()
+
+		c.emit(OpGetBuiltin, object.GetBuiltinByName("panjang"))
+		load()
+		c.emit(OpCall, 1)
+		c.emit(OpLoadConst, c.addConstant(object.NewInteger(int64(len(pat.Elements)))))
+		if pat.HasRest {
+			c.emit(OpGreaterEqual)
+		} else {
+			c.emit(OpEqual)
+		}
+		check()
+
+		for i, el := range pat.Elements {
+			indexConst := c.addConstant(object.NewInteger(int64(i)))
+			loadElement := func() {
+				load()
+				c.emit(OpLoadConst, indexConst)
+				c.emit(OpIndex)
+			}
+			err := c.compilePattern(el, loadElement, fails, binds, destructure)
+			if err != nil {
+				return err
+			}
+		}
+
+		if pat.Rest != nil && pat.Rest.Value != "_" {
+			load()
+			c.emit(OpArrayRest, len(pat.Elements))
+			bind(pat.Rest.Value)
+		}
+
+	case *parser.HashPattern:
+		// OpMatchKey alone fails for anything but hashes and structs
+		if pat.Schema != nil {
+			load()
+			c.emit(OpMatchSchema, c.addConstant(object.NewString(pat.Schema.Value)))
+			check()
+		} else if !destructure {
+			load()
+			c.emit(OpMatchType, int(memory.TagHash))
+			check()
+		}
+
+		for i, key := range pat.Keys {
+			var keyObj object.Object
+			switch k := key.(type) {
+			case *parser.StringLiteral:
+				keyObj = object.NewString(k.Value)
+			case *parser.IntegerLiteral:
+				keyObj = object.NewInteger(k.Value)
+			default:
+				return fmt.Errorf("unsupported pattern key %s", key.String())
+			}
+			keyConst := c.addConstant(keyObj)
+
+			load()
+			c.emit(OpLoadConst, keyConst)
+			c.emit(OpMatchKey)
+			check()
+
+			loadField := func() {
+				load()
+				c.emit(OpLoadConst, keyConst)
+				c.emit(OpIndex)
+			}
+			err := c.compilePattern(pat.Values[i], loadField, fails, binds, destructure)
+			if err != nil {
+				return err
+			}
+		}
+
+	case *parser.ErrorPattern:
+		load()
+		c.emit(OpMatchType, int(memory.TagError))
+		check()
+
+		parts := []struct {
+			pattern parser.Pattern
+			builtin string
+		}{
+			{pat.Message, "pesan_galat"},
+			{pat.Code, "kode_galat"},
+		}
+		for _, part := range parts {
+			if part.pattern == nil {
+				continue
+			}
+			builtinIndex := object.GetBuiltinByName(part.builtin)
+			loadPart := func() {
+				c.emit(OpGetBuiltin, builtinIndex)
+				load()
+				c.emit(OpCall, 1)
+			}
+			err := c.compilePattern(part.pattern, loadPart, fails, binds, destructure)
+			if err != nil {
+				return err
+			}
+		}
+
+	default:
+		return fmt.Errorf("unknown pattern %T", pattern)
+	}
+
+	return nil
+}
+
+// storeBindings copies the hidden variables of a matched pattern into the
+// names they bind.
+func (c *Compiler) storeBindings(binds []patternBinding) error {
+	for _, b := range binds {
+		c.loadSymbol(b.hidden)
+		if err := c.storeName(b.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compileDestructuring binds the names of a destructuring assignment. When
+// the value does not fit the pattern, an Error describing the mismatch is
+// returned from the current function (or fails the program at top level);
+// an Error value is returned as is.
+func (c *Compiler) compileDestructuring(node *parser.DestructuringStatement) error {
+	err := c.Compile(node.Value)
+	if err != nil {
+		return err
+	}
+	c.emit(OpPropagate)
+
+	value := c.symbolTable.Define(fmt.Sprintf("$destructure%d", len(c.currentInstructions())))
+	c.storeSymbol(value)
+
+	fails := []int{}
+	binds := []patternBinding{}
+	err = c.compilePattern(node.Target, func() { c.loadSymbol(value) }, &fails, &binds, true)
+	if err != nil {
+		return err
+	}
+	if len(fails) == 0 {
+		return c.storeBindings(binds)
+	}
+	end := c.emit(OpJump, 9999)
+
+	failPos := len(c.currentInstructions())
+	for _, pos := range fails {
+		c.changeOperand(pos, failPos)
+	}
+	c.emit(OpGetBuiltin, object.GetBuiltinByName("galat"))
+	c.emit(OpLoadConst, c.addConstant(object.NewString(destructuringMismatch(node.Target))))
+	c.emit(OpCall, 1)
+	c.emit(OpPropagate) // Always returns: the value is an Error
+
+	c.changeOperand(end, len(c.currentInstructions()))
+	return c.storeBindings(binds)
+}
+
+// destructuringMismatch describes the values a destructuring pattern accepts.
+func destructuringMismatch(target parser.Pattern) string {
+	switch pat := target.(type) {
+	case *parser.ArrayPattern:
+		if pat.HasRest {
+			return fmt.Sprintf("destructuring mismatch: want an array of at least %d elements for %s", len(pat.Elements), pat.String())
+		}
+		return fmt.Sprintf("destructuring mismatch: want an array of %d elements for %s", len(pat.Elements), pat.String())
+	case *parser.HashPattern:
+		if pat.Schema != nil {
+			return fmt.Sprintf("destructuring mismatch: want a %s for %s", pat.Schema.Value, pat.String())
+		}
+		return fmt.Sprintf("destructuring mismatch: want a hash or struct with the keys of %s", pat.String())
+	}
+	return "destructuring mismatch for " + target.String()
+}
+
+func literalPatternTag(value parser.Expression) (memory.TypeTag, error) {
+	switch v := value.(type) {
+	case *parser.IntegerLiteral:
+		return memory.TagInteger, nil
+	case *parser.FloatLiteral:
+		return memory.TagFloat, nil
+	case *parser.StringLiteral:
+		return memory.TagString, nil
+	case *parser.BooleanLiteral:
+		return memory.TagBoolean, nil
+	case *parser.NullLiteral:
+		return memory.TagNull, nil
+	case *parser.PrefixExpression:
+		return literalPatternTag(v.Right)
+	}
+	return 0, fmt.Errorf("unsupported literal pattern %s", value.String())
+}
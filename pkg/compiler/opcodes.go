@@ -8,6 +8,7 @@ const (
 	// Stack Manipulation
 	OpPop Opcode = 0x01
 	OpDup Opcode = 0x02
+	OpDup2 Opcode = 0x03 // Duplicates the top two values, keeping their order
 
 	// Constants & Variables
 	OpLoadConst  Opcode = 0x10
@@ -23,6 +24,10 @@ const (
 	OpIndex Opcode = 0x1C
 	OpSetIndex Opcode = 0x1D
 	OpStruct   Opcode = 0x1E
+	OpSlice    Opcode = 0x1F // Pops end, start and container; pushes a new array, string or range (kosong bounds are open)
+	OpRange    Opcode = 0x19 // Pops end and start, pushes a range; operand 1 leaves out the end
+	OpFreeze   Opcode = 0x18 // Makes the hash on top of the stack read-only
+	OpFreezeKey Opcode = 0x17 // Makes the key (constant operand) of the hash on top of the stack read-only
 
 	// Arithmetic & Logic
 	OpAdd Opcode = 0x20
@@ -33,6 +38,9 @@ const (
 	OpNotEqual Opcode = 0x25
 	OpGreaterThan Opcode = 0x26
 	OpGreaterEqual Opcode = 0x27
+	OpMod      Opcode = 0x34 // % (floored)
+	OpFloorDiv Opcode = 0x35 // //
+	OpPow      Opcode = 0x36 // **
 	OpMinus Opcode = 0x2F // Unary minus
 	OpBang  Opcode = 0x2E // Unary not
 
@@ -50,16 +58,31 @@ const (
 
 	// Functions
 	OpCall      Opcode = 0x40
+	OpCallNamed Opcode = 0x4A // Like OpCall, followed by (name, value) pairs for named arguments
+	OpCallMethod Opcode = 0x4B // Calls a method of the receiver below the arguments, binding it as `ini`
 	OpReturn    Opcode = 0x41 // RETURN in spec (returns null/void)
 	OpReturnValue Opcode = 0x42 // RETURN_VAL in spec (returns value)
+	OpPropagate Opcode = 0x48 // Returns the top of stack if it is an Error, else leaves it
 	OpClosure   Opcode = 0x43
 	OpGetFree   Opcode = 0x44
 	OpSetFree   Opcode = 0x45
 	OpCaptureLocal Opcode = 0x46
 	OpLoadUpvalue  Opcode = 0x47
+	OpJumpIfPassed Opcode = 0x49 // Jumps to the second operand if the parameter (local) received an argument
+	OpCheckType    Opcode = 0x4C // Returns a type mismatch Error unless the parameter (local) has one of the named types
 
 	// Modules
 	OpUpdateModule Opcode = 0x50
+
+	// Iteration
+	OpIter     Opcode = 0x60 // Pushes initial loop value and iterator
+	OpIterNext Opcode = 0x61 // Pushes next item(s) then true, or false when done
+
+	// Pattern Matching (never propagate Error operands)
+	OpMatchType   Opcode = 0x70 // Pops value, pushes whether its type tag equals the operand
+	OpMatchSchema Opcode = 0x71 // Pops value, pushes whether it is a struct of the named schema
+	OpMatchKey    Opcode = 0x72 // Pops key and container, pushes whether the key/field exists
+	OpArrayRest   Opcode = 0x73 // Pops array, pushes a new array of the elements from operand on
 )
 
 type Definition struct {
@@ -70,6 +93,7 @@ type Definition struct {
 var definitions = map[Opcode]*Definition{
 	OpPop:         {"OpPop", []int{}},
 	OpDup:         {"OpDup", []int{}},
+	OpDup2:        {"OpDup2", []int{}},
 	OpLoadConst:   {"OpLoadConst", []int{2}}, // u16 index
 	OpLoadGlobal:  {"OpLoadGlobal", []int{2}}, // u16 index
 	OpStoreGlobal: {"OpStoreGlobal", []int{2}}, // u16 index
@@ -80,11 +104,18 @@ var definitions = map[Opcode]*Definition{
 	OpHash:        {"OpHash", []int{2}}, // u16 pair count (keys + values)
 	OpIndex:       {"OpIndex", []int{}},
 	OpSetIndex:    {"OpSetIndex", []int{}},
-	OpStruct:      {"OpStruct", []int{2, 2}},
+	OpStruct:      {"OpStruct", []int{2, 2, 1}}, // u16 name constIndex, u16 fields, u8 methods
+	OpSlice:       {"OpSlice", []int{}},
+	OpRange:       {"OpRange", []int{1}}, // u8 exclusive
+	OpFreeze:      {"OpFreeze", []int{}},
+	OpFreezeKey:   {"OpFreezeKey", []int{2}}, // u16 key constIndex
 	OpAdd:         {"OpAdd", []int{}},
 	OpSub:         {"OpSub", []int{}},
 	OpMul:         {"OpMul", []int{}},
 	OpDiv:         {"OpDiv", []int{}},
+	OpMod:         {"OpMod", []int{}},
+	OpFloorDiv:    {"OpFloorDiv", []int{}},
+	OpPow:         {"OpPow", []int{}},
 	OpEqual:       {"OpEqual", []int{}},
 	OpNotEqual:    {"OpNotEqual", []int{}},
 	OpGreaterThan: {"OpGreaterThan", []int{}},
@@ -100,6 +131,8 @@ var definitions = map[Opcode]*Definition{
 	OpJump:        {"OpJump", []int{2}}, // u16 offset
 	OpJumpNotTruthy: {"OpJumpNotTruthy", []int{2}}, // u16 offset
 	OpCall:        {"OpCall", []int{1}}, // u8 numArgs
+	OpCallNamed:   {"OpCallNamed", []int{1, 1}}, // u8 positional args, u8 named args
+	OpCallMethod:  {"OpCallMethod", []int{2, 1, 1}}, // u16 name constIndex, u8 positional args, u8 named args
 	OpReturn:      {"OpReturn", []int{}},
 	OpReturnValue: {"OpReturnValue", []int{}},
 	OpClosure:     {"OpClosure", []int{2, 1}}, // u16 constIndex, u8 numFreeVars
@@ -107,7 +140,16 @@ var definitions = map[Opcode]*Definition{
 	OpSetFree:     {"OpSetFree", []int{1}},    // u8 index
 	OpCaptureLocal: {"OpCaptureLocal", []int{1}}, // u8 index (local index)
 	OpLoadUpvalue:  {"OpLoadUpvalue", []int{1}},  // u8 index
+	OpPropagate:    {"OpPropagate", []int{}},
+	OpJumpIfPassed: {"OpJumpIfPassed", []int{1, 2}}, // u8 local index, u16 target
+	OpCheckType:    {"OpCheckType", []int{1, 2}},    // u8 local index, u16 constIndex of "integer|error"
 	OpUpdateModule: {"OpUpdateModule", []int{}},
+	OpIter:        {"OpIter", []int{}},
+	OpIterNext:    {"OpIterNext", []int{1}}, // u8 loop variable count
+	OpMatchType:   {"OpMatchType", []int{1}},   // u8 type tag
+	OpMatchSchema: {"OpMatchSchema", []int{2}}, // u16 schema name constant
+	OpMatchKey:    {"OpMatchKey", []int{}},
+	OpArrayRest:   {"OpArrayRest", []int{2}},   // u16 start index
 }
 
 func Lookup(op byte) (*Definition, error) {
@@ -1,5 +1,7 @@
 package compiler
 
+import "fmt"
+
 type SymbolScope string
 
 const (
@@ -13,6 +15,23 @@ type Symbol struct {
 	Name  string
 	Scope SymbolScope
 	Index int
+
+	// Constant symbols are bound once, by `tetap`. A constant whose value is
+	// known at compile time is folded into the constant pool at ConstIndex
+	// (otherwise -1) and never read from its slot.
+	Constant   bool
+	ConstIndex int
+
+	// ReadOnly holds the constant exports of an imported module
+	ReadOnly map[string]bool
+
+	// Enum symbols hold the variant table of a `pilihan`
+	Enum bool
+}
+
+// Folded reports whether the symbol is a constant in the constant pool.
+func (s Symbol) Folded() bool {
+	return s.Constant && s.ConstIndex >= 0
 }
 
 type SymbolTable struct {
@@ -45,6 +64,96 @@ func (s *SymbolTable) Define(name string) Symbol {
 	return symbol
 }
 
+// DefineConstant defines name as a constant of this scope. constIndex is the
+// position of its folded value in the constant pool, or -1.
+func (s *SymbolTable) DefineConstant(name string, constIndex int) (Symbol, error) {
+	if _, ok := s.store[name]; ok {
+		return Symbol{}, fmt.Errorf("cannot declare constant %s: the name is already defined", name)
+	}
+	symbol := s.Define(name)
+	symbol.Constant = true
+	symbol.ConstIndex = constIndex
+	s.store[name] = symbol
+	return symbol, nil
+}
+
+// DefineEnum defines name as the constant holding the variants of a
+// `pilihan`, which cannot be assigned through it.
+func (s *SymbolTable) DefineEnum(name string) (Symbol, error) {
+	symbol, err := s.DefineConstant(name, -1)
+	if err != nil {
+		return symbol, err
+	}
+	symbol.Enum = true
+	s.store[name] = symbol
+	return symbol, nil
+}
+
+// Bind defines name in this scope for a declaration such as `fungsi`,
+// `struktur` or `ambil`, which may not replace a constant.
+func (s *SymbolTable) Bind(name string) (Symbol, error) {
+	if existing, ok := s.store[name]; ok && existing.Constant {
+		return Symbol{}, fmt.Errorf("cannot redefine constant %s", name)
+	}
+	return s.Define(name), nil
+}
+
+// Assign resolves name as the target of an assignment, defining it in this
+// scope when it is new. Constants cannot be assigned.
+func (s *SymbolTable) Assign(name string) (Symbol, error) {
+	symbol, ok := s.Resolve(name)
+	if !ok {
+		return s.Define(name), nil
+	}
+	if symbol.Constant {
+		return Symbol{}, fmt.Errorf("cannot assign to constant %s", name)
+	}
+	return symbol, nil
+}
+
+// BindModule binds an imported module under name. Its readOnly exports
+// cannot be assigned through the binding.
+func (s *SymbolTable) BindModule(name string, readOnly map[string]bool) (Symbol, error) {
+	symbol, err := s.Bind(name)
+	if err != nil {
+		return symbol, err
+	}
+	symbol.ReadOnly = readOnly
+	s.store[name] = symbol
+	return symbol, nil
+}
+
+// Shadow makes name resolve to symbol in this scope until the returned
+// function is called.
+func (s *SymbolTable) Shadow(name string, symbol Symbol) func() {
+	previous, ok := s.store[name]
+	s.store[name] = symbol
+	return func() {
+		if ok {
+			s.store[name] = previous
+		} else {
+			delete(s.store, name)
+		}
+	}
+}
+
+// Snapshot returns a function that restores the definitions of this scope to
+// what they are now.
+func (s *SymbolTable) Snapshot() func() {
+	store := make(map[string]Symbol, len(s.store))
+	for name, symbol := range s.store {
+		store[name] = symbol
+	}
+	numDefinitions := s.numDefinitions
+	numFree := len(s.FreeSymbols)
+
+	return func() {
+		s.store = store
+		s.numDefinitions = numDefinitions
+		s.FreeSymbols = s.FreeSymbols[:numFree]
+	}
+}
+
 func (s *SymbolTable) DefineBuiltin(index int, name string) Symbol {
 	symbol := Symbol{Name: name, Index: index, Scope: BuiltinScope}
 	s.store[name] = symbol
@@ -54,7 +163,9 @@ func (s *SymbolTable) DefineBuiltin(index int, name string) Symbol {
 func (s *SymbolTable) DefineFree(original Symbol) Symbol {
 	s.FreeSymbols = append(s.FreeSymbols, original)
 
-	symbol := Symbol{Name: original.Name, Index: len(s.FreeSymbols) - 1, Scope: FreeScope}
+	symbol := original
+	symbol.Index = len(s.FreeSymbols) - 1
+	symbol.Scope = FreeScope
 	s.store[original.Name] = symbol
 	return symbol
 }
@@ -71,7 +182,8 @@ func (s *SymbolTable) Resolve(name string) (Symbol, bool) {
 			return obj, ok
 		}
 
-		if obj.Scope == GlobalScope || obj.Scope == BuiltinScope {
+		// Folded constants are loaded from the pool and need no capture
+		if obj.Scope == GlobalScope || obj.Scope == BuiltinScope || obj.Folded() {
 			return obj, ok
 		}
 
@@ -2,6 +2,7 @@ package evaluator
 
 import (
 	"fmt"
+	"math"
 
 	"github.com/VzoelFox/morphlang/pkg/object"
 	"github.com/VzoelFox/morphlang/pkg/parser"
@@ -27,6 +28,20 @@ func Eval(node parser.Node, env *object.Environment) object.Object {
 
 		switch name := node.Name.(type) {
 		case *parser.Identifier:
+			// `x += 3` applies the operator to the current value of x
+			if op := node.Operator(); op != "" {
+				if isError(val) {
+					return val
+				}
+				current := evalIdentifier(name, env)
+				if isError(current) {
+					return current
+				}
+				val = evalInfixExpression(node, op, current, val)
+				if isError(val) {
+					return val
+				}
+			}
 			env.Set(name.Value, val)
 		default:
 			return newError(node.Name, "assignment not supported in evaluator for %T", node.Name)
@@ -37,6 +52,8 @@ func Eval(node parser.Node, env *object.Environment) object.Object {
 	// Expressions
 	case *parser.IntegerLiteral:
 		return object.NewInteger(node.Value)
+	case *parser.FloatLiteral:
+		return object.NewFloat(node.Value)
 	case *parser.StringLiteral:
 		return object.NewString(node.Value)
 	case *parser.BooleanLiteral:
@@ -182,6 +199,8 @@ func evalInfixExpression(node parser.Node, operator string, left, right object.O
 	switch {
 	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
 		return evalIntegerInfixExpression(node, operator, left, right)
+	case isNumber(left) && isNumber(right):
+		return evalFloatInfixExpression(node, operator, toFloat(left), toFloat(right))
 	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
 		return evalStringInfixExpression(node, operator, left, right)
 	case operator == "==":
@@ -242,9 +261,24 @@ func evalIntegerInfixExpression(node parser.Node, operator string, left, right o
 		return object.NewInteger(leftVal * rightVal)
 	case "/":
 		if rightVal == 0 {
-			return newError(node, "division by zero")
+			return newError(node, "integer divide by zero")
 		}
 		return object.NewInteger(leftVal / rightVal)
+	case "%":
+		if rightVal == 0 {
+			return newError(node, "integer divide by zero")
+		}
+		return object.NewInteger(object.FloorModInt(leftVal, rightVal))
+	case "//":
+		if rightVal == 0 {
+			return newError(node, "integer divide by zero")
+		}
+		return object.NewInteger(object.FloorDivInt(leftVal, rightVal))
+	case "**":
+		if rightVal < 0 {
+			return object.NewFloat(math.Pow(float64(leftVal), float64(rightVal)))
+		}
+		return object.NewInteger(object.PowInt(leftVal, rightVal))
 	case "<":
 		return nativeBoolToBooleanObject(leftVal < rightVal)
 	case ">":
@@ -258,6 +292,55 @@ func evalIntegerInfixExpression(node parser.Node, operator string, left, right o
 	}
 }
 
+func evalFloatInfixExpression(node parser.Node, operator string, leftVal, rightVal float64) object.Object {
+	switch operator {
+	case "+":
+		return object.NewFloat(leftVal + rightVal)
+	case "-":
+		return object.NewFloat(leftVal - rightVal)
+	case "*":
+		return object.NewFloat(leftVal * rightVal)
+	case "/":
+		if rightVal == 0 {
+			return newError(node, "float divide by zero")
+		}
+		return object.NewFloat(leftVal / rightVal)
+	case "%":
+		if rightVal == 0 {
+			return newError(node, "float divide by zero")
+		}
+		return object.NewFloat(object.FloorModFloat(leftVal, rightVal))
+	case "//":
+		if rightVal == 0 {
+			return newError(node, "float divide by zero")
+		}
+		return object.NewFloat(math.Floor(leftVal / rightVal))
+	case "**":
+		return object.NewFloat(math.Pow(leftVal, rightVal))
+	case "<":
+		return nativeBoolToBooleanObject(leftVal < rightVal)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+	default:
+		return newError(node, "unknown operator: FLOAT %s FLOAT", operator)
+	}
+}
+
+func isNumber(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.FLOAT_OBJ
+}
+
+func toFloat(obj object.Object) float64 {
+	if f, ok := obj.(*object.Float); ok {
+		return f.GetValue()
+	}
+	return float64(obj.(*object.Integer).GetValue())
+}
+
 func evalStringInfixExpression(node parser.Node, operator string, left, right object.Object) object.Object {
 	leftVal := left.(*object.String).GetValue()
 	rightVal := right.(*object.String).GetValue()
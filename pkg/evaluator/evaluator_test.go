@@ -36,6 +36,56 @@ func TestEvalIntegerExpression(t *testing.T) {
 	}
 }
 
+func TestEvalArithmeticOperators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"7 % 3", int64(1)},
+		{"-7 % 3", int64(2)},
+		{"7 % -3", int64(-2)},
+		{"7 // 2", int64(3)},
+		{"-7 // 2", int64(-4)},
+		{"2 ** 10", int64(1024)},
+		{"2 ** 3 ** 2", int64(512)},
+		{"-2 ** 2", int64(-4)},
+		{"2 ** -1", 0.5},
+		{"7.5 % 2", 1.5},
+		{"7.0 // 2", 3.0},
+		{"1 + 2.5", 3.5},
+		{"5 % 0", "integer divide by zero"},
+		{"5 // 0", "integer divide by zero"},
+		{"5.0 % 0", "float divide by zero"},
+		{"1.0 / 0", "float divide by zero"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int64:
+			testIntegerObject(t, evaluated, expected)
+		case float64:
+			result, ok := evaluated.(*object.Float)
+			if !ok {
+				t.Errorf("object is not Float. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if result.GetValue() != expected {
+				t.Errorf("object has wrong value. got=%f, want=%f", result.GetValue(), expected)
+			}
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.GetMessage() != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.GetMessage())
+			}
+		}
+	}
+}
+
 func TestEvalBooleanExpression(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -148,6 +198,9 @@ func TestAssignmentStatements(t *testing.T) {
 		{"x = 5 * 5; x;", 25},
 		{"x = 5; y = x; y;", 5},
 		{"x = 5; y = x + 5; y;", 10},
+		{"x = 5; x += 3; x;", 8},
+		{"x = 5; x -= 3; x *= 4; x;", 8},
+		{"x = 9; x /= 3; x;", 3},
 	}
 
 	for _, tt := range tests {
@@ -200,6 +253,14 @@ func TestErrorHandling(t *testing.T) {
 			"foobar",
 			"identifier not found: foobar",
 		},
+		{
+			"x += 1",
+			"identifier not found: x",
+		},
+		{
+			"x = 1; x += benar",
+			"type mismatch: INTEGER + BOOLEAN",
+		},
 	}
 
 	for _, tt := range tests {
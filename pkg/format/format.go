@@ -0,0 +1,794 @@
+// Package format prints Morph programs in their canonical layout, as used by
+// morphfmt.
+package format
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/VzoelFox/morphlang/pkg/lexer"
+	"github.com/VzoelFox/morphlang/pkg/parser"
+)
+
+const indentUnit = "  "
+
+// Source formats a whole .fox file. Missing spaces around binary operators
+// are fixed; files with other syntax errors are returned unchanged together
+// with the first error.
+func Source(src []byte) ([]byte, error) {
+	input := string(src)
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	for _, err := range p.Errors() {
+		if err.Code != parser.ErrMissingWhitespace {
+			return src, fmt.Errorf("%s", err.String())
+		}
+	}
+
+	pr := &printer{src: input, comments: collectComments(input)}
+	pr.program(program)
+	out := pr.buf.Bytes()
+
+	// The output must hold the same tokens and parse back to the same program
+	p2 := parser.New(lexer.New(string(out)))
+	formatted := p2.ParseProgram()
+	if len(p2.Errors()) > 0 || formatted.String() != program.String() || !sameTokens(input, string(out)) {
+		return src, fmt.Errorf("cannot format: the result would change the program")
+	}
+	return out, nil
+}
+
+// sameTokens compares the token streams of two sources, ignoring layout,
+// comments and semicolons.
+func sameTokens(a, b string) bool {
+	la, lb := lexer.New(a), lexer.New(b)
+	next := func(l *lexer.Lexer) lexer.Token {
+		for {
+			tok := l.NextToken()
+			if tok.Type != lexer.COMMENT && tok.Type != lexer.SEMICOLON {
+				return tok
+			}
+		}
+	}
+	for {
+		ta, tb := next(la), next(lb)
+		if ta.Type != tb.Type || ta.Literal != tb.Literal {
+			return false
+		}
+		if ta.Type == lexer.EOF {
+			return true
+		}
+	}
+}
+
+type comment struct {
+	text     string
+	line     int
+	offset   int
+	trailing bool // Follows code on the same line
+}
+
+func collectComments(input string) []comment {
+	comments := []comment{}
+	l := lexer.New(input)
+	for {
+		tok := l.NextToken()
+		if tok.Type == lexer.EOF {
+			break
+		}
+		if tok.Type != lexer.COMMENT {
+			continue
+		}
+		lineStart := strings.LastIndex(input[:tok.Offset], "\n") + 1
+		comments = append(comments, comment{
+			text:     strings.TrimRight(input[tok.Offset:tok.EndOffset], " \t\r"),
+			line:     tok.Line,
+			offset:   tok.Offset,
+			trailing: strings.TrimSpace(input[lineStart:tok.Offset]) != "",
+		})
+	}
+	return comments
+}
+
+type printer struct {
+	src      string
+	buf      bytes.Buffer
+	indent   int
+	comments []comment
+	next     int // Index of the first comment not printed yet
+
+	lastLine     int  // Source line of the last thing printed
+	atBlockStart bool // No blank line before the first line of a block
+}
+
+func (p *printer) write(s string) {
+	p.buf.WriteString(s)
+}
+
+// line starts a new output line for something from source line srcLine,
+// keeping (at most) one blank line where the source had any.
+func (p *printer) line(srcLine int) {
+	if p.buf.Len() > 0 {
+		p.write("\n")
+		if !p.atBlockStart && p.lastLine > 0 && srcLine > p.lastLine+1 {
+			p.write("\n")
+		}
+	}
+	p.write(strings.Repeat(indentUnit, p.indent))
+	p.atBlockStart = false
+	if srcLine > 0 {
+		p.lastLine = srcLine
+	}
+}
+
+// flushComments prints, each on its own line, the comments that start
+// before offset.
+func (p *printer) flushComments(offset int) {
+	for p.next < len(p.comments) && p.comments[p.next].offset < offset {
+		c := p.comments[p.next]
+		p.line(c.line)
+		p.write(c.text)
+		p.next++
+	}
+}
+
+// trailingComment appends a comment that sits at the end of srcLine.
+func (p *printer) trailingComment(srcLine int) {
+	if p.next < len(p.comments) && p.comments[p.next].trailing && p.comments[p.next].line == srcLine {
+		p.write(" " + p.comments[p.next].text)
+		p.next++
+	}
+}
+
+func (p *printer) program(program *parser.Program) {
+	p.statements(program.Statements, len(p.src))
+	if p.buf.Len() > 0 {
+		p.write("\n")
+	}
+}
+
+// statements prints one statement per line, followed by the comments that
+// come before until.
+func (p *printer) statements(stmts []parser.Statement, until int) {
+	for i, stmt := range stmts {
+		span := stmt.Span()
+		p.flushComments(span.Offset)
+		p.line(span.StartLine)
+		p.statement(stmt)
+
+		// Keep a following `(` or `[` from continuing this statement
+		_, closed := stmt.(*parser.StructStatement)
+		if _, ok := stmt.(*parser.EnumStatement); ok {
+			closed = true
+		}
+		if !closed && i+1 < len(stmts) && startsWithBracket(stmts[i+1]) {
+			p.write(";")
+		}
+		if span.EndLine > p.lastLine {
+			p.lastLine = span.EndLine
+		}
+		p.trailingComment(span.EndLine)
+	}
+	p.flushComments(until)
+}
+
+func startsWithBracket(stmt parser.Statement) bool {
+	var expr parser.Expression
+	switch s := stmt.(type) {
+	case *parser.ExpressionStatement:
+		expr = s.Expression
+	case *parser.AssignmentStatement:
+		expr = s.Name
+	case *parser.DestructuringStatement:
+		_, ok := s.Target.(*parser.ArrayPattern)
+		return ok
+	}
+	for expr != nil {
+		if _, ok := expr.(*parser.ArrayLiteral); ok {
+			return true
+		}
+		left := leftOperand(expr)
+		if left == nil {
+			return false
+		}
+		if parenthesize(expr, left, true) {
+			return true
+		}
+		expr = left
+	}
+	return false
+}
+
+// leftOperand returns the sub-expression printed first in e, if e starts
+// with one.
+func leftOperand(e parser.Expression) parser.Expression {
+	switch e := e.(type) {
+	case *parser.InfixExpression:
+		return e.Left
+	case *parser.CallExpression:
+		return e.Function
+	case *parser.IndexExpression:
+		return e.Left
+	case *parser.SliceExpression:
+		return e.Left
+	case *parser.RangeExpression:
+		return e.Start
+	}
+	return nil
+}
+
+// block prints the statements of a block one level deeper. until is the
+// offset of the keyword that ends the block; comments before it stay inside.
+func (p *printer) block(b *parser.BlockStatement, until int) {
+	// A comment at the end of the header line stays there
+	bodyStart := until
+	if b != nil && len(b.Statements) > 0 {
+		bodyStart = b.Statements[0].Span().Offset
+	}
+	if p.next < len(p.comments) && p.comments[p.next].trailing && p.comments[p.next].offset < bodyStart {
+		p.write(" " + p.comments[p.next].text)
+		p.next++
+	}
+
+	p.indent++
+	p.atBlockStart = true
+	if b != nil {
+		p.statements(b.Statements, until)
+	} else {
+		p.flushComments(until)
+	}
+	p.atBlockStart = false
+	p.indent--
+}
+
+// closing prints a block keyword (akhir, lainnya, ...) on its own line.
+func (p *printer) closing(keyword string) {
+	p.line(0)
+	p.write(keyword)
+}
+
+// endOf returns the offset of the `akhir` that ends node, or -1 when the
+// node has no position.
+func endOf(node parser.Node) int {
+	s := node.Span()
+	if s.IsZero() {
+		return -1
+	}
+	return s.Offset + s.Length - len("akhir")
+}
+
+func (p *printer) statement(stmt parser.Statement) {
+	switch s := stmt.(type) {
+	case *parser.ExpressionStatement:
+		p.expr(s.Expression)
+	case *parser.AssignmentStatement:
+		p.expr(s.Name)
+		if s.Type != nil {
+			p.write(": " + s.Type.String())
+		}
+		p.write(" " + s.Operator() + "= ")
+		p.expr(s.Value)
+	case *parser.ReturnStatement:
+		p.write("kembalikan")
+		if s.ReturnValue != nil {
+			p.write(" ")
+			p.expr(s.ReturnValue)
+		}
+	case *parser.DestructuringStatement:
+		p.pattern(s.Target)
+		p.write(" = ")
+		p.expr(s.Value)
+	case *parser.ConstStatement:
+		p.write("tetap " + s.Name.Value + " = ")
+		p.expr(s.Value)
+	case *parser.BreakStatement:
+		p.write("berhenti")
+	case *parser.ContinueStatement:
+		p.write("lanjut")
+	case *parser.ImportStatement:
+		if s.Token.Type == lexer.DARI {
+			p.write("dari " + quote(s.Path) + " ambil " + strings.Join(s.Identifiers, ", "))
+		} else {
+			p.write("ambil " + quote(s.Path))
+		}
+	case *parser.StructStatement:
+		p.write("struktur " + s.Name.Value)
+		p.trailingComment(s.Name.Token.Line)
+		p.indent++
+		p.atBlockStart = true
+		for _, member := range structMembers(s) {
+			span := member.Span()
+			p.flushComments(span.Offset)
+			p.line(span.StartLine)
+			if field, ok := member.(*parser.Identifier); ok {
+				p.write(field.Value)
+				if t := fieldType(s, field); t != nil {
+					p.write(": " + t.String())
+				}
+			} else {
+				p.expr(member.(*parser.FunctionLiteral))
+			}
+			if span.EndLine > p.lastLine {
+				p.lastLine = span.EndLine
+			}
+			p.trailingComment(span.EndLine)
+		}
+		p.flushComments(endOf(s))
+		p.atBlockStart = false
+		p.indent--
+		p.closing("akhir")
+	case *parser.EnumStatement:
+		p.write("pilihan " + s.Name.Value)
+		p.trailingComment(s.Name.Token.Line)
+		p.indent++
+		p.atBlockStart = true
+		for _, v := range s.Variants {
+			span := v.Span()
+			p.flushComments(span.Offset)
+			p.line(span.StartLine)
+			p.write(v.String())
+			p.trailingComment(span.EndLine)
+		}
+		p.flushComments(endOf(s))
+		p.atBlockStart = false
+		p.indent--
+		p.closing("akhir")
+	case *parser.BlockStatement:
+		p.statements(s.Statements, -1)
+	default:
+		p.write(stmt.String())
+	}
+}
+
+func fieldType(s *parser.StructStatement, field *parser.Identifier) *parser.TypeAnnotation {
+	for i, f := range s.Fields {
+		if f == field {
+			return s.FieldType(i)
+		}
+	}
+	return nil
+}
+
+// structMembers returns the fields and methods of s in source order.
+func structMembers(s *parser.StructStatement) []parser.Node {
+	members := make([]parser.Node, 0, len(s.Fields)+len(s.Methods))
+	for _, f := range s.Fields {
+		members = append(members, f)
+	}
+	for _, m := range s.Methods {
+		members = append(members, m)
+	}
+	sort.SliceStable(members, func(i, j int) bool {
+		return members[i].Span().Offset < members[j].Span().Offset
+	})
+	return members
+}
+
+func (p *printer) expr(e parser.Expression) {
+	switch e := e.(type) {
+	case nil:
+	case *parser.Identifier:
+		p.write(e.Value)
+	case *parser.IntegerLiteral, *parser.FloatLiteral, *parser.BooleanLiteral, *parser.NullLiteral:
+		p.write(e.TokenLiteral())
+	case *parser.StringLiteral:
+		if text, ok := p.verbatim(e); ok {
+			p.write(text)
+		} else {
+			p.write(quote(e.Value))
+		}
+	case *parser.InterpolatedString:
+		if text, ok := p.verbatim(e); ok {
+			p.write(text)
+		} else {
+			p.interpolated(e)
+		}
+	case *parser.ArrayLiteral:
+		p.list("[", "]", e, e.Elements, func(el parser.Expression) { p.expr(el) })
+	case *parser.HashLiteral:
+		p.list("{", "}", e, e.Keys(), func(key parser.Expression) {
+			p.expr(key)
+			p.write(": ")
+			p.expr(e.Pairs[key])
+		})
+	case *parser.PrefixExpression:
+		p.write(e.Operator)
+		p.operand(e, e.Right, false)
+	case *parser.TryExpression:
+		p.write("coba ")
+		p.operand(e, e.Value, false)
+	case *parser.InfixExpression:
+		p.operand(e, e.Left, true)
+		p.write(" " + e.Operator + " ")
+		p.operand(e, e.Right, false)
+	case *parser.CallExpression:
+		p.operand(e, e.Function, true)
+		p.write("(")
+		for i, arg := range e.Arguments {
+			if i > 0 {
+				p.write(", ")
+			}
+			p.expr(arg)
+		}
+		p.write(")")
+	case *parser.IndexExpression:
+		p.operand(e, e.Left, true)
+		if lit, ok := e.Index.(*parser.StringLiteral); ok && (e.Token.Type == lexer.DOT || e.Optional()) {
+			p.write(e.Token.Literal + lit.Value)
+			return
+		}
+		p.write("[")
+		p.expr(e.Index)
+		p.write("]")
+	case *parser.SliceExpression:
+		p.operand(e, e.Left, true)
+		p.write("[")
+		if e.Start != nil {
+			p.expr(e.Start)
+		}
+		p.write(":")
+		if e.End != nil {
+			p.expr(e.End)
+		}
+		p.write("]")
+	case *parser.RangeExpression:
+		p.operand(e, e.Start, true)
+		p.write(e.Token.Literal)
+		p.operand(e, e.End, false)
+	case *parser.IfExpression:
+		p.ifExpr(e, endOf(e))
+	case *parser.WhileExpression:
+		p.write("selama ")
+		p.expr(e.Condition)
+		p.block(e.Body, endOf(e))
+		p.closing("akhir")
+	case *parser.ForInExpression:
+		p.write("untuk ")
+		if e.Key != nil {
+			p.write(e.Key.Value + ", ")
+		}
+		p.write(e.Value.Value + " dalam ")
+		p.expr(e.Iterable)
+		p.block(e.Body, endOf(e))
+		p.closing("akhir")
+	case *parser.NamedArgument:
+		p.write(e.Name.Value + ": ")
+		p.expr(e.Value)
+	case *parser.MatchExpression:
+		p.match(e)
+	case *parser.FunctionLiteral:
+		p.write("fungsi")
+		if e.Name != "" {
+			p.write(" " + e.Name)
+		}
+		p.write("(")
+		for i, param := range e.Parameters {
+			if i > 0 {
+				p.write(", ")
+			}
+			if e.Variadic && i == len(e.Parameters)-1 {
+				p.write("...")
+			}
+			p.write(param.Value)
+			if t := e.ParamType(i); t != nil {
+				p.write(": " + t.String())
+			}
+			if def := e.Default(i); def != nil {
+				p.write(" = ")
+				p.expr(def)
+			}
+		}
+		p.write(")")
+		if e.ReturnType != nil {
+			p.write(" -> " + e.ReturnType.String())
+		}
+		p.block(e.Body, endOf(e))
+		p.closing("akhir")
+	default:
+		p.write(e.String())
+	}
+}
+
+// operand prints a sub-expression of parent, in parentheses if needed.
+func (p *printer) operand(parent, child parser.Expression, leftmost bool) {
+	if parenthesize(parent, child, leftmost) {
+		p.write("(")
+		p.expr(child)
+		p.write(")")
+		return
+	}
+	p.expr(child)
+}
+
+// parenthesize reports whether child needs parentheses inside parent: when
+// the grammar needs them, or when the source had them. Source parentheses
+// show up as a gap between the child and that edge of the parent's span.
+func parenthesize(parent, child parser.Expression, leftmost bool) bool {
+	ps, cs := parent.Span(), child.Span()
+	if !ps.IsZero() && !cs.IsZero() {
+		if leftmost && cs.Offset > ps.Offset {
+			return true
+		}
+		if !leftmost && cs.Offset+cs.Length < ps.Offset+ps.Length {
+			return true
+		}
+	}
+
+	inner := precedenceOf(child)
+	switch parent := parent.(type) {
+	case *parser.InfixExpression:
+		outer := parser.Precedence(parent.Token.Type)
+		if !leftmost && inner == parser.PREFIX {
+			return false // A prefix operator binds its own operand
+		}
+		// `**` groups to the right, everything else to the left
+		sameLevel := leftmost == (parent.Token.Type == lexer.POWER)
+		return inner < outer || (sameLevel && inner == outer)
+	case *parser.RangeExpression:
+		return inner < parser.RANGE || (!leftmost && inner == parser.RANGE)
+	case *parser.PrefixExpression, *parser.TryExpression:
+		return inner < parser.PREFIX
+	case *parser.CallExpression, *parser.IndexExpression, *parser.SliceExpression:
+		return inner <= parser.PREFIX
+	}
+	return false
+}
+
+func precedenceOf(e parser.Expression) int {
+	switch e := e.(type) {
+	case *parser.InfixExpression:
+		return parser.Precedence(e.Token.Type)
+	case *parser.RangeExpression:
+		return parser.RANGE
+	case *parser.PrefixExpression, *parser.TryExpression:
+		return parser.PREFIX
+	}
+	return parser.INDEX + 1
+}
+
+// list prints an array or hash literal. Literals that spanned several lines
+// in the source get one element per line; others stay on one line.
+func (p *printer) list(open, close string, node parser.Expression, items []parser.Expression, item func(parser.Expression)) {
+	span := node.Span()
+	multiline := !span.IsZero() && span.StartLine != span.EndLine && len(items) > 0
+
+	p.write(open)
+	if !multiline {
+		for i, it := range items {
+			if i > 0 {
+				p.write(", ")
+			}
+			item(it)
+		}
+		p.write(close)
+		return
+	}
+
+	p.indent++
+	p.atBlockStart = true
+	for i, it := range items {
+		p.flushComments(it.Span().Offset)
+		p.line(it.Span().StartLine)
+		item(it)
+		if i < len(items)-1 {
+			p.write(",")
+		}
+		// A comment after several elements on one line follows the last one
+		if i == len(items)-1 || items[i+1].Span().StartLine > p.lastLine {
+			p.trailingComment(p.lastLine)
+		}
+	}
+	p.flushComments(span.Offset + span.Length)
+	p.atBlockStart = false
+	p.indent--
+	p.line(0)
+	p.write(close)
+}
+
+// verbatim returns the source of a raw or triple-quoted string, which keeps
+// its own layout rather than being rewritten as "...".
+func (p *printer) verbatim(e parser.Expression) (string, bool) {
+	span := e.Span()
+	if span.IsZero() || span.Offset+span.Length > len(p.src) {
+		return "", false
+	}
+	text := p.src[span.Offset : span.Offset+span.Length]
+	if strings.HasPrefix(text, `"""`) || strings.HasPrefix(text, `r"`) {
+		return text, true
+	}
+	return "", false
+}
+
+func (p *printer) interpolated(is *parser.InterpolatedString) {
+	p.write("\"")
+	for i, part := range is.Parts {
+		lit, ok := part.(*parser.StringLiteral)
+		if !ok {
+			p.write("#{")
+			p.expr(part)
+			p.write("}")
+			continue
+		}
+		// Adjacent literals ("a" "b") stay separate strings
+		if i > 0 {
+			if _, prevLit := is.Parts[i-1].(*parser.StringLiteral); prevLit {
+				p.write("\" \"")
+			}
+		}
+		p.write(escape(lit.Value))
+	}
+	p.write("\"")
+}
+
+func (p *printer) ifExpr(e *parser.IfExpression, end int) {
+	p.write(e.Token.Literal + " ")
+	p.expr(e.Condition)
+
+	alt := e.Alternative
+	elseIf := elseIfOf(alt)
+	consequenceEnd := end
+	if alt != nil && !alt.Span().IsZero() {
+		consequenceEnd = -1 // Comments before the next branch go into it
+	}
+	p.block(e.Consequence, consequenceEnd)
+
+	switch {
+	case elseIf != nil:
+		p.line(0)
+		p.ifExpr(elseIf, end)
+		return
+	case alt != nil:
+		p.closing("lainnya")
+		p.block(alt, end)
+	}
+	p.closing("akhir")
+}
+
+// elseIfOf returns the `atau_jika` branch an alternative block stands for.
+func elseIfOf(alt *parser.BlockStatement) *parser.IfExpression {
+	if alt == nil || len(alt.Statements) != 1 {
+		return nil
+	}
+	stmt, ok := alt.Statements[0].(*parser.ExpressionStatement)
+	if !ok {
+		return nil
+	}
+	child, ok := stmt.Expression.(*parser.IfExpression)
+	if !ok || child.Token.Type != lexer.ATAU_JIKA {
+		return nil
+	}
+	return child
+}
+
+func (p *printer) match(m *parser.MatchExpression) {
+	p.write("cocokkan ")
+	p.expr(m.Subject)
+
+	p.indent++
+	p.atBlockStart = true
+	for i, arm := range m.Arms {
+		p.flushComments(arm.Span().Offset)
+		p.line(arm.Token.Line)
+		p.write("kasus ")
+		p.pattern(arm.Pattern)
+		if arm.Guard != nil {
+			p.write(" jika ")
+			p.expr(arm.Guard)
+		}
+
+		until := -1
+		if i == len(m.Arms)-1 && m.Alternative == nil {
+			until = endOf(m)
+		}
+		p.block(arm.Body, until)
+	}
+	if m.Alternative != nil {
+		p.line(0)
+		p.write("lainnya")
+		p.block(m.Alternative, endOf(m))
+	}
+	p.atBlockStart = false
+	p.indent--
+	p.closing("akhir")
+}
+
+func (p *printer) pattern(pat parser.Pattern) {
+	switch pat := pat.(type) {
+	case *parser.LiteralPattern:
+		p.expr(pat.Value)
+	case *parser.BindingPattern:
+		p.write(pat.Name.Value)
+	case *parser.ArrayPattern:
+		p.write("[")
+		for i, el := range pat.Elements {
+			if i > 0 {
+				p.write(", ")
+			}
+			p.pattern(el)
+		}
+		if pat.HasRest {
+			if len(pat.Elements) > 0 {
+				p.write(", ")
+			}
+			p.write("...")
+			if pat.Rest != nil {
+				p.write(pat.Rest.Value)
+			}
+		}
+		p.write("]")
+	case *parser.HashPattern:
+		if pat.Schema != nil {
+			p.write(pat.Schema.Value)
+		}
+		p.write("{")
+		for i, key := range pat.Keys {
+			if i > 0 {
+				p.write(", ")
+			}
+			name := ""
+			switch k := key.(type) {
+			case *parser.StringLiteral:
+				name = k.Value
+				if k.Token.Type == lexer.STRING {
+					name = quote(k.Value)
+				}
+			default:
+				name = key.String()
+			}
+			// `{nama}` is short for `{nama: nama}`
+			if b, ok := pat.Values[i].(*parser.BindingPattern); ok && b.Name.Value == name {
+				p.write(name)
+				continue
+			}
+			p.write(name + ": ")
+			p.pattern(pat.Values[i])
+		}
+		p.write("}")
+	case *parser.ErrorPattern:
+		p.write("galat")
+		if pat.Message != nil {
+			p.write("(")
+			p.pattern(pat.Message)
+			if pat.Code != nil {
+				p.write(", ")
+				p.pattern(pat.Code)
+			}
+			p.write(")")
+		}
+	default:
+		p.write(pat.String())
+	}
+}
+
+func quote(s string) string {
+	return "\"" + escape(s) + "\""
+}
+
+func escape(s string) string {
+	var out strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\':
+			out.WriteString(`\\`)
+		case c == '"':
+			out.WriteString(`\"`)
+		case c == '\n':
+			out.WriteString(`\n`)
+		case c == '\t':
+			out.WriteString(`\t`)
+		case c == '\r':
+			out.WriteString(`\r`)
+		case c == 0:
+			out.WriteString(`\0`)
+		case c == '#' && strings.HasPrefix(s[i:], "#{"):
+			out.WriteString(`\#`)
+		case c < 0x20 || c == 0x7f:
+			fmt.Fprintf(&out, `\x%02X`, c)
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,161 @@
+package format
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSource(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			"operator spacing",
+			"a = 10+5\nb=a*2\n",
+			"a = 10 + 5\nb = a * 2\n",
+		},
+		{
+			"call and hash layout",
+			"cetak( \"x\" )\ndata = {nama: \"Budi\",   umur:20}\n",
+			"cetak(\"x\")\ndata = {nama: \"Budi\", umur: 20}\n",
+		},
+		{
+			"multi-line array keeps comments",
+			"daftar = [\n1,   # satu\n  2,\n 3\n]\n",
+			"daftar = [\n  1, # satu\n  2,\n  3\n]\n",
+		},
+		{
+			"indentation and blank lines",
+			"# Hitung\nfungsi f(a,b)   # header\n    x = a\n\n\n\n\tkembalikan x\nakhir\n",
+			"# Hitung\nfungsi f(a, b) # header\n  x = a\n\n  kembalikan x\nakhir\n",
+		},
+		{
+			"else-if chain",
+			"jika x > 10 cetak(\"besar\")\natau_jika x > 5\ncetak(\"sedang\")\nlainnya\ncetak(\"kecil\")\nakhir\n",
+			"jika x > 10\n  cetak(\"besar\")\natau_jika x > 5\n  cetak(\"sedang\")\nlainnya\n  cetak(\"kecil\")\nakhir\n",
+		},
+		{
+			"match arms",
+			"cocokkan d kasus [h, ...t] jika h > 0 h kasus {nama} nama lainnya kosong akhir\n",
+			"cocokkan d\n  kasus [h, ...t] jika h > 0\n    h\n  kasus {nama}\n    nama\n  lainnya\n    kosong\nakhir\n",
+		},
+		{
+			"parentheses",
+			"z = a - (b - c)\nw = (a - b) - c\nv = -(x ** 2) ** 3\n",
+			"z = a - (b - c)\nw = (a - b) - c\nv = -(x ** 2) ** 3\n",
+		},
+		{
+			"struct fields",
+			"struktur Titik\n    x  # koordinat\n y\nakhir\n",
+			"struktur Titik\n  x # koordinat\n  y\nakhir\n",
+		},
+		{
+			"destructuring",
+			"x = 1;\n[a,b] = p\nstruktur O\n  n\nakhir\n[c] = q\n{n,  m: k} = o\n",
+			"x = 1;\n[a, b] = p\nstruktur O\n  n\nakhir\n[c] = q\n{n, m: k} = o\n",
+		},
+		{
+			"type annotations",
+			"fungsi f(a:integer,b: integer|galat = 1)->  integer\nkembalikan a\nakhir\nx :string = \"a\"\nstruktur T\n  n:  integer\nakhir\n",
+			"fungsi f(a: integer, b: integer | galat = 1) -> integer\n  kembalikan a\nakhir\nx: string = \"a\"\nstruktur T\n  n: integer\nakhir\n",
+		},
+		{
+			"pilihan",
+			"pilihan Status\nMenunggu # awal\n  Selesai(kode,pesan)\nakhir\n[a] = b\n",
+			"pilihan Status\n  Menunggu # awal\n  Selesai(kode, pesan)\nakhir\n[a] = b\n",
+		},
+		{
+			"string escapes",
+			"cetak(\"a \\\"b\\\" #{c}\")\n",
+			"cetak(\"a \\\"b\\\" #{c}\")\n",
+		},
+		{
+			"raw and triple-quoted strings",
+			"jika benar\nx = r\"a\\d\"\ny = \"\"\"\n    #{x}\n      b\n    \"\"\"\nakhir\nz = \"\\x01\\0\\u{e9}\"\n",
+			"jika benar\n  x = r\"a\\d\"\n  y = \"\"\"\n    #{x}\n      b\n    \"\"\"\nakhir\nz = \"\\x01\\0é\"\n",
+		},
+		{
+			"numeric literals",
+			"x = 0xFF&0b1010\ny = [1_000,6.02e23]\n",
+			"x = 0xFF & 0b1010\ny = [1_000, 6.02e23]\n",
+		},
+		{
+			"slices and ranges",
+			"x = a[1 :3]\ny = t[:-1]\nuntuk i dalam 0..<n + 1\ncetak(i)\nakhir\nz = (1..5)[0]\n",
+			"x = a[1:3]\ny = t[:-1]\nuntuk i dalam 0..<n + 1\n  cetak(i)\nakhir\nz = (1..5)[0]\n",
+		},
+		{
+			"null-safe access",
+			"port = cfg?.db?.port  ??   5432\nx = f()  ?!  kosong\n",
+			"port = cfg?.db?.port ?? 5432\nx = f() ?! kosong\n",
+		},
+		{
+			"trailing comment after several elements",
+			"x = [\n  1, 2, # k\n  3\n]\n",
+			"x = [\n  1,\n  2, # k\n  3\n]\n",
+		},
+		{
+			"missing space in a nested condition",
+			"fungsi f(a)\n  jika a>1\n    kembalikan a\n  akhir\n  kembalikan 0\nakhir\n",
+			"fungsi f(a)\n  jika a > 1\n    kembalikan a\n  akhir\n  kembalikan 0\nakhir\n",
+		},
+		{
+			"dangling comment",
+			"x = 1\n  # akhir file\n",
+			"x = 1\n# akhir file\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Source([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("Source returned error: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("wrong output.\ngot:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSourceIdempotent(t *testing.T) {
+	files, err := filepath.Glob("../../test/fixtures/valid/*.fox")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no fixtures found: %v", err)
+	}
+
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		once, err := Source(src)
+		if err != nil {
+			t.Errorf("%s: %v", file, err)
+			continue
+		}
+		twice, err := Source(once)
+		if err != nil {
+			t.Errorf("%s: reformat failed: %v", file, err)
+			continue
+		}
+		if string(once) != string(twice) {
+			t.Errorf("%s: not idempotent.\nfirst:\n%s\nsecond:\n%s", file, once, twice)
+		}
+	}
+}
+
+func TestSourceSyntaxError(t *testing.T) {
+	src := []byte("fungsi f(a\n  kembalikan a\nakhir\n")
+	out, err := Source(src)
+	if err == nil {
+		t.Fatalf("expected syntax error, got:\n%s", out)
+	}
+	if string(out) != string(src) {
+		t.Errorf("source should be returned unchanged on error")
+	}
+}
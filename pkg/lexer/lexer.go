@@ -1,15 +1,32 @@
 package lexer
 
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
 type Lexer struct {
 	input        string
 	position     int
 	readPosition int
-	ch           byte
+	ch           rune // Current code point, decoded from UTF-8
 	line         int
-	column       int
+	column       int // Counted in runes, not bytes
 
 	states      []int
 	braceCounts []int
+	strs        []stringFrame // One per STATE_STRING on the state stack
+	lineStarts  []int         // Byte offset of the first character of each line
+
+	errors []Error
+}
+
+// Error is a malformed literal found while scanning, such as an unknown
+// escape sequence. The parser reports it at Token's position.
+type Error struct {
+	Token   Token // ILLEGAL token covering the offending text
+	Message string
 }
 
 const (
@@ -24,11 +41,29 @@ func New(input string) *Lexer {
 		column:      0,
 		states:      []int{STATE_CODE},
 		braceCounts: []int{0},
+		lineStarts:  []int{0},
 	}
 	l.readChar()
 	return l
 }
 
+// Clone returns an independent copy of the lexer at its current position,
+// so a parser can look ahead without consuming tokens.
+func (l *Lexer) Clone() *Lexer {
+	c := *l
+	c.states = append([]int(nil), l.states...)
+	c.braceCounts = append([]int(nil), l.braceCounts...)
+	c.strs = append([]stringFrame(nil), l.strs...)
+	c.lineStarts = append([]int(nil), l.lineStarts...)
+	c.errors = append([]Error(nil), l.errors...)
+	return &c
+}
+
+// Errors returns the malformed literals found so far, in source order.
+func (l *Lexer) Errors() []Error {
+	return l.errors
+}
+
 func (l *Lexer) currentState() int {
 	if len(l.states) == 0 {
 		return STATE_CODE
@@ -54,6 +89,9 @@ func (l *Lexer) popState() {
 			l.braceCounts = l.braceCounts[:len(l.braceCounts)-1]
 		}
 	}
+	if s == STATE_STRING && len(l.strs) > 0 {
+		l.strs = l.strs[:len(l.strs)-1]
+	}
 }
 
 func (l *Lexer) currentBraceCount() int {
@@ -76,30 +114,57 @@ func (l *Lexer) decrementBraceCount() {
 }
 
 func (l *Lexer) readChar() {
+	size := 1
 	if l.readPosition >= len(l.input) {
 		l.ch = 0
 	} else {
-		l.ch = l.input[l.readPosition]
+		l.ch, size = utf8.DecodeRuneInString(l.input[l.readPosition:])
 	}
 	l.position = l.readPosition
-	l.readPosition += 1
+	l.readPosition += size
 	l.column += 1
 }
 
-func (l *Lexer) peekChar() byte {
+func (l *Lexer) peekChar() rune {
 	if l.readPosition >= len(l.input) {
 		return 0
 	}
-	return l.input[l.readPosition]
+	r, _ := utf8.DecodeRuneInString(l.input[l.readPosition:])
+	return r
 }
 
 func (l *Lexer) NextToken() Token {
+	var tok Token
 	if l.currentState() == STATE_STRING {
 		// Continuation of string (e.g. after interpolation) usually has no leading space
 		// relative to the code stream, as it is inside quotes.
-		return l.readStringToken(false)
+		tok = l.readStringToken(false)
+	} else {
+		tok = l.readCodeToken()
+		if tok.Type == RBRACE && l.currentState() == STATE_STRING && l.atStringEnd() {
+			// A string ending in an interpolation closes with this token
+			l.closeString()
+		}
 	}
-	return l.readCodeToken()
+
+	tok.Offset = l.offsetOf(tok.Line, tok.Column)
+	tok.EndLine = l.line
+	tok.EndColumn = l.column
+	tok.EndOffset = l.position
+	return tok
+}
+
+// offsetOf converts a 1-based line and rune column into a byte offset.
+func (l *Lexer) offsetOf(line, column int) int {
+	if line < 1 || line > len(l.lineStarts) {
+		return 0
+	}
+	offset := l.lineStarts[line-1]
+	for i := 1; i < column && offset < len(l.input); i++ {
+		_, size := utf8.DecodeRuneInString(l.input[offset:])
+		offset += size
+	}
+	return offset
 }
 
 func (l *Lexer) readCodeToken() Token {
@@ -123,9 +188,22 @@ func (l *Lexer) readCodeToken() Token {
 			tok = newToken(ASSIGN, l.ch)
 		}
 	case '+':
-		tok = newToken(PLUS, l.ch)
+		if l.peekChar() == '=' {
+			l.readChar()
+			tok = Token{Type: PLUS_ASSIGN, Literal: "+="}
+		} else {
+			tok = newToken(PLUS, l.ch)
+		}
 	case '-':
-		tok = newToken(MINUS, l.ch)
+		if l.peekChar() == '=' {
+			l.readChar()
+			tok = Token{Type: MINUS_ASSIGN, Literal: "-="}
+		} else if l.peekChar() == '>' {
+			l.readChar()
+			tok = Token{Type: ARROW, Literal: "->"}
+		} else {
+			tok = newToken(MINUS, l.ch)
+		}
 	case '!':
 		if l.peekChar() == '=' {
 			ch := l.ch
@@ -136,9 +214,45 @@ func (l *Lexer) readCodeToken() Token {
 			tok = newToken(BANG, l.ch)
 		}
 	case '/':
-		tok = newToken(SLASH, l.ch)
+		if l.peekChar() == '/' {
+			ch := l.ch
+			l.readChar()
+			literal := string(ch) + string(l.ch)
+			tok = Token{Type: FLOORDIV, Literal: literal}
+		} else if l.peekChar() == '=' {
+			l.readChar()
+			tok = Token{Type: SLASH_ASSIGN, Literal: "/="}
+		} else {
+			tok = newToken(SLASH, l.ch)
+		}
 	case '*':
-		tok = newToken(ASTERISK, l.ch)
+		if l.peekChar() == '*' {
+			ch := l.ch
+			l.readChar()
+			literal := string(ch) + string(l.ch)
+			tok = Token{Type: POWER, Literal: literal}
+		} else if l.peekChar() == '=' {
+			l.readChar()
+			tok = Token{Type: ASTERISK_ASSIGN, Literal: "*="}
+		} else {
+			tok = newToken(ASTERISK, l.ch)
+		}
+	case '?':
+		switch l.peekChar() {
+		case '?':
+			l.readChar()
+			tok = Token{Type: NULL_COALESCE, Literal: "??"}
+		case '!':
+			l.readChar()
+			tok = Token{Type: ERROR_COALESCE, Literal: "?!"}
+		case '.':
+			l.readChar()
+			tok = Token{Type: QDOT, Literal: "?."}
+		default:
+			tok = newToken(ILLEGAL, l.ch)
+		}
+	case '%':
+		tok = newToken(PERCENT, l.ch)
 	case '&':
 		tok = newToken(AND, l.ch)
 	case '|':
@@ -182,7 +296,21 @@ func (l *Lexer) readCodeToken() Token {
 	case ',':
 		tok = newToken(COMMA, l.ch)
 	case '.':
-		tok = newToken(DOT, l.ch)
+		if l.peekChar() == '.' && l.readPosition+1 < len(l.input) && l.input[l.readPosition+1] == '.' {
+			l.readChar()
+			l.readChar()
+			tok = Token{Type: ELLIPSIS, Literal: "..."}
+		} else if l.peekChar() == '.' {
+			l.readChar()
+			if l.peekChar() == '<' {
+				l.readChar()
+				tok = Token{Type: DOTDOT_LT, Literal: "..<"}
+			} else {
+				tok = Token{Type: DOTDOT, Literal: ".."}
+			}
+		} else {
+			tok = newToken(DOT, l.ch)
+		}
 	case '(':
 		tok = newToken(LPAREN, l.ch)
 	case ')':
@@ -206,42 +334,15 @@ func (l *Lexer) readCodeToken() Token {
 		tok = Token{Type: COMMENT, Literal: l.readComment(), Line: tokLine, Column: tokCol, HasLeadingSpace: hasLeadingSpace}
 		return tok
 	case '"':
-		// Optimization for empty string
-		if l.peekChar() == '"' {
-			tok = Token{Type: STRING, Literal: "", Line: tokLine, Column: tokCol, HasLeadingSpace: hasLeadingSpace}
-			l.readChar() // consume opening "
-			// consume closing " happens at end of function if logic flowed there, but here we return early?
-			// Wait, previous logic was: l.readChar(); return ...
-			// But readStringToken expects to read content.
-			// Let's keep logic simple: push state, delegate to readStringToken.
-			// Optimizations might be tricky with HasLeadingSpace.
-			// Let's remove optimization for clarity/safety or fix it.
-			// If empty string: ""
-			l.readChar() // eat opening "
-			// check closing
-			if l.ch == '"' {
-				tok = Token{Type: STRING, Literal: "", Line: tokLine, Column: tokCol, HasLeadingSpace: hasLeadingSpace}
-				l.readChar() // eat closing "
-				return tok
-			}
-			// Not empty immediately (or logic above was just optimization).
-			// Let's stick to standard path.
-			// Rewind? No.
-			// Just use readStringToken logic.
-			l.pushState(STATE_STRING)
-			// l.readChar() was done (consumed opening ").
-			// But readStringToken expects to start reading content.
-			return l.readStringToken(hasLeadingSpace)
-		} else {
-			l.pushState(STATE_STRING)
-			l.readChar() // consume opening "
-			return l.readStringToken(hasLeadingSpace)
-		}
+		return l.readString(tokLine, tokCol, hasLeadingSpace, false)
 	case 0:
 		tok.Literal = ""
 		tok.Type = EOF
 	default:
-		if isLetter(l.ch) {
+		if l.ch == 'r' && l.peekChar() == '"' {
+			l.readChar() // consume r
+			return l.readString(tokLine, tokCol, hasLeadingSpace, true)
+		} else if isLetter(l.ch) {
 			tok.Literal = l.readIdentifier()
 			tok.Type = LookupIdent(tok.Literal)
 			tok.Line = tokLine
@@ -267,114 +368,114 @@ func (l *Lexer) readCodeToken() Token {
 	return tok
 }
 
-func (l *Lexer) readStringToken(hasLeadingSpace bool) Token {
-	tokLine := l.line
-	tokCol := l.column
-
-	if l.ch == '"' {
-		l.popState()
-		l.readChar() // consume closing quote
-		return l.NextToken()
-	}
-
-	if l.ch == '#' && l.peekChar() == '{' {
-		l.pushState(STATE_CODE)
-		// Interpolation start. Does it have leading space? No, inside string.
-		tok := Token{Type: INTERP_START, Literal: "#{", Line: tokLine, Column: tokCol, HasLeadingSpace: false}
-		l.readChar() // consume #
-		l.readChar() // consume {
-		return tok
-	}
-
-	content := l.readStringContent()
-	return Token{
-		Type:            STRING,
-		Literal:         content,
-		Line:            tokLine,
-		Column:          tokCol,
-		HasLeadingSpace: hasLeadingSpace,
-	}
-}
-
-func newToken(tokenType TokenType, ch byte) Token {
+func newToken(tokenType TokenType, ch rune) Token {
 	return Token{Type: tokenType, Literal: string(ch)}
 }
 
 func (l *Lexer) readIdentifier() string {
 	position := l.position
-	for isLetter(l.ch) || l.ch == '_' || isDigit(l.ch) {
+	for isLetter(l.ch) || isDigit(l.ch) || unicode.IsMark(l.ch) {
 		l.readChar()
 	}
 	return l.input[position:l.position]
 }
 
-func isLetter(ch byte) bool {
-	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_'
+// isLetter accepts any Unicode letter, so identifiers such as `jumlah_café`
+// or `ñame` lex as one token.
+func isLetter(ch rune) bool {
+	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_' ||
+		ch >= utf8.RuneSelf && unicode.IsLetter(ch)
 }
 
+// readNumber reads a decimal literal with optional fraction and exponent
+// (`1_000`, `3.14`, `6.02e23`) or a prefixed integer (`0xFF`, `0b1010`,
+// `0o755`). Digits are checked against the base by the parser.
 func (l *Lexer) readNumber() (string, TokenType) {
 	position := l.position
-	for isDigit(l.ch) {
+	line, col := l.line, l.column
+
+	if l.ch == '0' && strings.ContainsRune("xXbBoO", l.peekChar()) {
+		l.readChar()
 		l.readChar()
+		for isHexDigit(l.ch) || l.ch == '_' {
+			l.readChar()
+		}
+		return l.input[position:l.position], INT
 	}
 
+	tokType := TokenType(INT)
+	l.readDigits()
+
 	if l.ch == '.' && isDigit(l.peekChar()) {
 		l.readChar() // consume dot
-		for isDigit(l.ch) {
+		l.readDigits()
+		tokType = FLOAT
+	}
+
+	if (l.ch == 'e' || l.ch == 'E') && l.exponentFollows() {
+		l.readChar() // consume e
+		if l.ch == '+' || l.ch == '-' {
 			l.readChar()
 		}
-		return l.input[position:l.position], FLOAT
+		l.readDigits()
+		tokType = FLOAT
 	}
 
-	return l.input[position:l.position], INT
-}
-
-func isDigit(ch byte) bool {
-	return '0' <= ch && ch <= '9'
+	literal := l.input[position:l.position]
+	if !separatorsValid(literal) {
+		l.addError(line, col, position, "`_` in a number must separate two digits")
+	}
+	return literal, tokType
 }
 
-func (l *Lexer) readStringContent() string {
-	var out string
-	for {
-		if l.ch == '"' || l.ch == 0 {
-			break
-		}
-		if l.ch == '#' && l.peekChar() == '{' {
-			break
+// separatorsValid reports whether every `_` in a decimal literal sits between
+// two digits, which rules out `1_`, `1__0`, `1_.5` and `1_e3`.
+func separatorsValid(literal string) bool {
+	for i := 0; i < len(literal); i++ {
+		if literal[i] == '_' && (i == 0 || i == len(literal)-1 || !isDigit(rune(literal[i-1])) || !isDigit(rune(literal[i+1]))) {
+			return false
 		}
+	}
+	return true
+}
 
-		if l.ch == '\\' {
-			l.readChar()
-			switch l.ch {
-			case 'n':
-				out += "\n"
-			case 't':
-				out += "\t"
-			case '"':
-				out += "\""
-			case '\\':
-				out += "\\"
-			case 'r':
-				out += "\r"
-			default:
-				out += string(l.ch)
-			}
-		} else {
-			out += string(l.ch)
-		}
+// readDigits reads decimal digits and `_` separators. A misplaced `_` is kept
+// in the literal, so that readNumber reports it instead of starting an
+// identifier.
+func (l *Lexer) readDigits() {
+	for isDigit(l.ch) || l.ch == '_' {
 		l.readChar()
 	}
-	return out
+}
+
+// exponentFollows reports whether the `e` at the current position starts an
+// exponent (`e9`, `e+9`, `e-9`) rather than an identifier.
+func (l *Lexer) exponentFollows() bool {
+	rest := l.input[l.readPosition:]
+	if len(rest) > 0 && (rest[0] == '+' || rest[0] == '-') {
+		rest = rest[1:]
+	}
+	return len(rest) > 0 && '0' <= rest[0] && rest[0] <= '9'
+}
+
+func isDigit(ch rune) bool {
+	return '0' <= ch && ch <= '9'
 }
 
 func (l *Lexer) skipWhitespace() {
 	for l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r' {
-		if l.ch == '\n' {
-			l.line += 1
-			l.column = 0
-		}
-		l.readChar()
+		l.advance()
+	}
+}
+
+// advance reads the next character, starting a new line after a newline.
+func (l *Lexer) advance() {
+	if l.ch == '\n' {
+		l.line += 1
+		l.column = 0
+		l.lineStarts = append(l.lineStarts, l.readPosition)
 	}
+	l.readChar()
 }
 
 func (l *Lexer) readComment() string {
@@ -112,3 +112,68 @@ a+b
 		}
 	}
 }
+
+func TestRangeTokens(t *testing.T) {
+	input := `1..10 0..<n a.b f(...xs) 1.5..2`
+
+	tests := []struct {
+		expectedType    TokenType
+		expectedLiteral string
+	}{
+		{INT, "1"},
+		{DOTDOT, ".."},
+		{INT, "10"},
+		{INT, "0"},
+		{DOTDOT_LT, "..<"},
+		{IDENT, "n"},
+		{IDENT, "a"},
+		{DOT, "."},
+		{IDENT, "b"},
+		{IDENT, "f"},
+		{LPAREN, "("},
+		{ELLIPSIS, "..."},
+		{IDENT, "xs"},
+		{RPAREN, ")"},
+		{FLOAT, "1.5"},
+		{DOTDOT, ".."},
+		{INT, "2"},
+		{EOF, ""},
+	}
+
+	l := New(input)
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - expected %q %q, got %q %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestNullSafeTokens(t *testing.T) {
+	input := `a?.b ?? c ?! d ?`
+
+	tests := []struct {
+		expectedType    TokenType
+		expectedLiteral string
+	}{
+		{IDENT, "a"},
+		{QDOT, "?."},
+		{IDENT, "b"},
+		{NULL_COALESCE, "??"},
+		{IDENT, "c"},
+		{ERROR_COALESCE, "?!"},
+		{IDENT, "d"},
+		{ILLEGAL, "?"},
+		{EOF, ""},
+	}
+
+	l := New(input)
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - expected %q %q, got %q %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
@@ -47,6 +47,36 @@ func TestFloatTokens(t *testing.T) {
 	}
 }
 
+func TestNumberTokens(t *testing.T) {
+	input := `0xFF 0b1010 0o755 1_000_000 6.02e23 1E-9 2e 3_ 1.5e+2.x`
+	tests := []struct {
+		expectedType    TokenType
+		expectedLiteral string
+	}{
+		{INT, "0xFF"},
+		{INT, "0b1010"},
+		{INT, "0o755"},
+		{INT, "1_000_000"},
+		{FLOAT, "6.02e23"},
+		{FLOAT, "1E-9"},
+		{INT, "2"},
+		{IDENT, "e"},
+		{INT, "3_"}, // Reported as an error, see TestNumberSeparators
+		{FLOAT, "1.5e+2"},
+		{DOT, "."},
+		{IDENT, "x"},
+		{EOF, ""},
+	}
+
+	l := New(input)
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - expected %s %q, got %s %q", i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
+
 func TestEmptyString(t *testing.T) {
 	input := `""`
 	l := New(input)
@@ -103,6 +103,7 @@ func TestNextTokenExtended(t *testing.T) {
 10 != 9
 10 <= 20
 20 >= 10
+x += 1 -= 2 *= 3 /= 4 // 5
 "hello"
 "hello\nworld"
 "foo\"bar"
@@ -123,6 +124,17 @@ func TestNextTokenExtended(t *testing.T) {
 		{INT, "20"},
 		{GTE, ">="},
 		{INT, "10"},
+		{IDENT, "x"},
+		{PLUS_ASSIGN, "+="},
+		{INT, "1"},
+		{MINUS_ASSIGN, "-="},
+		{INT, "2"},
+		{ASTERISK_ASSIGN, "*="},
+		{INT, "3"},
+		{SLASH_ASSIGN, "/="},
+		{INT, "4"},
+		{FLOORDIV, "//"},
+		{INT, "5"},
 		{STRING, "hello"},
 		{STRING, "hello\nworld"},
 		{STRING, "foo\"bar"},
@@ -145,3 +157,100 @@ func TestNextTokenExtended(t *testing.T) {
 		}
 	}
 }
+
+func TestTokenSpan(t *testing.T) {
+	input := "x = \"ab\"\n  yz"
+
+	tests := []struct {
+		expectedLiteral   string
+		expectedOffset    int
+		expectedEndLine   int
+		expectedEndColumn int
+		expectedEndOffset int
+	}{
+		{"x", 0, 1, 2, 1},
+		{"=", 2, 1, 4, 3},
+		{"ab", 4, 1, 9, 8}, // Quotes included
+		{"yz", 11, 2, 5, 13},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+		if tok.Offset != tt.expectedOffset || tok.EndLine != tt.expectedEndLine ||
+			tok.EndColumn != tt.expectedEndColumn || tok.EndOffset != tt.expectedEndOffset {
+			t.Fatalf("tests[%d] span wrong. expected=%d..%d:%d/%d, got=%d..%d:%d/%d", i,
+				tt.expectedOffset, tt.expectedEndLine, tt.expectedEndColumn, tt.expectedEndOffset,
+				tok.Offset, tok.EndLine, tok.EndColumn, tok.EndOffset)
+		}
+	}
+}
+
+func TestUnicodeIdentifiers(t *testing.T) {
+	input := `jumlah_café = "héllo" + ñame
+π`
+
+	tests := []struct {
+		expectedType    TokenType
+		expectedLiteral string
+		expectedLine    int
+		expectedColumn  int
+		expectedOffset  int
+	}{
+		{IDENT, "jumlah_café", 1, 1, 0},
+		{ASSIGN, "=", 1, 13, 13},
+		{STRING, "héllo", 1, 15, 15},
+		{PLUS, "+", 1, 23, 24},
+		{IDENT, "ñame", 1, 25, 26},
+		{IDENT, "π", 2, 1, 32},
+		{EOF, "", 2, 2, 34},
+	}
+
+	l := New(input)
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - expected %s %q, got %s %q", i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+		if tok.Line != tt.expectedLine || tok.Column != tt.expectedColumn {
+			t.Fatalf("tests[%d] - position wrong. expected=%d:%d, got=%d:%d", i, tt.expectedLine, tt.expectedColumn, tok.Line, tok.Column)
+		}
+		if tok.Offset != tt.expectedOffset {
+			t.Fatalf("tests[%d] - offset wrong. expected=%d, got=%d", i, tt.expectedOffset, tok.Offset)
+		}
+	}
+}
+
+func TestNumberSeparators(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedType    TokenType
+		expectedLiteral string
+		expectError     bool
+	}{
+		{"1_000", INT, "1_000", false},
+		{"1_000.5_0", FLOAT, "1_000.5_0", false},
+		{"1_", INT, "1_", true},
+		{"1__0", INT, "1__0", true},
+		{"1_.5", FLOAT, "1_.5", true},
+		{"2_e3", FLOAT, "2_e3", true},
+	}
+
+	for i, tt := range tests {
+		l := New(tt.input)
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - expected %s %q, got %s %q", i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+		if next := l.NextToken(); next.Type != EOF {
+			t.Fatalf("tests[%d] - expected EOF after the number, got %s %q", i, next.Type, next.Literal)
+		}
+		if got := len(l.Errors()) > 0; got != tt.expectError {
+			t.Errorf("tests[%d] - expected error %t, got %v", i, tt.expectError, l.Errors())
+		}
+	}
+}
@@ -0,0 +1,333 @@
+package lexer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// stringFrame describes a string literal that is being read. Interpolation
+// can leave and re-enter it, so frames live on a stack next to the states.
+type stringFrame struct {
+	raw    bool // r"..." has no escapes or interpolation
+	triple bool // """...""" may span lines and has its indentation stripped
+	indent int  // Leading whitespace characters dropped from each line
+	stop   int  // Byte offset where the content of a triple-quoted string ends
+	end    int  // Byte offset of the closing """
+
+	// Opening quote, for reporting an unterminated literal
+	line, column, offset int
+}
+
+// readString starts a string literal at its opening quote: "...", """...""",
+// r"..." or r"""...""". The first token is positioned at line:col, which is
+// the `r` of a raw string.
+func (l *Lexer) readString(line, col int, hasLeadingSpace bool, raw bool) Token {
+	f := stringFrame{raw: raw, line: line, column: col, offset: l.offsetOf(line, col)}
+	if strings.HasPrefix(l.input[l.position:], `"""`) {
+		l.readChar()
+		l.readChar()
+		l.readChar()
+		l.openTriple(f)
+	} else {
+		l.readChar() // consume opening "
+		l.pushString(f)
+	}
+
+	if l.atStringEnd() {
+		l.closeString()
+		return Token{Type: STRING, Literal: "", Line: line, Column: col, HasLeadingSpace: hasLeadingSpace}
+	}
+
+	tok := l.readStringToken(hasLeadingSpace)
+	// The literal starts at its opening quote
+	tok.Line = line
+	tok.Column = col
+	return tok
+}
+
+func (l *Lexer) pushString(f stringFrame) {
+	l.pushState(STATE_STRING)
+	l.strs = append(l.strs, f)
+}
+
+func (l *Lexer) frame() stringFrame {
+	if len(l.strs) == 0 {
+		return stringFrame{}
+	}
+	return l.strs[len(l.strs)-1]
+}
+
+// openTriple measures a triple-quoted string whose content starts at the
+// current position. A blank first line and a closing """ on a line of its own
+// are not part of the content, and the smallest indentation of the remaining
+// lines (the closing line included) is stripped from every line.
+func (l *Lexer) openTriple(f stringFrame) {
+	start := l.position
+	f.triple = true
+	f.end = findTripleEnd(l.input, start, f.raw)
+	f.stop = f.end
+
+	lines := strings.Split(l.input[start:f.end], "\n")
+	if len(lines) == 1 {
+		l.pushString(f)
+		return
+	}
+
+	f.indent = -1
+	last := lines[len(lines)-1]
+	if isBlank(last) {
+		f.stop = f.end - len(last) - 1
+		if f.stop > start && l.input[f.stop-1] == '\r' {
+			f.stop--
+		}
+		f.indent = len(last)
+	}
+	for _, line := range lines[1:] {
+		if isBlank(line) {
+			continue
+		}
+		n := len(line) - len(strings.TrimLeft(line, " \t"))
+		if f.indent < 0 || n < f.indent {
+			f.indent = n
+		}
+	}
+	if f.indent < 0 {
+		f.indent = 0
+	}
+	l.pushString(f)
+
+	if isBlank(lines[0]) {
+		for l.ch != '\n' {
+			l.readChar()
+		}
+		l.advance()
+		l.skipIndent(f)
+	}
+}
+
+// findTripleEnd returns the offset of the """ closing the string whose
+// content starts at start, skipping over escapes and interpolations. It
+// returns len(input) when the string is unterminated.
+func findTripleEnd(input string, start int, raw bool) int {
+	depth := 0 // Braces open inside an interpolation
+	for i := start; i < len(input); i++ {
+		c := input[i]
+		switch {
+		case depth == 0 && strings.HasPrefix(input[i:], `"""`):
+			return i
+		case raw:
+		case depth == 0 && c == '\\':
+			i++
+		case depth == 0 && c == '#' && i+1 < len(input) && input[i+1] == '{':
+			depth = 1
+			i++
+		case depth > 0 && c == '{':
+			depth++
+		case depth > 0 && c == '}':
+			depth--
+		case depth > 0 && c == '"':
+			// A string inside the interpolation
+			for i++; i < len(input) && input[i] != '"'; i++ {
+				if input[i] == '\\' {
+					i++
+				}
+			}
+		}
+	}
+	return len(input)
+}
+
+func isBlank(line string) bool {
+	return strings.TrimLeft(line, " \t\r") == ""
+}
+
+// skipIndent drops up to the frame's indentation at the start of a line.
+func (l *Lexer) skipIndent(f stringFrame) {
+	for i := 0; i < f.indent && (l.ch == ' ' || l.ch == '\t') && l.position < f.stop; i++ {
+		l.readChar()
+	}
+}
+
+// atStringEnd reports whether the current string's content is exhausted.
+func (l *Lexer) atStringEnd() bool {
+	f := l.frame()
+	if f.triple {
+		return l.position >= f.stop
+	}
+	return l.ch == '"'
+}
+
+// closeString consumes the closing quotes and leaves the string state.
+func (l *Lexer) closeString() {
+	f := l.frame()
+	if !f.triple {
+		l.readChar()
+		l.popState()
+		return
+	}
+
+	// Skip the newline and indentation before a closing """ on its own line
+	for l.position < f.end && l.ch != 0 {
+		l.advance()
+	}
+	if f.end >= len(l.input) {
+		l.addError(f.line, f.column, f.offset, "unterminated string literal")
+	} else {
+		l.readChar()
+		l.readChar()
+		l.readChar()
+	}
+	l.popState()
+}
+
+func (l *Lexer) readStringToken(hasLeadingSpace bool) Token {
+	tokLine := l.line
+	tokCol := l.column
+
+	if l.atStringEnd() {
+		l.closeString()
+		return l.NextToken()
+	}
+
+	if !l.frame().raw && l.ch == '#' && l.peekChar() == '{' {
+		l.pushState(STATE_CODE)
+		// Interpolation start. Does it have leading space? No, inside string.
+		tok := Token{Type: INTERP_START, Literal: "#{", Line: tokLine, Column: tokCol, HasLeadingSpace: false}
+		l.readChar() // consume #
+		l.readChar() // consume {
+		return tok
+	}
+
+	content := l.readStringContent()
+	if l.atStringEnd() {
+		// Consume the closing quote now so the token ends after it
+		l.closeString()
+	} else if l.ch == 0 {
+		f := l.frame()
+		l.addError(f.line, f.column, f.offset, "unterminated string literal")
+		l.popState()
+	}
+	return Token{
+		Type:            STRING,
+		Literal:         content,
+		Line:            tokLine,
+		Column:          tokCol,
+		HasLeadingSpace: hasLeadingSpace,
+	}
+}
+
+func (l *Lexer) readStringContent() string {
+	f := l.frame()
+	var out strings.Builder
+	for l.ch != 0 && !l.atStringEnd() {
+		if !f.raw && l.ch == '#' && l.peekChar() == '{' {
+			break
+		}
+		if !f.raw && l.ch == '\\' {
+			l.readEscape(&out)
+			continue
+		}
+
+		if l.ch == '\n' {
+			out.WriteByte('\n')
+			l.advance()
+			if f.triple {
+				l.skipIndent(f)
+			}
+			continue
+		}
+		// Copy the source bytes so invalid UTF-8 survives unchanged
+		out.WriteString(l.input[l.position:l.readPosition])
+		l.readChar()
+	}
+	return out.String()
+}
+
+// readEscape decodes the escape sequence at a backslash and leaves l.ch on
+// the character after it. Malformed sequences are recorded as errors.
+func (l *Lexer) readEscape(out *strings.Builder) {
+	line, col, offset := l.line, l.column, l.position
+	l.readChar() // consume '\'
+
+	switch l.ch {
+	case 'n':
+		out.WriteByte('\n')
+	case 't':
+		out.WriteByte('\t')
+	case 'r':
+		out.WriteByte('\r')
+	case '0':
+		out.WriteByte(0)
+	case '\\', '"', '#':
+		out.WriteRune(l.ch)
+	case 'x':
+		l.readChar()
+		hex := l.readHex(2)
+		if len(hex) != 2 {
+			l.addError(line, col, offset, `\x must be followed by two hex digits`)
+			return
+		}
+		v, _ := strconv.ParseUint(hex, 16, 8)
+		out.WriteByte(byte(v))
+		return
+	case 'u':
+		l.readChar()
+		if l.ch != '{' {
+			l.addError(line, col, offset, `\u must be followed by {hex digits}`)
+			return
+		}
+		l.readChar()
+		hex := l.readHex(6)
+		if hex == "" || l.ch != '}' {
+			l.addError(line, col, offset, `\u must be followed by {hex digits}`)
+			return
+		}
+		l.readChar()
+		v, _ := strconv.ParseUint(hex, 16, 32)
+		if !utf8.ValidRune(rune(v)) {
+			l.addError(line, col, offset, fmt.Sprintf("invalid code point U+%X", v))
+			return
+		}
+		out.WriteRune(rune(v))
+		return
+	case 0, '\n':
+		l.addError(line, col, offset, "unfinished escape sequence")
+		return
+	default:
+		l.readChar()
+		l.addError(line, col, offset, fmt.Sprintf("unknown escape sequence %s", l.input[offset:l.position]))
+		return
+	}
+	l.readChar()
+}
+
+// readHex reads up to max hex digits.
+func (l *Lexer) readHex(max int) string {
+	start := l.position
+	for i := 0; i < max && isHexDigit(l.ch); i++ {
+		l.readChar()
+	}
+	return l.input[start:l.position]
+}
+
+func isHexDigit(ch rune) bool {
+	return isDigit(ch) || 'a' <= ch && ch <= 'f' || 'A' <= ch && ch <= 'F'
+}
+
+// addError records a malformed literal from line:col (byte offset) up to the
+// current position.
+func (l *Lexer) addError(line, col, offset int, msg string) {
+	tok := Token{
+		Type:      ILLEGAL,
+		Literal:   l.input[offset:l.position],
+		Line:      line,
+		Column:    col,
+		Offset:    offset,
+		EndLine:   l.line,
+		EndColumn: l.column,
+		EndOffset: l.position,
+	}
+	l.errors = append(l.errors, Error{Token: tok, Message: msg})
+}
@@ -0,0 +1,104 @@
+package lexer
+
+import (
+	"testing"
+)
+
+func TestStringLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`""`, ""},
+		{`"a\tb\r\0\\\"\#{x}"`, "a\tb\r\x00\\\"#{x}"},
+		{`"\x41\xff"`, "A\xff"},
+		{`"\u{41}\u{e9}\u{1F600}"`, "Aé😀"},
+		{`r"C:\path\#{x}"`, `C:\path\#{x}`},
+		{`"""satu"""`, "satu"},
+		{"\"\"\"\n    a\n      b\n    \"\"\"", "a\n  b"},
+		{"\"\"\"\n    a\n\n    b\n  \"\"\"", "  a\n\n  b"},
+		{"\"\"\"\n  a \"kutip\"\n  \"\"\"", "a \"kutip\""},
+		{"r\"\"\"\n  \\d+\n  \"\"\"", `\d+`},
+	}
+
+	for i, tt := range tests {
+		l := New(tt.input)
+		tok := l.NextToken()
+		if tok.Type != STRING || tok.Literal != tt.expected {
+			t.Errorf("tests[%d] - expected STRING %q, got %s %q", i, tt.expected, tok.Type, tok.Literal)
+		}
+		if len(l.Errors()) > 0 {
+			t.Errorf("tests[%d] - unexpected error: %s", i, l.Errors()[0].Message)
+		}
+		if next := l.NextToken(); next.Type != EOF {
+			t.Errorf("tests[%d] - expected EOF after the literal, got %s %q", i, next.Type, next.Literal)
+		}
+	}
+}
+
+func TestTripleQuotedInterpolation(t *testing.T) {
+	input := "x = \"\"\"\n    nama: #{nama}\n      umur: #{umur}\n    \"\"\"\ny"
+
+	tests := []struct {
+		expectedType    TokenType
+		expectedLiteral string
+		expectedLine    int
+	}{
+		{IDENT, "x", 1},
+		{ASSIGN, "=", 1},
+		{STRING, "nama: ", 1},
+		{INTERP_START, "#{", 2},
+		{IDENT, "nama", 2},
+		{RBRACE, "}", 2},
+		{STRING, "\n  umur: ", 2},
+		{INTERP_START, "#{", 3},
+		{IDENT, "umur", 3},
+		{RBRACE, "}", 3},
+		{IDENT, "y", 5},
+		{EOF, "", 5},
+	}
+
+	l := New(input)
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - expected %s %q, got %s %q", i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+		if tok.Line != tt.expectedLine {
+			t.Fatalf("tests[%d] - line wrong. expected=%d, got=%d", i, tt.expectedLine, tok.Line)
+		}
+	}
+}
+
+func TestMalformedStrings(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedError  string
+		expectedLine   int
+		expectedColumn int
+	}{
+		{`x = "ab\qc"`, `unknown escape sequence \q`, 1, 8},
+		{`"\x4"`, `\x must be followed by two hex digits`, 1, 2},
+		{`"\u41"`, `\u must be followed by {hex digits}`, 1, 2},
+		{`"\u{D800}"`, "invalid code point U+D800", 1, 2},
+		{"x = 1\n\"abc", "unterminated string literal", 2, 1},
+		{"\"\"\"\n  abc", "unterminated string literal", 1, 1},
+	}
+
+	for i, tt := range tests {
+		l := New(tt.input)
+		for tok := l.NextToken(); tok.Type != EOF; tok = l.NextToken() {
+		}
+		errs := l.Errors()
+		if len(errs) != 1 {
+			t.Fatalf("tests[%d] - expected 1 error, got %d", i, len(errs))
+		}
+		e := errs[0]
+		if e.Message != tt.expectedError {
+			t.Errorf("tests[%d] - message wrong. expected=%q, got=%q", i, tt.expectedError, e.Message)
+		}
+		if e.Token.Line != tt.expectedLine || e.Token.Column != tt.expectedColumn {
+			t.Errorf("tests[%d] - position wrong. expected=%d:%d, got=%d:%d", i, tt.expectedLine, tt.expectedColumn, e.Token.Line, e.Token.Column)
+		}
+	}
+}
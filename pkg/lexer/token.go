@@ -10,6 +10,13 @@ type Token struct {
 	Line            int
 	Column          int
 	HasLeadingSpace bool
+
+	// Byte offset of the first character, and the position just past the
+	// last one. Filled in by Lexer.NextToken.
+	Offset    int
+	EndLine   int
+	EndColumn int
+	EndOffset int
 }
 
 const (
@@ -29,6 +36,19 @@ const (
 	BANG     = "!"
 	ASTERISK = "*"
 	SLASH    = "/"
+	PERCENT  = "%"
+	FLOORDIV = "//" // Integer (floor) division
+	POWER    = "**" // Exponent, right-associative
+
+	// Fallbacks: the right side replaces a kosong (??) or galat (?!) left side
+	NULL_COALESCE  = "??"
+	ERROR_COALESCE = "?!"
+
+	// Compound assignment
+	PLUS_ASSIGN     = "+="
+	MINUS_ASSIGN    = "-="
+	ASTERISK_ASSIGN = "*="
+	SLASH_ASSIGN    = "/="
 
 	LT     = "<"
 	GT     = ">"
@@ -50,6 +70,11 @@ const (
 	SEMICOLON = ";"
 	COLON     = ":"
 	DOT       = "."
+	QDOT      = "?." // Null-safe access: kosong when the left side is
+	ELLIPSIS  = "..."
+	DOTDOT    = ".."  // Inclusive range
+	DOTDOT_LT = "..<" // Exclusive range
+	ARROW     = "->"  // Before a return type
 
 	LPAREN   = "("
 	RPAREN   = ")"
@@ -79,6 +104,12 @@ const (
 	BERHENTI   = "BERHENTI"
 	LANJUT     = "LANJUT"
 	STRUKTUR   = "STRUKTUR"
+	UNTUK      = "UNTUK"
+	COCOKKAN   = "COCOKKAN"
+	KASUS      = "KASUS"
+	COBA       = "COBA"
+	TETAP      = "TETAP"
+	PILIHAN    = "PILIHAN"
 	COMMENT    = "COMMENT"
 )
 
@@ -100,6 +131,12 @@ var keywords = map[string]TokenType{
 	"dari":       DARI,
 	"berhenti":   BERHENTI,
 	"lanjut":     LANJUT,
+	"untuk":      UNTUK,
+	"cocokkan":   COCOKKAN,
+	"kasus":      KASUS,
+	"coba":       COBA,
+	"tetap":      TETAP,
+	"pilihan":    PILIHAN,
 }
 
 // LookupIdent checks if an identifier is a keyword (case-insensitive)
@@ -153,6 +153,11 @@ func (c *Cabinet) bringToRAM(drawerID int) error {
 			return err
 		}
 		freeSlot = victimSlot
+
+		// Dynamic aging: the incoming drawer starts at least as hot as the
+		// victim. Otherwise drawers needed now keep evicting each other
+		// while stale ones with a long history stay resident.
+		target.AccessCount += c.Drawers[victimID].AccessCount
 	}
 
 	// Calculate Physical Address for this slot
@@ -197,8 +202,14 @@ func (c *Cabinet) evictToSwap(drawerID int) error {
 	physAddr := unsafe.Add(RAM.BasePointer(), uintptr(slot)*uintptr(DRAWER_SIZE))
 	srcSlice := unsafe.Slice((*byte)(physAddr), DRAWER_SIZE)
 
-	// 2. Write to .z file
-	fileOffset, err := Swap.Spill(srcSlice)
+	// 2. Write to .z file, in place if the drawer was swapped out before
+	fileOffset := victim.SwapOffset
+	var err error
+	if fileOffset > 0 {
+		err = Swap.SpillAt(fileOffset, srcSlice)
+	} else {
+		fileOffset, err = Swap.Spill(srcSlice)
+	}
 	if err != nil {
 		return err
 	}
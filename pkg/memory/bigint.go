@@ -0,0 +1,79 @@
+package memory
+
+import (
+	"fmt"
+	"math/big"
+	"unsafe"
+)
+
+// MaxBigIntBits is the largest magnitude a BigInt can hold: its bytes must
+// fit in one tray next to the header and the sign/length words.
+const MaxBigIntBits = (TRAY_SIZE - 32) * 8
+
+// AllocBigInt allocates an arbitrary-precision integer in the Cabinet.
+// Layout: [Header][int32 Sign][int32 Length][Magnitude bytes, big-endian...]
+func AllocBigInt(v *big.Int) (Ptr, error) {
+	if v.BitLen() > MaxBigIntBits {
+		return NilPtr, fmt.Errorf("integer of %d bits exceeds the %d bit limit", v.BitLen(), MaxBigIntBits)
+	}
+	mag := v.Bytes()
+	totalSize := HeaderSize + 8 + len(mag)
+
+	// Align to 8 bytes
+	allocSize := (totalSize + 7) & ^7
+
+	Lemari.mu.Lock()
+	defer Lemari.mu.Unlock()
+
+	ptr, err := Lemari.alloc(allocSize)
+	if err != nil { return NilPtr, err }
+
+	raw, err := Lemari.resolve(ptr)
+	if err != nil { return NilPtr, err }
+
+	header := (*Header)(raw)
+	header.Type = TagBigInt
+	header.Size = uint32(allocSize)
+
+	writeMagnitude(uintptr(raw)+uintptr(HeaderSize), v.Sign(), mag)
+	return ptr, nil
+}
+
+// ReadBigInt reads the value of a BigInt object.
+func ReadBigInt(ptr Ptr) (*big.Int, error) {
+	Lemari.mu.Lock()
+	defer Lemari.mu.Unlock()
+
+	raw, err := Lemari.resolve(ptr)
+	if err != nil { return nil, err }
+
+	return readBigInt(raw), nil
+}
+
+func readBigInt(raw unsafe.Pointer) *big.Int {
+	return readMagnitude(uintptr(raw) + uintptr(HeaderSize))
+}
+
+// writeMagnitude stores [int32 Sign][int32 Length][Magnitude...] at base.
+func writeMagnitude(base uintptr, sign int, mag []byte) {
+	*(*int32)(unsafe.Pointer(base)) = int32(sign)
+	*(*int32)(unsafe.Pointer(base + 4)) = int32(len(mag))
+	if len(mag) > 0 {
+		dst := unsafe.Slice((*byte)(unsafe.Pointer(base+8)), len(mag))
+		copy(dst, mag)
+	}
+}
+
+func readMagnitude(base uintptr) *big.Int {
+	sign := *(*int32)(unsafe.Pointer(base))
+	length := int(*(*int32)(unsafe.Pointer(base + 4)))
+
+	v := new(big.Int)
+	if length > 0 {
+		v.SetBytes(unsafe.Slice((*byte)(unsafe.Pointer(base+8)), length))
+	}
+	if sign < 0 {
+		v.Neg(v)
+	}
+	return v
+}
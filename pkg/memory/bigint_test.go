@@ -0,0 +1,40 @@
+package memory
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestBigIntRoundTrip(t *testing.T) {
+	InitCabinet()
+
+	values := []string{
+		"0",
+		"9223372036854775808",
+		"-170141183460469231731687303715884105728",
+		"123456789012345678901234567890123456789012345678901234567890",
+	}
+	for _, s := range values {
+		want, _ := new(big.Int).SetString(s, 10)
+		ptr, err := AllocBigInt(want)
+		if err != nil {
+			t.Fatalf("AllocBigInt(%s) failed: %v", s, err)
+		}
+		got, err := ReadBigInt(ptr)
+		if err != nil {
+			t.Fatalf("ReadBigInt(%s) failed: %v", s, err)
+		}
+		if got.Cmp(want) != 0 {
+			t.Errorf("round trip mismatch. want=%s, got=%s", want, got)
+		}
+	}
+}
+
+func TestBigIntTooLarge(t *testing.T) {
+	InitCabinet()
+
+	v := new(big.Int).Lsh(big.NewInt(1), MaxBigIntBits)
+	if _, err := AllocBigInt(v); err == nil {
+		t.Errorf("expected an error for a %d-bit integer", v.BitLen())
+	}
+}
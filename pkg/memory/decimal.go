@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"fmt"
+	"math/big"
+	"unsafe"
+)
+
+// AllocDecimal allocates an exact decimal, unscaled * 10^-scale, in the Cabinet.
+// Layout: [Header][int32 Scale][int32 Reserved][int32 Sign][int32 Length][Magnitude bytes, big-endian...]
+func AllocDecimal(unscaled *big.Int, scale int32) (Ptr, error) {
+	if unscaled.BitLen() > MaxBigIntBits {
+		return NilPtr, fmt.Errorf("decimal of %d bits exceeds the %d bit limit", unscaled.BitLen(), MaxBigIntBits)
+	}
+	mag := unscaled.Bytes()
+	totalSize := HeaderSize + 16 + len(mag)
+
+	// Align to 8 bytes
+	allocSize := (totalSize + 7) & ^7
+
+	Lemari.mu.Lock()
+	defer Lemari.mu.Unlock()
+
+	ptr, err := Lemari.alloc(allocSize)
+	if err != nil { return NilPtr, err }
+
+	raw, err := Lemari.resolve(ptr)
+	if err != nil { return NilPtr, err }
+
+	header := (*Header)(raw)
+	header.Type = TagDecimal
+	header.Size = uint32(allocSize)
+
+	base := uintptr(raw) + uintptr(HeaderSize)
+	*(*int32)(unsafe.Pointer(base)) = scale
+	*(*int32)(unsafe.Pointer(base + 4)) = 0
+	writeMagnitude(base+8, unscaled.Sign(), mag)
+	return ptr, nil
+}
+
+// ReadDecimal reads the unscaled value and scale of a Decimal object.
+func ReadDecimal(ptr Ptr) (*big.Int, int32, error) {
+	Lemari.mu.Lock()
+	defer Lemari.mu.Unlock()
+
+	raw, err := Lemari.resolve(ptr)
+	if err != nil { return nil, 0, err }
+
+	unscaled, scale := readDecimal(raw)
+	return unscaled, scale, nil
+}
+
+func readDecimal(raw unsafe.Pointer) (*big.Int, int32) {
+	base := uintptr(raw) + uintptr(HeaderSize)
+	return readMagnitude(base + 8), *(*int32)(unsafe.Pointer(base))
+}
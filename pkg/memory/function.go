@@ -4,13 +4,22 @@ import (
 	"unsafe"
 )
 
-// Layout: [Header][NumLocals(4)][NumParams(4)][InstrLen(4)][Instructions...]
+// Layout: [Header][NumLocals(4)][NumParams(4)][MinParams(4)][Variadic(4)][ParamNames(8)][InstrLen(4)][Instructions...]
 // Note: Instructions usually byte array. Padded to 8 bytes.
+// NumParams counts every parameter slot, including the variadic rest array;
+// MinParams is how many arguments a call must pass at least. ParamNames is an
+// Array of Strings used to bind named arguments (NilPtr if unknown).
 
 func AllocCompiledFunction(instructions []byte, numLocals, numParams int) (Ptr, error) {
+	return AllocFunctionWithArity(instructions, numLocals, numParams, numParams, false, NilPtr)
+}
+
+// AllocFunctionWithArity allocates a function with optional (defaulted)
+// and/or variadic parameters, and the names of its parameters.
+func AllocFunctionWithArity(instructions []byte, numLocals, numParams, minParams int, variadic bool, paramNames Ptr) (Ptr, error) {
 	instrLen := len(instructions)
-	// Payload: 4+4+4 = 12 bytes + instrLen
-	payloadSize := 12 + instrLen
+	// Payload: 4+4+4+4+8+4 = 28 bytes + instrLen
+	payloadSize := 28 + instrLen
 	totalSize := HeaderSize + payloadSize
 	allocSize := (totalSize + 7) & ^7
 
@@ -38,8 +47,23 @@ func AllocCompiledFunction(instructions []byte, numLocals, numParams int) (Ptr,
 	paramPtr := unsafe.Pointer(uintptr(bodyPtr) + 4)
 	*(*int32)(paramPtr) = int32(numParams)
 
+	// Write MinParams
+	minPtr := unsafe.Pointer(uintptr(paramPtr) + 4)
+	*(*int32)(minPtr) = int32(minParams)
+
+	// Write Variadic
+	variadicPtr := unsafe.Pointer(uintptr(minPtr) + 4)
+	*(*int32)(variadicPtr) = 0
+	if variadic {
+		*(*int32)(variadicPtr) = 1
+	}
+
+	// Write ParamNames
+	namesPtr := unsafe.Pointer(uintptr(variadicPtr) + 4)
+	*(*Ptr)(namesPtr) = paramNames
+
 	// Write InstrLen
-	lenPtr := unsafe.Pointer(uintptr(paramPtr) + 4)
+	lenPtr := unsafe.Pointer(uintptr(namesPtr) + 8)
 	*(*int32)(lenPtr) = int32(instrLen)
 
 	// Write Instructions
@@ -66,7 +90,7 @@ func ReadCompiledFunction(ptr Ptr) ([]byte, int, int, error) {
 	paramPtr := unsafe.Pointer(uintptr(bodyPtr) + 4)
 	numParams := int(*(*int32)(paramPtr))
 
-	lenPtr := unsafe.Pointer(uintptr(paramPtr) + 4)
+	lenPtr := unsafe.Pointer(uintptr(paramPtr) + 20)
 	instrLen := int(*(*int32)(lenPtr))
 
 	instr := make([]byte, instrLen)
@@ -96,6 +120,36 @@ func ReadCompiledFunctionMeta(ptr Ptr) (int, int, error) {
 	return numLocals, numParams, nil
 }
 
+// ReadCompiledFunctionArity reads the minimum argument count and whether the
+// last parameter collects the remaining arguments.
+func ReadCompiledFunctionArity(ptr Ptr) (int, bool, error) {
+	Lemari.mu.Lock()
+	defer Lemari.mu.Unlock()
+
+	raw, err := Lemari.resolve(ptr)
+	if err != nil { return 0, false, err }
+
+	minPtr := unsafe.Pointer(uintptr(raw) + uintptr(HeaderSize) + 8)
+	minParams := int(*(*int32)(minPtr))
+
+	variadicPtr := unsafe.Pointer(uintptr(minPtr) + 4)
+	variadic := *(*int32)(variadicPtr) != 0
+
+	return minParams, variadic, nil
+}
+
+// ReadFunctionParamNames reads the Array of parameter names (NilPtr if unknown).
+func ReadFunctionParamNames(ptr Ptr) (Ptr, error) {
+	Lemari.mu.Lock()
+	defer Lemari.mu.Unlock()
+
+	raw, err := Lemari.resolve(ptr)
+	if err != nil { return NilPtr, err }
+
+	namesPtr := unsafe.Pointer(uintptr(raw) + uintptr(HeaderSize) + 16)
+	return *(*Ptr)(namesPtr), nil
+}
+
 // Layout: [Header][FnPtr(8)][FreeCount(4)][FreePtr0(8)]...
 func AllocClosure(fnPtr Ptr, freeVars []Ptr) (Ptr, error) {
 	freeCount := len(freeVars)
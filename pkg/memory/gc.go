@@ -81,7 +81,8 @@ func (c *Cabinet) findVictimLFU() int {
 
 	// Iterate over physical slots to find a resident victim
 	for i, drawerID := range c.RAMSlots {
-		if drawerID == -1 {
+		// The allocator needs its drawer again on the next Alloc
+		if drawerID == -1 || drawerID == c.ActiveDrawerIndex {
 			continue
 		}
 
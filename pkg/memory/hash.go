@@ -1,20 +1,73 @@
 package memory
 
-import "unsafe"
-
-// AllocHash allocates a Hash map container.
-// For MVP Phase X, this is a linear list of Key-Value pairs.
-// Layout: [Header][int32 Count][Pair0_Key][Pair0_Value]...
-func AllocHash(count int) (Ptr, error) {
-	// Each pair is 2 Ptrs (16 bytes)
-	payloadSize := 4 + (count * 16)
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"unsafe"
+)
+
+// ErrUnhashableKey is returned when a key has no stable value identity.
+// The copying GC moves objects, so only value types (integer, float, string,
+// boolean, null) can be hashed.
+var ErrUnhashableKey = fmt.Errorf("unusable as hash key")
+
+// ErrReadOnlyHash is returned when writing to a hash frozen by FreezeHash.
+var ErrReadOnlyHash = fmt.Errorf("hash is read-only")
+
+// ErrReadOnlyKey is returned when writing to a key frozen by FreezeHashKey.
+var ErrReadOnlyKey = fmt.Errorf("hash key is read-only")
+
+const (
+	hashMinSlots  = 8
+	hashSlotSize  = 8  // [int32 Index][uint32 Tag]
+	hashEntrySize = 24 // [uint64 Hash][Ptr Key][Ptr Value]
+
+	// Slots and entries live in segments that each fit in a Tray
+	hashSlotsPerSegment   = 4096
+	hashEntriesPerSegment = 2048
+
+	hashSlotEmpty   = 0
+	hashSlotDeleted = -1
+
+	hashFlagReadOnly = 1
+
+	// Set in the stored hash of an entry frozen by FreezeHashKey. Key hashes
+	// never have it, so it does not take part in lookups.
+	hashEntryReadOnly = 1 << 63
+)
+
+// AllocHash allocates a growable Hash map with room for `capacity` entries.
+// The Hash object is a fixed-size handle so that references to it survive
+// table resizes.
+// Layout: [Header][int32 Count][int32 Used][Ptr Table][int32 Flags]
+//
+// Count is the number of live entries, Used the number of entry slots consumed
+// (live + removed). Flags marks a hash frozen by FreezeHash. Table points to a TagHashTable object:
+// Layout: [Header][int32 SlotCap][int32 EntryCap][Ptr SlotSegments...][Ptr EntrySegments...]
+//
+// The segments split the table across Trays, so a hash is not limited to the
+// size of one. A TagHashSlots segment holds up to hashSlotsPerSegment slots:
+// Layout: [Header][[int32 Index][uint32 Tag]...]
+// A TagHashEntries segment holds up to hashEntriesPerSegment entries:
+// Layout: [Header][int32 Cap][int32 Unused][Entries...]
+//
+// Slots are open-addressed (linear probing) indices into the Entries array,
+// which is kept in insertion order: 0 = empty, -1 = deleted, n = entry n-1.
+// Tag is the high half of the entry's hash, so a probe can skip other keys
+// without resolving their entry segment. An entry whose Key is NilPtr has
+// been removed; one whose Hash has the top bit set is frozen by FreezeHashKey.
+func AllocHash(capacity int) (Ptr, error) {
+	payloadSize := 4 + 4 + 8 + 4
 	totalSize := HeaderSize + payloadSize
-	allocSize := (totalSize + 7) & ^7
 
 	Lemari.mu.Lock()
 	defer Lemari.mu.Unlock()
 
-	ptr, err := Lemari.alloc(allocSize)
+	table, err := Lemari.allocHashTable(hashSlotsFor(capacity))
+	if err != nil { return NilPtr, err }
+
+	ptr, err := Lemari.alloc(totalSize)
 	if err != nil { return NilPtr, err }
 
 	raw, err := Lemari.resolve(ptr)
@@ -22,57 +75,645 @@ func AllocHash(count int) (Ptr, error) {
 
 	header := (*Header)(raw)
 	header.Type = TagHash
-	header.Size = uint32(allocSize)
+	header.Size = uint32(totalSize)
 
-	countPtr := unsafe.Pointer(uintptr(raw) + uintptr(HeaderSize))
-	*(*int32)(countPtr) = int32(count)
+	base := uintptr(raw) + uintptr(HeaderSize)
+	*(*int32)(unsafe.Pointer(base)) = 0
+	*(*int32)(unsafe.Pointer(base + 4)) = 0
+	*(*Ptr)(unsafe.Pointer(base + 8)) = table
+	*(*int32)(unsafe.Pointer(base + 16)) = 0
 
 	return ptr, nil
 }
 
-func WriteHashPair(hashPtr Ptr, index int, key, value Ptr) error {
+// FreezeHash makes the hash read-only: HashSet and HashDelete fail with
+// ErrReadOnlyHash from then on.
+func FreezeHash(hashPtr Ptr) error {
 	Lemari.mu.Lock()
 	defer Lemari.mu.Unlock()
 
-	raw, err := Lemari.resolve(hashPtr)
+	p, err := Lemari.hashField(hashPtr, 16)
 	if err != nil { return err }
+	*(*int32)(p) |= hashFlagReadOnly
+	return nil
+}
+
+// FreezeHashKey makes one existing key read-only: HashSet and HashDelete
+// fail with ErrReadOnlyKey for it, while other keys stay writable.
+func FreezeHashKey(hashPtr Ptr, key Ptr) error {
+	Lemari.mu.Lock()
+	defer Lemari.mu.Unlock()
 
-	// Offset = Header + Count(4) + (Index * 16)
-	base := uintptr(raw) + uintptr(HeaderSize) + 4
-	offset := uintptr(index) * 16
+	k, err := Lemari.hashKey(key)
+	if err != nil { return err }
 
-	keyPtr := unsafe.Pointer(base + offset)
-	valPtr := unsafe.Pointer(base + offset + 8)
+	v, err := Lemari.hashView(hashPtr)
+	if err != nil { return err }
 
-	*(*uint64)(keyPtr) = uint64(key)
-	*(*uint64)(valPtr) = uint64(value)
+	_, entry, err := Lemari.hashFind(v, key, k)
+	if err != nil { return err }
+	if entry < 0 {
+		return fmt.Errorf("cannot freeze a missing hash key")
+	}
 
+	p, err := Lemari.hashEntry(v, entry)
+	if err != nil { return err }
+	*(*uint64)(unsafe.Pointer(p)) |= hashEntryReadOnly
 	return nil
 }
 
-func ReadHashPair(hashPtr Ptr, index int) (Ptr, Ptr, error) {
+// HashGet looks up `key` and returns its value.
+func HashGet(hashPtr Ptr, key Ptr) (Ptr, bool, error) {
+	Lemari.mu.Lock()
+	defer Lemari.mu.Unlock()
+
+	k, err := Lemari.hashKey(key)
+	if err != nil { return NilPtr, false, err }
+
+	v, err := Lemari.hashView(hashPtr)
+	if err != nil { return NilPtr, false, err }
+
+	_, entry, err := Lemari.hashFind(v, key, k)
+	if err != nil || entry < 0 { return NilPtr, false, err }
+
+	p, err := Lemari.hashEntry(v, entry)
+	if err != nil { return NilPtr, false, err }
+	return *(*Ptr)(unsafe.Pointer(p + 16)), true, nil
+}
+
+// HashSet inserts or updates `key`, growing the table when it is full.
+func HashSet(hashPtr Ptr, key Ptr, value Ptr) error {
 	Lemari.mu.Lock()
 	defer Lemari.mu.Unlock()
 
-	raw, err := Lemari.resolve(hashPtr)
-	if err != nil { return NilPtr, NilPtr, err }
+	if err := Lemari.hashCheckWritable(hashPtr); err != nil { return err }
 
-	base := uintptr(raw) + uintptr(HeaderSize) + 4
-	offset := uintptr(index) * 16
+	k, err := Lemari.hashKey(key)
+	if err != nil { return err }
 
-	keyPtr := unsafe.Pointer(base + offset)
-	valPtr := unsafe.Pointer(base + offset + 8)
+	v, err := Lemari.hashView(hashPtr)
+	if err != nil { return err }
+
+	slot, entry, err := Lemari.hashFind(v, key, k)
+	if err != nil { return err }
 
-	return Ptr(*(*uint64)(keyPtr)), Ptr(*(*uint64)(valPtr)), nil
+	if entry >= 0 {
+		p, err := Lemari.hashEntry(v, entry)
+		if err != nil { return err }
+		if *(*uint64)(unsafe.Pointer(p))&hashEntryReadOnly != 0 {
+			return ErrReadOnlyKey
+		}
+		*(*Ptr)(unsafe.Pointer(p + 16)) = value
+		return nil
+	}
+
+	count, used, err := Lemari.hashCounts(hashPtr)
+	if err != nil { return err }
+
+	if used >= v.entryCap {
+		v, err = Lemari.hashRehash(hashPtr, v, count)
+		if err != nil { return err }
+		used = count
+
+		slot, _, err = Lemari.hashFind(v, key, k)
+		if err != nil { return err }
+	}
+
+	if err := Lemari.hashWriteEntry(v, used, k.hash, key, value); err != nil { return err }
+	if err := Lemari.hashWriteSlot(v, slot, int32(used+1), k.hash); err != nil { return err }
+
+	return Lemari.hashWriteCounts(hashPtr, count+1, used+1)
 }
 
+// HashDelete removes `key`. It reports whether the key was present.
+func HashDelete(hashPtr Ptr, key Ptr) (bool, error) {
+	Lemari.mu.Lock()
+	defer Lemari.mu.Unlock()
+
+	if err := Lemari.hashCheckWritable(hashPtr); err != nil { return false, err }
+
+	k, err := Lemari.hashKey(key)
+	if err != nil { return false, err }
+
+	v, err := Lemari.hashView(hashPtr)
+	if err != nil { return false, err }
+
+	slot, entry, err := Lemari.hashFind(v, key, k)
+	if err != nil || entry < 0 { return false, err }
+
+	p, err := Lemari.hashEntry(v, entry)
+	if err != nil { return false, err }
+	if *(*uint64)(unsafe.Pointer(p))&hashEntryReadOnly != 0 {
+		return false, ErrReadOnlyKey
+	}
+
+	if err := Lemari.hashWriteEntry(v, entry, 0, NilPtr, NilPtr); err != nil { return false, err }
+	if err := Lemari.hashWriteSlot(v, slot, hashSlotDeleted, 0); err != nil { return false, err }
+
+	count, used, err := Lemari.hashCounts(hashPtr)
+	if err != nil { return false, err }
+	return true, Lemari.hashWriteCounts(hashPtr, count-1, used)
+}
+
+// ReadHashCount returns the number of live entries.
 func ReadHashCount(hashPtr Ptr) (int, error) {
 	Lemari.mu.Lock()
 	defer Lemari.mu.Unlock()
 
-	raw, err := Lemari.resolve(hashPtr)
+	count, _, err := Lemari.hashCounts(hashPtr)
+	return count, err
+}
+
+// HashNext returns the first live entry at or after `cursor` (in insertion
+// order) and the cursor for the following call. next is -1 when exhausted.
+//
+// The cursor is an entry index. Removed entries keep their index, and a
+// rehash of a hash without removed entries copies the rest in place, so
+// HashSet and HashDelete between calls are safe on their own. A HashSet that
+// rehashes after a HashDelete drops the removed entries and renumbers the
+// others: deleting and then growing the hash while iterating it is not
+// supported and may skip or repeat entries.
+func HashNext(hashPtr Ptr, cursor int) (key Ptr, value Ptr, next int, err error) {
+	Lemari.mu.Lock()
+	defer Lemari.mu.Unlock()
+
+	_, used, err := Lemari.hashCounts(hashPtr)
+	if err != nil { return NilPtr, NilPtr, -1, err }
+
+	v, err := Lemari.hashView(hashPtr)
+	if err != nil { return NilPtr, NilPtr, -1, err }
+
+	next = -1
+	err = Lemari.hashScanEntries(v, cursor, used, func(i int, _ uint64, k, val Ptr) bool {
+		key, value, next = k, val, i+1
+		return false
+	})
+	if err != nil { return NilPtr, NilPtr, -1, err }
+	return key, value, next, nil
+}
+
+// ReadHashPairs returns all live keys and values in insertion order.
+func ReadHashPairs(hashPtr Ptr) ([]Ptr, []Ptr, error) {
+	Lemari.mu.Lock()
+	defer Lemari.mu.Unlock()
+
+	count, used, err := Lemari.hashCounts(hashPtr)
+	if err != nil { return nil, nil, err }
+
+	v, err := Lemari.hashView(hashPtr)
+	if err != nil { return nil, nil, err }
+
+	keys := make([]Ptr, 0, count)
+	values := make([]Ptr, 0, count)
+	err = Lemari.hashScanEntries(v, 0, used, func(_ int, _ uint64, k, val Ptr) bool {
+		keys = append(keys, k)
+		values = append(values, val)
+		return true
+	})
+	if err != nil { return nil, nil, err }
+	return keys, values, nil
+}
+
+// hashSlotsFor returns the slot capacity needed to hold `capacity` entries.
+func hashSlotsFor(capacity int) int {
+	slots := hashMinSlots
+	for hashEntriesFor(slots) < capacity {
+		slots *= 2
+	}
+	return slots
+}
+
+// hashEntriesFor keeps the load factor at 3/4 so probing always terminates.
+func hashEntriesFor(slots int) int {
+	return slots * 3 / 4
+}
+
+// The helpers below assume Lemari.mu is Locked. A resolved address is only
+// good until the next resolve, which may page its Drawer out of RAM, so each
+// one is read out before another object is touched.
+
+// hashSegmentsFor returns how many segments of perSegment hold n items.
+func hashSegmentsFor(n, perSegment int) int {
+	return (n + perSegment - 1) / perSegment
+}
+
+func (c *Cabinet) allocHashTable(slotCap int) (Ptr, error) {
+	entryCap := hashEntriesFor(slotCap)
+	slotSegs := hashSegmentsFor(slotCap, hashSlotsPerSegment)
+	entrySegs := hashSegmentsFor(entryCap, hashEntriesPerSegment)
+
+	totalSize := HeaderSize + 8 + (slotSegs+entrySegs)*8
+	if totalSize > TRAY_SIZE {
+		return NilPtr, fmt.Errorf("hash too large: %d entries", entryCap)
+	}
+
+	// Segments first: the table is written once they all exist
+	segments := make([]Ptr, 0, slotSegs+entrySegs)
+	for i := 0; i < slotSegs; i++ {
+		n := min(slotCap-i*hashSlotsPerSegment, hashSlotsPerSegment)
+		seg, err := c.allocHashSegment(TagHashSlots, n*hashSlotSize, 0)
+		if err != nil { return NilPtr, err }
+		segments = append(segments, seg)
+	}
+	for i := 0; i < entrySegs; i++ {
+		n := min(entryCap-i*hashEntriesPerSegment, hashEntriesPerSegment)
+		seg, err := c.allocHashSegment(TagHashEntries, 8+n*hashEntrySize, n)
+		if err != nil { return NilPtr, err }
+		segments = append(segments, seg)
+	}
+
+	ptr, err := c.alloc(totalSize)
+	if err != nil { return NilPtr, err }
+
+	raw, err := c.resolve(ptr)
+	if err != nil { return NilPtr, err }
+
+	header := (*Header)(raw)
+	header.Type = TagHashTable
+	header.Size = uint32(totalSize)
+
+	base := uintptr(raw) + uintptr(HeaderSize)
+	*(*int32)(unsafe.Pointer(base)) = int32(slotCap)
+	*(*int32)(unsafe.Pointer(base + 4)) = int32(entryCap)
+	for i, seg := range segments {
+		*(*Ptr)(unsafe.Pointer(base + 8 + uintptr(i*8))) = seg
+	}
+
+	return ptr, nil
+}
+
+// allocHashSegment allocates a zeroed segment. Entry segments record their
+// capacity in the first field for the GC.
+func (c *Cabinet) allocHashSegment(tag TypeTag, payloadSize int, capacity int) (Ptr, error) {
+	totalSize := HeaderSize + payloadSize
+
+	ptr, err := c.alloc(totalSize)
+	if err != nil { return NilPtr, err }
+
+	raw, err := c.resolve(ptr)
+	if err != nil { return NilPtr, err }
+
+	header := (*Header)(raw)
+	header.Type = tag
+	header.Size = uint32(totalSize)
+
+	// Zero slots and entries (Trays are reused after GC)
+	base := uintptr(raw) + uintptr(HeaderSize)
+	payload := unsafe.Slice((*byte)(unsafe.Pointer(base)), payloadSize)
+	for i := range payload {
+		payload[i] = 0
+	}
+	if tag == TagHashEntries {
+		*(*int32)(unsafe.Pointer(base)) = int32(capacity)
+	}
+
+	return ptr, nil
+}
+
+func (c *Cabinet) hashField(hashPtr Ptr, offset uintptr) (unsafe.Pointer, error) {
+	raw, err := c.resolve(hashPtr)
+	if err != nil { return nil, err }
+	if (*Header)(raw).Type != TagHash {
+		return nil, fmt.Errorf("not a hash: type tag %d", (*Header)(raw).Type)
+	}
+	return unsafe.Pointer(uintptr(raw) + uintptr(HeaderSize) + offset), nil
+}
+
+func (c *Cabinet) hashCounts(hashPtr Ptr) (int, int, error) {
+	p, err := c.hashField(hashPtr, 0)
+	if err != nil { return 0, 0, err }
+	return int(*(*int32)(p)), int(*(*int32)(unsafe.Pointer(uintptr(p) + 4))), nil
+}
+
+func (c *Cabinet) hashWriteCounts(hashPtr Ptr, count, used int) error {
+	p, err := c.hashField(hashPtr, 0)
+	if err != nil { return err }
+	*(*int32)(p) = int32(count)
+	*(*int32)(unsafe.Pointer(uintptr(p) + 4)) = int32(used)
+	return nil
+}
+
+func (c *Cabinet) hashCheckWritable(hashPtr Ptr) error {
+	p, err := c.hashField(hashPtr, 16)
+	if err != nil { return err }
+	if *(*int32)(p)&hashFlagReadOnly != 0 {
+		return ErrReadOnlyHash
+	}
+	return nil
+}
+
+// hashView is a table with its capacities and segment pointers read out, so
+// that an operation resolves each segment once instead of once per probe.
+type hashView struct {
+	table    Ptr
+	slotCap  int
+	entryCap int
+	segments []Ptr // Slot segments, then entry segments
+}
+
+func (c *Cabinet) hashView(hashPtr Ptr) (*hashView, error) {
+	p, err := c.hashField(hashPtr, 8)
+	if err != nil { return nil, err }
+	return c.hashTableView(*(*Ptr)(p))
+}
+
+func (c *Cabinet) hashTableView(table Ptr) (*hashView, error) {
+	raw, err := c.resolve(table)
+	if err != nil { return nil, err }
+	base := uintptr(raw) + uintptr(HeaderSize)
+
+	v := &hashView{
+		table:    table,
+		slotCap:  int(*(*int32)(unsafe.Pointer(base))),
+		entryCap: int(*(*int32)(unsafe.Pointer(base + 4))),
+	}
+	segs := v.slotSegments() + hashSegmentsFor(v.entryCap, hashEntriesPerSegment)
+	v.segments = make([]Ptr, segs)
+	for i := range v.segments {
+		v.segments[i] = *(*Ptr)(unsafe.Pointer(base + 8 + uintptr(i*8)))
+	}
+	return v, nil
+}
+
+func (v *hashView) slotSegments() int {
+	return hashSegmentsFor(v.slotCap, hashSlotsPerSegment)
+}
+
+// hashSlotSegment resolves slot segment i and returns the address of its
+// first slot.
+func (c *Cabinet) hashSlotSegment(v *hashView, i int) (uintptr, error) {
+	raw, err := c.resolve(v.segments[i])
+	if err != nil { return 0, err }
+	return uintptr(raw) + uintptr(HeaderSize), nil
+}
+
+// hashEntrySegment resolves entry segment i and returns the address of its
+// first entry.
+func (c *Cabinet) hashEntrySegment(v *hashView, i int) (uintptr, error) {
+	raw, err := c.resolve(v.segments[v.slotSegments()+i])
 	if err != nil { return 0, err }
+	return uintptr(raw) + uintptr(HeaderSize) + 8, nil
+}
+
+// hashEntry returns the address of an entry: [uint64 Hash][Ptr Key][Ptr Value].
+func (c *Cabinet) hashEntry(v *hashView, entry int) (uintptr, error) {
+	base, err := c.hashEntrySegment(v, entry/hashEntriesPerSegment)
+	if err != nil { return 0, err }
+	return base + uintptr(entry%hashEntriesPerSegment*hashEntrySize), nil
+}
+
+func (c *Cabinet) hashWriteEntry(v *hashView, entry int, h uint64, key, value Ptr) error {
+	p, err := c.hashEntry(v, entry)
+	if err != nil { return err }
+	*(*uint64)(unsafe.Pointer(p)) = h
+	*(*Ptr)(unsafe.Pointer(p + 8)) = key
+	*(*Ptr)(unsafe.Pointer(p + 16)) = value
+	return nil
+}
+
+func (c *Cabinet) hashWriteSlot(v *hashView, slot int, value int32, h uint64) error {
+	base, err := c.hashSlotSegment(v, slot/hashSlotsPerSegment)
+	if err != nil { return err }
+	p := base + uintptr(slot%hashSlotsPerSegment*hashSlotSize)
+	*(*int32)(unsafe.Pointer(p)) = value
+	*(*uint32)(unsafe.Pointer(p + 4)) = hashSlotTag(h)
+	return nil
+}
+
+// hashSlotTag is the part of a hash kept beside its slot, so that probing
+// only reads entries whose hash is likely to match.
+func hashSlotTag(h uint64) uint32 {
+	return uint32((h &^ hashEntryReadOnly) >> 32)
+}
+
+// hashScanEntries calls visit for each live entry in [from, to), in order,
+// until it returns false. visit must not touch the heap.
+func (c *Cabinet) hashScanEntries(v *hashView, from, to int, visit func(i int, h uint64, key, value Ptr) bool) error {
+	for i := from; i < to; {
+		seg := i / hashEntriesPerSegment
+		base, err := c.hashEntrySegment(v, seg)
+		if err != nil { return err }
+
+		end := min(to, (seg+1)*hashEntriesPerSegment)
+		for ; i < end; i++ {
+			p := base + uintptr(i%hashEntriesPerSegment*hashEntrySize)
+			k := *(*Ptr)(unsafe.Pointer(p + 8))
+			if k == NilPtr {
+				continue
+			}
+			if !visit(i, *(*uint64)(unsafe.Pointer(p)), k, *(*Ptr)(unsafe.Pointer(p + 16))) {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// hashFind probes for `key`. It returns the entry index (-1 if absent) and the
+// slot where the key lives or should be inserted (first deleted slot wins).
+func (c *Cabinet) hashFind(v *hashView, key Ptr, k hashKeyInfo) (int, int, error) {
+	mask := v.slotCap - 1
+	tag := hashSlotTag(k.hash)
+	insertAt := -1
+
+	seg, base := -1, uintptr(0)
+	for i := int(k.hash) & mask; ; i = (i + 1) & mask {
+		if i/hashSlotsPerSegment != seg {
+			seg = i / hashSlotsPerSegment
+			var err error
+			base, err = c.hashSlotSegment(v, seg)
+			if err != nil { return 0, -1, err }
+		}
+		p := base + uintptr(i%hashSlotsPerSegment*hashSlotSize)
+		s := *(*int32)(unsafe.Pointer(p))
+
+		if s == hashSlotEmpty {
+			if insertAt < 0 {
+				insertAt = i
+			}
+			return insertAt, -1, nil
+		}
+		if s == hashSlotDeleted {
+			if insertAt < 0 {
+				insertAt = i
+			}
+			continue
+		}
+		if *(*uint32)(unsafe.Pointer(p + 4)) != tag {
+			continue
+		}
+
+		entry := int(s - 1)
+		eq, err := c.hashEntryHasKey(v, entry, key, k)
+		if err != nil { return 0, -1, err }
+		if eq {
+			return i, entry, nil
+		}
+		seg = -1 // Comparing resolved other objects, which may have paged the slots out
+	}
+}
+
+// hashEntryHasKey reports whether entry holds a key equal to `key`.
+func (c *Cabinet) hashEntryHasKey(v *hashView, entry int, key Ptr, k hashKeyInfo) (bool, error) {
+	p, err := c.hashEntry(v, entry)
+	if err != nil { return false, err }
+	if *(*uint64)(unsafe.Pointer(p))&^hashEntryReadOnly != k.hash {
+		return false, nil
+	}
+	stored := *(*Ptr)(unsafe.Pointer(p + 8))
+	if stored == key {
+		return true, nil
+	}
+
+	tag, bits, str, err := c.hashKeyValue(stored)
+	if err != nil { return false, err }
+	return tag == k.tag && bits == k.bits && str == k.str, nil
+}
+
+// hashRehash rebuilds the table, dropping removed entries. The slot capacity
+// doubles unless compaction alone frees enough room. The entries are placed
+// in Go memory first, so every segment is resolved once.
+func (c *Cabinet) hashRehash(hashPtr Ptr, old *hashView, count int) (*hashView, error) {
+	newSlots := old.slotCap
+	if count >= old.entryCap/2 {
+		newSlots = old.slotCap * 2
+	}
+
+	hashes := make([]uint64, 0, count)
+	keys := make([]Ptr, 0, count)
+	values := make([]Ptr, 0, count)
+	err := c.hashScanEntries(old, 0, old.entryCap, func(_ int, h uint64, k, val Ptr) bool {
+		hashes = append(hashes, h)
+		keys = append(keys, k)
+		values = append(values, val)
+		return true
+	})
+	if err != nil { return nil, err }
+
+	table, err := c.allocHashTable(newSlots)
+	if err != nil { return nil, err }
+
+	v, err := c.hashTableView(table)
+	if err != nil { return nil, err }
+
+	mask := newSlots - 1
+	slots := make([]int32, newSlots)
+	for i, h := range hashes {
+		s := int(h) & mask
+		for slots[s] != hashSlotEmpty {
+			s = (s + 1) & mask
+		}
+		slots[s] = int32(i + 1)
+	}
+
+	for seg := 0; seg < v.slotSegments(); seg++ {
+		base, err := c.hashSlotSegment(v, seg)
+		if err != nil { return nil, err }
+		end := min(newSlots, (seg+1)*hashSlotsPerSegment)
+		for s := seg * hashSlotsPerSegment; s < end; s++ {
+			if slots[s] == hashSlotEmpty {
+				continue
+			}
+			p := base + uintptr(s%hashSlotsPerSegment*hashSlotSize)
+			*(*int32)(unsafe.Pointer(p)) = slots[s]
+			*(*uint32)(unsafe.Pointer(p + 4)) = hashSlotTag(hashes[slots[s]-1])
+		}
+	}
+
+	for i := 0; i < len(hashes); {
+		seg := i / hashEntriesPerSegment
+		base, err := c.hashEntrySegment(v, seg)
+		if err != nil { return nil, err }
+		end := min(len(hashes), (seg+1)*hashEntriesPerSegment)
+		for ; i < end; i++ {
+			p := base + uintptr(i%hashEntriesPerSegment*hashEntrySize)
+			*(*uint64)(unsafe.Pointer(p)) = hashes[i]
+			*(*Ptr)(unsafe.Pointer(p + 8)) = keys[i]
+			*(*Ptr)(unsafe.Pointer(p + 16)) = values[i]
+		}
+	}
+
+	p, err := c.hashField(hashPtr, 8)
+	if err != nil { return nil, err }
+	*(*Ptr)(p) = table
+
+	if err := c.hashWriteCounts(hashPtr, len(hashes), len(hashes)); err != nil { return nil, err }
+	return v, nil
+}
+
+// hashKeyValue extracts the comparable value of a key.
+func (c *Cabinet) hashKeyValue(key Ptr) (TypeTag, uint64, string, error) {
+	raw, err := c.resolve(key)
+	if err != nil { return 0, 0, "", err }
+
+	header := (*Header)(raw)
+	payload := unsafe.Pointer(uintptr(raw) + uintptr(HeaderSize))
+
+	switch header.Type {
+	case TagInteger:
+		return TagInteger, uint64(*(*int64)(payload)), "", nil
+	case TagFloat:
+		f := *(*float64)(payload)
+		if f == 0 {
+			f = 0 // Normalize -0
+		}
+		return TagFloat, math.Float64bits(f), "", nil
+	case TagBoolean:
+		return TagBoolean, uint64(*(*int8)(payload)), "", nil
+	case TagNull:
+		return TagNull, 0, "", nil
+	case TagString:
+		length := int(*(*int32)(payload))
+		if length == 0 {
+			return TagString, 0, "", nil
+		}
+		data := unsafe.Slice((*byte)(unsafe.Pointer(uintptr(payload)+4)), length)
+		return TagString, 0, string(data), nil
+	case TagBigInt:
+		// Never equal to an int64 key: BigInts only hold values outside that range
+		return TagBigInt, 0, readBigInt(raw).String(), nil
+	case TagDecimal:
+		// Equal decimals hash alike whatever their scale: 1.50 and 1.5 are one key
+		unscaled, scale := readDecimal(raw)
+		ten := big.NewInt(10)
+		for scale > 0 {
+			q, m := new(big.Int).QuoRem(unscaled, ten, new(big.Int))
+			if m.Sign() != 0 { break }
+			unscaled, scale = q, scale-1
+		}
+		return TagDecimal, 0, fmt.Sprintf("%se-%d", unscaled, scale), nil
+	}
+	return header.Type, 0, "", ErrUnhashableKey
+}
+
+// hashKeyInfo is the comparable value of a key with its hash, read once per
+// operation.
+type hashKeyInfo struct {
+	tag  TypeTag
+	bits uint64
+	str  string
+	hash uint64
+}
+
+// hashKey hashes a key by value (FNV-1a for strings, big integers and decimals,
+// splitmix64 otherwise).
+func (c *Cabinet) hashKey(key Ptr) (hashKeyInfo, error) {
+	tag, bits, str, err := c.hashKeyValue(key)
+	if err != nil { return hashKeyInfo{}, err }
+
+	x := bits
+	if tag == TagString || tag == TagBigInt || tag == TagDecimal {
+		h := uint64(14695981039346656037)
+		for i := 0; i < len(str); i++ {
+			h ^= uint64(str[i])
+			h *= 1099511628211
+		}
+		x = h
+	}
 
-	countPtr := unsafe.Pointer(uintptr(raw) + uintptr(HeaderSize))
-	return int(*(*int32)(countPtr)), nil
+	x += uint64(tag) * 0x9E3779B97F4A7C15
+	x = (x ^ (x >> 30)) * 0xBF58476D1CE4E5B9
+	x = (x ^ (x >> 27)) * 0x94D049BB133111EB
+	x ^= x >> 31
+	return hashKeyInfo{tag: tag, bits: bits, str: str, hash: x &^ hashEntryReadOnly}, nil
 }
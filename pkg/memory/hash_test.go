@@ -0,0 +1,305 @@
+package memory
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestHashGrowAndDelete(t *testing.T) {
+	InitCabinet()
+
+	h, err := AllocHash(0)
+	if err != nil { t.Fatalf("AllocHash failed: %v", err) }
+
+	for i := 0; i < 200; i++ {
+		k, _ := AllocString(fmt.Sprintf("k%d", i))
+		v, _ := AllocInteger(int64(i))
+		if err := HashSet(h, k, v); err != nil {
+			t.Fatalf("HashSet %d failed: %v", i, err)
+		}
+	}
+
+	for i := 0; i < 200; i += 2 {
+		k, _ := AllocString(fmt.Sprintf("k%d", i))
+		removed, err := HashDelete(h, k)
+		if err != nil || !removed {
+			t.Fatalf("HashDelete %d failed: removed=%v err=%v", i, removed, err)
+		}
+	}
+
+	count, _ := ReadHashCount(h)
+	if count != 100 {
+		t.Fatalf("expected 100 entries, got %d", count)
+	}
+
+	for i := 0; i < 200; i++ {
+		k, _ := AllocString(fmt.Sprintf("k%d", i))
+		v, found, err := HashGet(h, k)
+		if err != nil { t.Fatalf("HashGet %d failed: %v", i, err) }
+		if found != (i%2 == 1) {
+			t.Fatalf("key k%d: expected found=%v", i, i%2 == 1)
+		}
+		if found {
+			val, _ := ReadInteger(v)
+			if val != int64(i) {
+				t.Errorf("key k%d: expected %d, got %d", i, i, val)
+			}
+		}
+	}
+
+	// Insertion order survives compaction
+	keys, _, _ := ReadHashPairs(h)
+	first, _ := ReadString(keys[0])
+	last, _ := ReadString(keys[len(keys)-1])
+	if first != "k1" || last != "k199" {
+		t.Errorf("unexpected order: first=%s last=%s", first, last)
+	}
+}
+
+// hashIterate walks h with HashNext, calling step with each key before
+// moving on, and returns the keys in the order they were visited.
+func hashIterate(t *testing.T, h Ptr, step func(key string)) []string {
+	var visited []string
+	for cursor := 0; ; {
+		k, _, next, err := HashNext(h, cursor)
+		if err != nil { t.Fatalf("HashNext failed: %v", err) }
+		if next < 0 { return visited }
+		key, _ := ReadString(k)
+		visited = append(visited, key)
+		step(key)
+		cursor = next
+	}
+}
+
+func TestHashNextDuringMutation(t *testing.T) {
+	InitCabinet()
+
+	set := func(h Ptr, key string) {
+		k, _ := AllocString(key)
+		if err := HashSet(h, k, NilPtr); err != nil { t.Fatalf("HashSet %s failed: %v", key, err) }
+	}
+	del := func(h Ptr, key string) {
+		k, _ := AllocString(key)
+		if _, err := HashDelete(h, k); err != nil { t.Fatalf("HashDelete %s failed: %v", key, err) }
+	}
+
+	// Growing a hash without removed entries keeps the cursor valid, and
+	// entries added during the walk are visited too
+	h, _ := AllocHash(0)
+	for i := 0; i < 4; i++ {
+		set(h, fmt.Sprintf("k%d", i))
+	}
+	n := 4
+	visited := hashIterate(t, h, func(string) {
+		if n < 100 {
+			set(h, fmt.Sprintf("k%d", n))
+			n++
+		}
+	})
+	if len(visited) != 100 {
+		t.Fatalf("expected 100 keys, got %d", len(visited))
+	}
+	for i, key := range visited {
+		if key != fmt.Sprintf("k%d", i) {
+			t.Fatalf("visit %d: expected k%d, got %s", i, i, key)
+		}
+	}
+
+	// Deleting leaves the other entries in place; a removed key that was
+	// not visited yet is skipped
+	visited = hashIterate(t, h, func(key string) {
+		if key == "k10" {
+			del(h, "k10")
+			del(h, "k11")
+		}
+	})
+	if len(visited) != 99 || visited[10] != "k10" || visited[11] != "k12" {
+		t.Fatalf("unexpected walk after delete: %d keys, %v", len(visited), visited[9:13])
+	}
+
+	// Unsupported: growing after a delete compacts the entries, so the
+	// cursor lands past entries that were never visited
+	h, _ = AllocHash(0)
+	for i := 0; i < 8; i++ {
+		set(h, fmt.Sprintf("k%d", i))
+	}
+	del(h, "k0")
+	del(h, "k1")
+	visited = hashIterate(t, h, func(key string) {
+		if key == "k4" {
+			for i := 8; i < 64; i++ {
+				set(h, fmt.Sprintf("k%d", i))
+			}
+		}
+	})
+	if len(visited) >= 62 {
+		t.Fatalf("expected compaction to skip entries, visited all %d", len(visited))
+	}
+}
+
+func TestHashSurvivesGC(t *testing.T) {
+	InitCabinet()
+
+	h, _ := AllocHash(0)
+	for i := 0; i < 50; i++ {
+		k, _ := AllocInteger(int64(i))
+		v, _ := AllocInteger(int64(i * 10))
+		HashSet(h, k, v)
+	}
+	// Garbage that should be collected
+	for i := 0; i < 100; i++ {
+		AllocString("sampah")
+	}
+
+	roots := []*Ptr{&h}
+	if err := Lemari.MarkAndCompact(roots); err != nil {
+		t.Fatalf("GC failed: %v", err)
+	}
+
+	key, _ := AllocInteger(42)
+	v, found, err := HashGet(h, key)
+	if err != nil || !found {
+		t.Fatalf("key 42 lost after GC: found=%v err=%v", found, err)
+	}
+	val, _ := ReadInteger(v)
+	if val != 420 {
+		t.Errorf("expected 420, got %d", val)
+	}
+}
+
+func TestHashSpansTrays(t *testing.T) {
+	InitCabinet()
+
+	// Far more entries than one Tray holds, over several slot segments
+	const n = 10000
+	h, _ := AllocHash(0)
+	for i := 0; i < n; i++ {
+		k, _ := AllocInteger(int64(i))
+		v, _ := AllocInteger(int64(i * 2))
+		if err := HashSet(h, k, v); err != nil {
+			t.Fatalf("HashSet %d failed: %v", i, err)
+		}
+	}
+	for i := 0; i < n; i += 3 {
+		k, _ := AllocInteger(int64(i))
+		if removed, err := HashDelete(h, k); err != nil || !removed {
+			t.Fatalf("HashDelete %d failed: removed=%v err=%v", i, removed, err)
+		}
+	}
+
+	roots := []*Ptr{&h}
+	if err := Lemari.MarkAndCompact(roots); err != nil {
+		t.Fatalf("GC failed: %v", err)
+	}
+
+	count, _ := ReadHashCount(h)
+	if count != n-(n+2)/3 {
+		t.Fatalf("expected %d entries, got %d", n-(n+2)/3, count)
+	}
+	for i := 0; i < n; i++ {
+		k, _ := AllocInteger(int64(i))
+		v, found, err := HashGet(h, k)
+		if err != nil { t.Fatalf("HashGet %d failed: %v", i, err) }
+		if found != (i%3 != 0) {
+			t.Fatalf("key %d: expected found=%v", i, i%3 != 0)
+		}
+		if found {
+			val, _ := ReadInteger(v)
+			if val != int64(i*2) {
+				t.Fatalf("key %d: expected %d, got %d", i, i*2, val)
+			}
+		}
+	}
+
+	// n-1 was removed, so n-2 is the last key inserted
+	keys, _, _ := ReadHashPairs(h)
+	last, _ := ReadInteger(keys[len(keys)-1])
+	if last != n-2 {
+		t.Errorf("insertion order lost: last key %d", last)
+	}
+}
+
+func TestHashUnhashableKey(t *testing.T) {
+	InitCabinet()
+
+	h, _ := AllocHash(0)
+	arr, _ := AllocArray(0, 0)
+	if err := HashSet(h, arr, arr); err != ErrUnhashableKey {
+		t.Errorf("expected ErrUnhashableKey, got %v", err)
+	}
+}
+
+func TestFreezeHashKey(t *testing.T) {
+	InitCabinet()
+
+	h, _ := AllocHash(0)
+	for i := 0; i < 20; i++ {
+		k, _ := AllocInteger(int64(i))
+		HashSet(h, k, k)
+	}
+	frozen, _ := AllocInteger(7)
+	if err := FreezeHashKey(h, frozen); err != nil {
+		t.Fatalf("FreezeHashKey failed: %v", err)
+	}
+
+	v, _ := AllocInteger(70)
+	if err := HashSet(h, frozen, v); err != ErrReadOnlyKey {
+		t.Errorf("expected ErrReadOnlyKey on set, got %v", err)
+	}
+	if _, err := HashDelete(h, frozen); err != ErrReadOnlyKey {
+		t.Errorf("expected ErrReadOnlyKey on delete, got %v", err)
+	}
+
+	// Other keys stay writable, and the flag survives growing the table
+	for i := 20; i < 200; i++ {
+		k, _ := AllocInteger(int64(i))
+		if err := HashSet(h, k, k); err != nil {
+			t.Fatalf("HashSet %d failed: %v", i, err)
+		}
+	}
+	if err := HashSet(h, frozen, v); err != ErrReadOnlyKey {
+		t.Errorf("expected ErrReadOnlyKey after rehash, got %v", err)
+	}
+	got, found, _ := HashGet(h, frozen)
+	if val, _ := ReadInteger(got); !found || val != 7 {
+		t.Errorf("frozen key lost its value: found=%v value=%d", found, val)
+	}
+}
+
+func TestHashStaysOutOfSwap(t *testing.T) {
+	InitCabinet()
+
+	// A hash of a few thousand entries among more garbage than RAM holds
+	const n = 5000
+	h, _ := AllocHash(0)
+	for i := 0; i < n; i++ {
+		k, _ := AllocString(fmt.Sprintf("kunci%d", i))
+		v, _ := AllocInteger(int64(i))
+		if err := HashSet(h, k, v); err != nil {
+			t.Fatalf("HashSet %d failed: %v", i, err)
+		}
+	}
+	for i := 0; i < 20000; i++ {
+		AllocString("sampah")
+	}
+
+	before := Swap.Written()
+	for i := 0; i < n; i++ {
+		k, _ := AllocInteger(int64(i))
+		if err := HashSet(h, k, k); err != nil {
+			t.Fatalf("HashSet %d failed: %v", i, err)
+		}
+	}
+	for i := 0; i < n; i++ {
+		k, _ := AllocString(fmt.Sprintf("kunci%d", i))
+		if _, found, err := HashGet(h, k); err != nil || !found {
+			t.Fatalf("HashGet %d failed: found=%v err=%v", i, found, err)
+		}
+	}
+
+	// Operations resolve each segment once, so the table mostly stays
+	// resident; re-resolving per probe used to spill gigabytes here
+	if written := Swap.Written() - before; written > 128*DRAWER_SIZE {
+		t.Errorf("hash operations spilled %d KB to swap", written/1024)
+	}
+}
@@ -0,0 +1,61 @@
+package memory
+
+import (
+	"unsafe"
+)
+
+// AllocIterator allocates an Iterator over a collection (used by `untuk`).
+// Layout: [Header][Ptr Collection][int64 Cursor][int64 Index]
+// Cursor is the collection-specific position, Index counts yielded items.
+func AllocIterator(collection Ptr) (Ptr, error) {
+	size := HeaderSize + 24
+
+	Lemari.mu.Lock()
+	defer Lemari.mu.Unlock()
+
+	ptr, err := Lemari.alloc(size)
+	if err != nil { return NilPtr, err }
+
+	raw, err := Lemari.resolve(ptr)
+	if err != nil { return NilPtr, err }
+
+	header := (*Header)(raw)
+	header.Type = TagIterator
+	header.Size = uint32(size)
+
+	base := uintptr(raw) + uintptr(HeaderSize)
+	*(*Ptr)(unsafe.Pointer(base)) = collection
+	*(*int64)(unsafe.Pointer(base + 8)) = 0
+	*(*int64)(unsafe.Pointer(base + 16)) = 0
+
+	return ptr, nil
+}
+
+// ReadIterator returns the collection, cursor and yielded count.
+func ReadIterator(ptr Ptr) (Ptr, int64, int64, error) {
+	Lemari.mu.Lock()
+	defer Lemari.mu.Unlock()
+
+	raw, err := Lemari.resolve(ptr)
+	if err != nil { return NilPtr, 0, 0, err }
+
+	base := uintptr(raw) + uintptr(HeaderSize)
+	collection := *(*Ptr)(unsafe.Pointer(base))
+	cursor := *(*int64)(unsafe.Pointer(base + 8))
+	index := *(*int64)(unsafe.Pointer(base + 16))
+	return collection, cursor, index, nil
+}
+
+// WriteIteratorCursor advances the iterator state.
+func WriteIteratorCursor(ptr Ptr, cursor int64, index int64) error {
+	Lemari.mu.Lock()
+	defer Lemari.mu.Unlock()
+
+	raw, err := Lemari.resolve(ptr)
+	if err != nil { return err }
+
+	base := uintptr(raw) + uintptr(HeaderSize)
+	*(*int64)(unsafe.Pointer(base + 8)) = cursor
+	*(*int64)(unsafe.Pointer(base + 16)) = index
+	return nil
+}
@@ -30,6 +30,38 @@ func TestAllocCompiledFunction(t *testing.T) {
 	}
 }
 
+func TestAllocFunctionWithArity(t *testing.T) {
+	InitCabinet()
+
+	instr := []byte{0x01, 0x02}
+	ptr, err := AllocFunctionWithArity(instr, 4, 3, 1, true, NilPtr)
+	if err != nil {
+		t.Fatalf("Alloc failed: %v", err)
+	}
+
+	readInstr, locals, params, err := ReadCompiledFunction(ptr)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if locals != 4 || params != 3 || !bytes.Equal(readInstr, instr) {
+		t.Errorf("Meta mismatch: locals=%d params=%d instr=%v", locals, params, readInstr)
+	}
+
+	minParams, variadic, err := ReadCompiledFunctionArity(ptr)
+	if err != nil {
+		t.Fatalf("Read arity failed: %v", err)
+	}
+	if minParams != 1 || !variadic {
+		t.Errorf("Arity: want 1 variadic, got %d %v", minParams, variadic)
+	}
+
+	// Plain functions require every parameter
+	ptr, _ = AllocCompiledFunction(instr, 2, 2)
+	if minParams, variadic, _ := ReadCompiledFunctionArity(ptr); minParams != 2 || variadic {
+		t.Errorf("Arity: want 2 fixed, got %d %v", minParams, variadic)
+	}
+}
+
 func TestAllocClosure(t *testing.T) {
 	InitCabinet()
 
@@ -26,6 +26,14 @@ const (
 	TagUpvalue  TypeTag = 16
 	TagStruct   TypeTag = 17
 	TagSchema   TypeTag = 18
+	TagHashTable TypeTag = 19 // Internal backing store of TagHash
+	TagIterator  TypeTag = 20
+	TagVariant   TypeTag = 21 // Instance of a `pilihan` variant, laid out like TagStruct
+	TagBigInt    TypeTag = 22 // Integer outside the int64 range
+	TagDecimal   TypeTag = 23 // Exact decimal: unscaled integer and scale
+	TagRange     TypeTag = 24 // Integer range `a..b` or `a..<b`
+	TagHashSlots   TypeTag = 25 // Slot segment of a TagHashTable
+	TagHashEntries TypeTag = 26 // Entry segment of a TagHashTable
 )
 
 // Header is the metadata for every object in our heap.
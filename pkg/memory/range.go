@@ -0,0 +1,46 @@
+package memory
+
+import "unsafe"
+
+// AllocRange allocates an integer range in the Cabinet.
+// Layout: [Header][int64 Start][int64 End][int64 Exclusive]
+func AllocRange(start, end int64, exclusive bool) (Ptr, error) {
+	totalSize := HeaderSize + 24
+
+	Lemari.mu.Lock()
+	defer Lemari.mu.Unlock()
+
+	ptr, err := Lemari.alloc(totalSize)
+	if err != nil { return NilPtr, err }
+
+	raw, err := Lemari.resolve(ptr)
+	if err != nil { return NilPtr, err }
+
+	header := (*Header)(raw)
+	header.Type = TagRange
+	header.Size = uint32(totalSize)
+
+	base := uintptr(raw) + uintptr(HeaderSize)
+	*(*int64)(unsafe.Pointer(base)) = start
+	*(*int64)(unsafe.Pointer(base + 8)) = end
+	flag := int64(0)
+	if exclusive { flag = 1 }
+	*(*int64)(unsafe.Pointer(base + 16)) = flag
+
+	return ptr, nil
+}
+
+// ReadRange reads the bounds of a Range object.
+func ReadRange(ptr Ptr) (start, end int64, exclusive bool, err error) {
+	Lemari.mu.Lock()
+	defer Lemari.mu.Unlock()
+
+	raw, err := Lemari.resolve(ptr)
+	if err != nil { return 0, 0, false, err }
+
+	base := uintptr(raw) + uintptr(HeaderSize)
+	start = *(*int64)(unsafe.Pointer(base))
+	end = *(*int64)(unsafe.Pointer(base + 8))
+	exclusive = *(*int64)(unsafe.Pointer(base + 16)) == 1
+	return start, end, exclusive, nil
+}
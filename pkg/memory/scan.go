@@ -28,13 +28,29 @@ func Scan(ptr Ptr) ([]*Ptr, error) {
 		}
 
 	case TagHash:
-		// Layout: [Count(4)][Key0][Val0]...
-		countPtr := unsafe.Pointer(base)
-		count := int(*(*int32)(countPtr))
-		elementsStart := base + 4
-		for i := 0; i < count; i++ {
-			k := (*Ptr)(unsafe.Pointer(elementsStart + uintptr(i*16)))
-			v := (*Ptr)(unsafe.Pointer(elementsStart + uintptr(i*16) + 8))
+		// Layout: [Count(4)][Used(4)][TablePtr(8)][Flags(4)]
+		tablePtr := (*Ptr)(unsafe.Pointer(base + 8))
+		children = append(children, tablePtr)
+
+	case TagHashTable:
+		// Layout: [SlotCap(4)][EntryCap(4)][SlotSegments(8)...][EntrySegments(8)...]
+		slotCap := int(*(*int32)(unsafe.Pointer(base)))
+		entryCap := int(*(*int32)(unsafe.Pointer(base + 4)))
+		segs := hashSegmentsFor(slotCap, hashSlotsPerSegment) + hashSegmentsFor(entryCap, hashEntriesPerSegment)
+		for i := 0; i < segs; i++ {
+			children = append(children, (*Ptr)(unsafe.Pointer(base+8+uintptr(i*8))))
+		}
+
+	case TagHashEntries:
+		// Layout: [Cap(4)][Unused(4)][Hash(8) Key(8) Val(8)]...
+		entryCap := int(*(*int32)(unsafe.Pointer(base)))
+		entriesStart := base + 8
+		for i := 0; i < entryCap; i++ {
+			k := (*Ptr)(unsafe.Pointer(entriesStart + uintptr(i*hashEntrySize) + 8))
+			if *k == NilPtr {
+				continue // Empty or removed entry
+			}
+			v := (*Ptr)(unsafe.Pointer(entriesStart + uintptr(i*hashEntrySize) + 16))
 			children = append(children, k, v)
 		}
 
@@ -52,12 +68,37 @@ func Scan(ptr Ptr) ([]*Ptr, error) {
 			children = append(children, p)
 		}
 
+	case TagCompiledFunction:
+		// Layout: [NumLocals(4)][NumParams(4)][MinParams(4)][Variadic(4)][ParamNames(8)]...
+		namesPtr := (*Ptr)(unsafe.Pointer(base + 16))
+		children = append(children, namesPtr)
+
 	case TagError:
 		// Layout: [MsgPtr(8)][CodePtr(8)]...
 		msgPtr := (*Ptr)(unsafe.Pointer(base))
 		codePtr := (*Ptr)(unsafe.Pointer(base + 8))
 		children = append(children, msgPtr, codePtr)
 
+	case TagIterator:
+		// Layout: [Collection(8)][Cursor(8)][Index(8)]
+		collPtr := (*Ptr)(unsafe.Pointer(base))
+		children = append(children, collPtr)
+
+	case TagSchema:
+		// Layout: [Name(8)][FieldNames(8)][Methods(8)]
+		namePtr := (*Ptr)(unsafe.Pointer(base))
+		fieldsPtr := (*Ptr)(unsafe.Pointer(base + 8))
+		methodsPtr := (*Ptr)(unsafe.Pointer(base + 16))
+		children = append(children, namePtr, fieldsPtr, methodsPtr)
+
+	case TagStruct, TagVariant:
+		// Layout: [Schema(8)][Fields...]
+		count := (int(header.Size) - HeaderSize) / 8
+		for i := 0; i < count; i++ {
+			p := (*Ptr)(unsafe.Pointer(base + uintptr(i*8)))
+			children = append(children, p)
+		}
+
 	case TagPointer:
 		// Layout: [Ptr(8)]
 		p := (*Ptr)(unsafe.Pointer(base))
@@ -68,6 +109,15 @@ func Scan(ptr Ptr) ([]*Ptr, error) {
 		expPtr := (*Ptr)(unsafe.Pointer(base + 8))
 		children = append(children, initPtr, expPtr)
 
+	case TagBigInt:
+		// Layout: [Sign(4)][Length(4)][Magnitude...], nothing to trace
+
+	case TagDecimal:
+		// Layout: [Scale(4)][Reserved(4)][Sign(4)][Length(4)][Magnitude...], nothing to trace
+
+	case TagRange:
+		// Layout: [Start(8)][End(8)][Exclusive(8)], nothing to trace
+
 	case TagUpvalue:
 		isOpenPtr := (*int64)(unsafe.Pointer(base + 16))
 		isOpen := *isOpenPtr == 1
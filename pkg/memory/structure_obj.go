@@ -5,11 +5,12 @@ import (
 	"unsafe"
 )
 
-// AllocSchema allocates a Schema object.
-// Layout: [Header][Ptr Name][Ptr FieldNames(Array)]
-func AllocSchema(name Ptr, fields Ptr) (Ptr, error) {
-	// Header + 8 (Name) + 8 (Fields)
-	payloadSize := 16
+// AllocSchema allocates a Schema object. methods is a Hash from method name
+// to Closure, or NilPtr when the struktur declares none.
+// Layout: [Header][Ptr Name][Ptr FieldNames(Array)][Ptr Methods(Hash)]
+func AllocSchema(name Ptr, fields Ptr, methods Ptr) (Ptr, error) {
+	// Header + 8 (Name) + 8 (Fields) + 8 (Methods)
+	payloadSize := 24
 	totalSize := HeaderSize + payloadSize
 
 	Lemari.mu.Lock()
@@ -33,6 +34,10 @@ func AllocSchema(name Ptr, fields Ptr) (Ptr, error) {
 	fieldsPtr := unsafe.Pointer(uintptr(raw) + uintptr(HeaderSize) + 8)
 	*(*Ptr)(fieldsPtr) = fields
 
+	// Write Methods
+	methodsPtr := unsafe.Pointer(uintptr(raw) + uintptr(HeaderSize) + 16)
+	*(*Ptr)(methodsPtr) = methods
+
 	return ptr, nil
 }
 
@@ -50,9 +55,31 @@ func ReadSchema(ptr Ptr) (name Ptr, fields Ptr, err error) {
 	return *(*Ptr)(namePtr), *(*Ptr)(fieldsPtr), nil
 }
 
+// ReadSchemaMethods reads the method table of a schema (NilPtr if it has none).
+func ReadSchemaMethods(ptr Ptr) (Ptr, error) {
+	Lemari.mu.Lock()
+	defer Lemari.mu.Unlock()
+
+	raw, err := Lemari.resolve(ptr)
+	if err != nil { return NilPtr, err }
+
+	methodsPtr := unsafe.Pointer(uintptr(raw) + uintptr(HeaderSize) + 16)
+	return *(*Ptr)(methodsPtr), nil
+}
+
 // AllocStruct allocates a Struct instance.
 // Layout: [Header][Ptr Schema][Ptr... Fields]
 func AllocStruct(schema Ptr, fieldCount int) (Ptr, error) {
+	return allocInstance(TagStruct, schema, fieldCount)
+}
+
+// AllocVariant allocates an instance of a `pilihan` variant. Its schema is
+// named "Pilihan.Varian"; fields are read and written like struct fields.
+func AllocVariant(schema Ptr, fieldCount int) (Ptr, error) {
+	return allocInstance(TagVariant, schema, fieldCount)
+}
+
+func allocInstance(tag TypeTag, schema Ptr, fieldCount int) (Ptr, error) {
 	payloadSize := 8 + (fieldCount * 8)
 	totalSize := HeaderSize + payloadSize
 
@@ -66,7 +93,7 @@ func AllocStruct(schema Ptr, fieldCount int) (Ptr, error) {
 	if err != nil { return NilPtr, err }
 
 	header := (*Header)(raw)
-	header.Type = TagStruct
+	header.Type = tag
 	header.Size = uint32(totalSize)
 
 	// Write Schema
@@ -12,6 +12,7 @@ type SwapSystem struct {
 	mu       sync.Mutex
 	file     *os.File
 	isActive bool
+	written  int64 // Bytes spilled since InitSwap
 }
 
 var Swap SwapSystem
@@ -21,7 +22,7 @@ func InitSwap() error {
 	defer Swap.mu.Unlock()
 
 	// 0600 = Readable/Writable by owner only (not user accessible generally)
-	f, err := os.OpenFile(SWAP_FILE, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	f, err := os.OpenFile(SWAP_FILE, os.O_RDWR|os.O_CREATE, 0600)
 	if err != nil {
 		return err
 	}
@@ -37,6 +38,7 @@ func InitSwap() error {
 
 	Swap.file = f
 	Swap.isActive = true
+	Swap.written = 0
 	return nil
 }
 
@@ -55,10 +57,11 @@ func (s *SwapSystem) Spill(data []byte) (int64, error) {
 	}
 	offset := info.Size()
 
-	_, err = s.file.Write(data)
+	_, err = s.file.WriteAt(data, offset)
 	if err != nil {
 		return -1, err
 	}
+	s.written += int64(len(data))
 
 	// Sync to ensure disk write
 	// s.file.Sync() // Optional, slow but safe
@@ -66,6 +69,31 @@ func (s *SwapSystem) Spill(data []byte) (int64, error) {
 	return offset, nil
 }
 
+// SpillAt overwrites data previously spilled at offset, so a Drawer that is
+// swapped out again reuses its place instead of growing the file.
+func (s *SwapSystem) SpillAt(offset int64, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.isActive {
+		return fmt.Errorf("swap not initialized")
+	}
+
+	_, err := s.file.WriteAt(data, offset)
+	if err != nil {
+		return err
+	}
+	s.written += int64(len(data))
+	return nil
+}
+
+// Written returns how many bytes were spilled since InitSwap.
+func (s *SwapSystem) Written() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.written
+}
+
 // Restore reads data from swap file at offset
 func (s *SwapSystem) Restore(offset int64, dest []byte) error {
 	s.mu.Lock()
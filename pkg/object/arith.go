@@ -0,0 +1,111 @@
+package object
+
+import (
+	"math"
+	"math/big"
+)
+
+// Shared arithmetic semantics for the VM and the evaluator.
+// `//` and `%` use floor semantics: the remainder takes the sign of the
+// divisor, so a == (a // b) * b + a % b always holds.
+
+// FloorDivInt performs integer floor division. b must not be zero.
+func FloorDivInt(a, b int64) int64 {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+// FloorModInt returns the floored remainder. b must not be zero.
+func FloorModInt(a, b int64) int64 {
+	m := a % b
+	if m != 0 && (m < 0) != (b < 0) {
+		m += b
+	}
+	return m
+}
+
+// FloorModFloat returns the floored remainder of two floats.
+func FloorModFloat(a, b float64) float64 {
+	m := math.Mod(a, b)
+	if m != 0 && (m < 0) != (b < 0) {
+		m += b
+	}
+	return m
+}
+
+// PowInt raises base to a non-negative exponent by repeated squaring.
+func PowInt(base, exp int64) int64 {
+	result := int64(1)
+	for exp > 0 {
+		if exp&1 == 1 {
+			result *= base
+		}
+		base *= base
+		exp >>= 1
+	}
+	return result
+}
+
+// AddInt, SubInt, MulInt and PowInt64 report ok=false when the result does
+// not fit in int64, so the caller can redo the operation with big.Int.
+func AddInt(a, b int64) (int64, bool) {
+	res := a + b
+	return res, (res > a) == (b > 0)
+}
+
+func SubInt(a, b int64) (int64, bool) {
+	res := a - b
+	return res, (res < a) == (b > 0)
+}
+
+func MulInt(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	res := a * b
+	if res/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, false
+	}
+	return res, true
+}
+
+// PowInt64 raises base to a non-negative exponent.
+func PowInt64(base, exp int64) (int64, bool) {
+	result := int64(1)
+	for exp > 0 {
+		var ok bool
+		if exp&1 == 1 {
+			if result, ok = MulInt(result, base); !ok {
+				return 0, false
+			}
+		}
+		exp >>= 1
+		if exp > 0 {
+			if base, ok = MulInt(base, base); !ok {
+				return 0, false
+			}
+		}
+	}
+	return result, true
+}
+
+// FloorDivBig performs floor division on big integers. b must not be zero.
+func FloorDivBig(a, b *big.Int) *big.Int {
+	q, m := new(big.Int).QuoRem(a, b, new(big.Int))
+	if m.Sign() != 0 && m.Sign() != b.Sign() {
+		q.Sub(q, big.NewInt(1))
+	}
+	return q
+}
+
+// FloorModBig returns the floored remainder of big integers. b must not be zero.
+func FloorModBig(a, b *big.Int) *big.Int {
+	m := new(big.Int).Rem(a, b)
+	if m.Sign() != 0 && m.Sign() != b.Sign() {
+		m.Add(m, b)
+	}
+	return m
+}
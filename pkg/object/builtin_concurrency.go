@@ -40,10 +40,37 @@ func init() {
 			return NewError(fmt.Sprintf("argument to `terima` must be CHANNEL, got %s", args[0].Type()), ErrCodeTypeMismatch, 0, 0)
 		}
 
-		val := <-chObj.Value
+		val, ok := <-chObj.Value
+		if !ok {
+			return NewNull()
+		}
 		return val
 	})
 
+	RegisterBuiltin("tutup_saluran", func(args ...Object) Object {
+		if len(args) != 1 {
+			return newArgumentError(len(args), 1)
+		}
+		chObj, ok := args[0].(*Channel)
+		if !ok {
+			return NewError(fmt.Sprintf("argument to `tutup_saluran` must be CHANNEL, got %s", args[0].Type()), ErrCodeTypeMismatch, 0, 0)
+		}
+
+		closed := func() (ok bool) {
+			defer func() {
+				if recover() != nil {
+					ok = false
+				}
+			}()
+			close(chObj.Value)
+			return true
+		}()
+		if !closed {
+			return NewError("channel already closed", ErrCodeRuntime, 0, 0)
+		}
+		return NewNull()
+	})
+
 	RegisterBuiltin("luncurkan", func(args ...Object) Object {
 		return NewError("luncurkan() requires VM context", ErrCodeSignalLaunch, 0, 0)
 	})
@@ -6,9 +6,12 @@ import (
 	"github.com/VzoelFox/morphlang/pkg/memory"
 	"io"
 	"math"
+	"math/big"
 	"os"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 func newArgumentError(got int, expected int) *Error {
@@ -43,8 +46,15 @@ func init() {
 			if err != nil { return NewError(err.Error(), ErrCodeRuntime, 0, 0) }
 			return NewInteger(int64(len))
 		case *String:
+			// Length in code points; panjang_byte counts bytes
 			val := arg.GetValue()
-			return NewInteger(int64(len(val)))
+			return NewInteger(int64(utf8.RuneCountInString(val)))
+		case *Hash:
+			count, err := memory.ReadHashCount(arg.Address)
+			if err != nil { return NewError(err.Error(), ErrCodeRuntime, 0, 0) }
+			return NewInteger(int64(count))
+		case *Range:
+			return NewInteger(arg.Len())
 		default:
 			return NewError(fmt.Sprintf("argument to `panjang` not supported, got %s", args[0].Type()), ErrCodeTypeMismatch, 0, 0)
 		}
@@ -67,6 +77,22 @@ func init() {
 		return NewArray(elements)
 	})
 
+	RegisterBuiltin("hapus_kunci", func(args ...Object) Object {
+		if len(args) != 2 {
+			return newArgumentError(len(args), 2)
+		}
+		hash, ok := args[0].(*Hash)
+		if !ok {
+			return NewError(fmt.Sprintf("argument to `hapus_kunci` must be HASH, got %s", args[0].Type()), ErrCodeTypeMismatch, 0, 0)
+		}
+
+		removed, err := memory.HashDelete(hash.Address, args[1].GetAddress())
+		if err == memory.ErrReadOnlyHash { return NewError("the variants of a pilihan cannot be removed", ErrCodeRuntime, 0, 0) }
+		if err == memory.ErrReadOnlyKey { return NewError("a constant cannot be removed", ErrCodeRuntime, 0, 0) }
+		if err != nil { return NewError(err.Error(), ErrCodeTypeMismatch, 0, 0) }
+		return NewBoolean(removed)
+	})
+
 	RegisterBuiltin("cetak", func(args ...Object) Object {
 		for _, arg := range args {
 			fmt.Println(arg.Inspect())
@@ -78,6 +104,14 @@ func init() {
 		if len(args) != 1 {
 			return newArgumentError(len(args), 1)
 		}
+		// Every variant of a pilihan reports the pilihan name, as in "Status"
+		if v, ok := args[0].(*Variant); ok {
+			return NewString(v.Enum())
+		}
+		// A BigInt is an integer that happens not to fit in int64
+		if _, ok := args[0].(*BigInt); ok {
+			return NewString(INTEGER_OBJ)
+		}
 		return NewString(string(args[0].Type()))
 	})
 
@@ -117,6 +151,18 @@ func init() {
 		}
 	})
 
+	RegisterBuiltin("kode_galat", func(args ...Object) Object {
+		if len(args) != 1 {
+			return newArgumentError(len(args), 1)
+		}
+		switch arg := args[0].(type) {
+		case *Error:
+			return NewString(arg.GetCode())
+		default:
+			return NewError(fmt.Sprintf("argument to `kode_galat` must be ERROR, got %s", args[0].Type()), ErrCodeTypeMismatch, 0, 0)
+		}
+	})
+
 	RegisterBuiltin("baca_file", func(args ...Object) Object {
 		if len(args) != 1 {
 			return newArgumentError(len(args), 1)
@@ -359,11 +405,17 @@ func init() {
 		if len(args) != 1 {
 			return newArgumentError(len(args), 1)
 		}
+		if b, ok := args[0].(*BigInt); ok {
+			return NewInt(new(big.Int).Abs(b.GetValue()))
+		}
 		num, ok := args[0].(*Integer)
 		if !ok {
 			return NewError(fmt.Sprintf("argument to `abs` must be INTEGER, got %s", args[0].Type()), ErrCodeTypeMismatch, 0, 0)
 		}
 		val := num.GetValue()
+		if val == math.MinInt64 {
+			return NewInt(new(big.Int).Neg(big.NewInt(val)))
+		}
 		if val < 0 {
 			return NewInteger(-val)
 		}
@@ -374,36 +426,113 @@ func init() {
 		if len(args) != 2 {
 			return newArgumentError(len(args), 2)
 		}
-		a, ok := args[0].(*Integer)
+		a, ok := getIntegerValue(args[0])
 		if !ok {
 			return NewError(fmt.Sprintf("first argument to `max` must be INTEGER, got %s", args[0].Type()), ErrCodeTypeMismatch, 0, 0)
 		}
-		b, ok := args[1].(*Integer)
+		b, ok := getIntegerValue(args[1])
 		if !ok {
 			return NewError(fmt.Sprintf("second argument to `max` must be INTEGER, got %s", args[1].Type()), ErrCodeTypeMismatch, 0, 0)
 		}
-		if a.GetValue() > b.GetValue() {
-			return a
+		if a.Cmp(b) > 0 {
+			return args[0]
 		}
-		return b
+		return args[1]
 	})
 
 	RegisterBuiltin("min", func(args ...Object) Object {
 		if len(args) != 2 {
 			return newArgumentError(len(args), 2)
 		}
-		a, ok := args[0].(*Integer)
+		a, ok := getIntegerValue(args[0])
 		if !ok {
 			return NewError(fmt.Sprintf("first argument to `min` must be INTEGER, got %s", args[0].Type()), ErrCodeTypeMismatch, 0, 0)
 		}
-		b, ok := args[1].(*Integer)
+		b, ok := getIntegerValue(args[1])
 		if !ok {
 			return NewError(fmt.Sprintf("second argument to `min` must be INTEGER, got %s", args[1].Type()), ErrCodeTypeMismatch, 0, 0)
 		}
-		if a.GetValue() < b.GetValue() {
-			return a
+		if a.Cmp(b) < 0 {
+			return args[0]
 		}
-		return b
+		return args[1]
+	})
+
+	RegisterBuiltin("desimal", func(args ...Object) Object {
+		if len(args) < 1 {
+			return newArgumentError(len(args), 1)
+		}
+		if len(args) > 3 {
+			return newArgumentError(len(args), 3)
+		}
+
+		var val DecimalValue
+		switch arg := args[0].(type) {
+		case *Decimal:
+			val = arg.GetValue()
+		case *Integer:
+			val = DecimalFromInt(big.NewInt(arg.GetValue()))
+		case *BigInt:
+			val = DecimalFromInt(arg.GetValue())
+		case *String, *Float:
+			text := arg.Inspect()
+			if f, ok := arg.(*Float); ok {
+				// Converting is explicit here: the shortest decimal that reads back as f
+				if math.IsInf(f.GetValue(), 0) || math.IsNaN(f.GetValue()) {
+					return NewError(fmt.Sprintf("cannot convert %s to DECIMAL", text), ErrCodeTypeMismatch, 0, 0)
+				}
+				text = strconv.FormatFloat(f.GetValue(), 'f', -1, 64)
+			} else {
+				text = arg.(*String).GetValue()
+			}
+			d, err := ParseDecimal(text)
+			if err != nil {
+				return NewError(err.Error(), ErrCodeTypeMismatch, 0, 0)
+			}
+			val = d
+		default:
+			return NewError(fmt.Sprintf("argument to `desimal` must be STRING, INTEGER, FLOAT or DECIMAL, got %s", args[0].Type()), ErrCodeTypeMismatch, 0, 0)
+		}
+		if len(args) == 1 {
+			return NewDecimal(val)
+		}
+
+		scale, mode, errObj := decimalRounding("desimal", args[1:])
+		if errObj != nil {
+			return errObj
+		}
+		return NewDecimal(val.Rescale(scale, mode))
+	})
+
+	// bagi_desimal rounds the exact quotient once, to the caller's scale and
+	// mode; `desimal(a / b, skala, mode)` would round the 16-digit `/` result
+	// a second time.
+	RegisterBuiltin("bagi_desimal", func(args ...Object) Object {
+		if len(args) < 3 || len(args) > 4 {
+			return newArgumentErrorRange(len(args), 3, 4)
+		}
+
+		var operands [2]DecimalValue
+		for i, arg := range args[:2] {
+			switch arg := arg.(type) {
+			case *Decimal:
+				operands[i] = arg.GetValue()
+			case *Integer:
+				operands[i] = DecimalFromInt(big.NewInt(arg.GetValue()))
+			case *BigInt:
+				operands[i] = DecimalFromInt(arg.GetValue())
+			default:
+				return NewError(fmt.Sprintf("arguments to `bagi_desimal` must be DECIMAL or INTEGER, got %s", arg.Type()), ErrCodeTypeMismatch, 0, 0)
+			}
+		}
+		scale, mode, errObj := decimalRounding("bagi_desimal", args[2:])
+		if errObj != nil {
+			return errObj
+		}
+		if operands[1].Unscaled.Sign() == 0 {
+			return NewError("decimal divide by zero", ErrCodeRuntime, 0, 0)
+		}
+		return NewDecimal(QuoDecimal(operands[0], operands[1], scale, mode))
 	})
 
 	RegisterBuiltin("pow", func(args ...Object) Object {
@@ -651,7 +780,12 @@ func init() {
 		if len(args) != 1 { return newArgumentError(len(args), 1) }
 		code, ok := args[0].(*Integer)
 		if !ok { return NewError(fmt.Sprintf("argument to `chr` must be INTEGER, got %s", args[0].Type()), ErrCodeTypeMismatch, 0, 0) }
-		return NewString(string(rune(code.GetValue())))
+
+		val := code.GetValue()
+		if val < 0 || val > utf8.MaxRune || !utf8.ValidRune(rune(val)) {
+			return NewError(fmt.Sprintf("invalid code point %d", val), ErrCodeRuntime, 0, 0)
+		}
+		return NewString(string(rune(val)))
 	})
 
 	RegisterBuiltin("ord", func(args ...Object) Object {
@@ -661,10 +795,83 @@ func init() {
 
 		val := str.GetValue()
 		if len(val) == 0 { return NewError("empty string", ErrCodeRuntime, 0, 0) }
-		r := []rune(val)[0]
+		r, _ := utf8.DecodeRuneInString(val)
 		return NewInteger(int64(r))
 	})
 
+	// Byte-oriented counterparts of the string builtins, for binary data
+	// that is not valid UTF-8.
+
+	RegisterBuiltin("panjang_byte", func(args ...Object) Object {
+		if len(args) != 1 { return newArgumentError(len(args), 1) }
+		str, ok := args[0].(*String)
+		if !ok { return NewError(fmt.Sprintf("argument to `panjang_byte` must be STRING, got %s", args[0].Type()), ErrCodeTypeMismatch, 0, 0) }
+		return NewInteger(int64(len(str.GetValue())))
+	})
+
+	RegisterBuiltin("ke_byte", func(args ...Object) Object {
+		if len(args) != 1 { return newArgumentError(len(args), 1) }
+		str, ok := args[0].(*String)
+		if !ok { return NewError(fmt.Sprintf("argument to `ke_byte` must be STRING, got %s", args[0].Type()), ErrCodeTypeMismatch, 0, 0) }
+
+		val := str.GetValue()
+		elements := make([]Object, len(val))
+		for i := 0; i < len(val); i++ {
+			elements[i] = NewInteger(int64(val[i]))
+		}
+		return NewArray(elements)
+	})
+
+	RegisterBuiltin("ke_array", func(args ...Object) Object {
+		if len(args) != 1 { return newArgumentError(len(args), 1) }
+
+		var elements []Object
+		switch arg := args[0].(type) {
+		case *Range:
+			n := arg.Len()
+			if n > int64(MaxArrayLength) {
+				return NewError(fmt.Sprintf("range %s has %d elements, more than an array can hold (%d)", arg.Inspect(), n, MaxArrayLength), ErrCodeRuntime, 0, 0)
+			}
+			start, _, _ := arg.Bounds()
+			elements = make([]Object, n)
+			for i := range elements {
+				elements[i] = NewInteger(start + int64(i))
+			}
+		case *String:
+			val := arg.GetValue()
+			for len(val) > 0 {
+				_, size := utf8.DecodeRuneInString(val)
+				elements = append(elements, NewString(val[:size]))
+				val = val[size:]
+			}
+		case *Array:
+			elements = arg.GetElements()
+		default:
+			return NewError(fmt.Sprintf("argument to `ke_array` must be RANGE, STRING or ARRAY, got %s", args[0].Type()), ErrCodeTypeMismatch, 0, 0)
+		}
+		return NewArray(elements)
+	})
+
+	RegisterBuiltin("dari_byte", func(args ...Object) Object {
+		if len(args) != 1 { return newArgumentError(len(args), 1) }
+		arr, ok := args[0].(*Array)
+		if !ok { return NewError(fmt.Sprintf("argument to `dari_byte` must be ARRAY, got %s", args[0].Type()), ErrCodeTypeMismatch, 0, 0) }
+
+		length, err := memory.ReadArrayLength(arr.Address)
+		if err != nil { return NewError(err.Error(), ErrCodeRuntime, 0, 0) }
+		data := make([]byte, length)
+		for i := 0; i < length; i++ {
+			elemPtr, err := memory.ReadArrayElement(arr.Address, i)
+			if err != nil { return NewError(err.Error(), ErrCodeRuntime, 0, 0) }
+			b, ok := FromPtr(elemPtr).(*Integer)
+			if !ok || b.GetValue() < 0 || b.GetValue() > 255 {
+				return NewError(fmt.Sprintf("element %d of `dari_byte` must be an INTEGER from 0 to 255", i), ErrCodeTypeMismatch, 0, 0)
+			}
+			data[i] = byte(b.GetValue())
+		}
+		return NewString(string(data))
+	})
+
 	RegisterBuiltin("baca_byte", func(args ...Object) Object {
 		if len(args) != 2 { return newArgumentError(len(args), 2) }
 		ptr, ok := args[0].(*Pointer)
@@ -698,10 +905,24 @@ func init() {
 
 // ...
 
+// getIntegerValue returns the value of an Integer or BigInt.
+func getIntegerValue(obj Object) (*big.Int, bool) {
+	switch val := obj.(type) {
+	case *Integer:
+		return big.NewInt(val.GetValue()), true
+	case *BigInt:
+		return val.GetValue(), true
+	default:
+		return nil, false
+	}
+}
+
 func getFloatValue(obj Object) (float64, error) {
 	switch val := obj.(type) {
 	case *Integer:
 		return float64(val.GetValue()), nil
+	case *BigInt:
+		return val.Float(), nil
 	case *Float:
 		return val.GetValue(), nil
 	default:
@@ -709,6 +930,26 @@ func getFloatValue(obj Object) (float64, error) {
 	}
 }
 
+// decimalRounding reads the `skala, mode?` arguments of the decimal builtin
+// fn. The mode defaults to half-even.
+func decimalRounding(fn string, args []Object) (int, RoundingMode, *Error) {
+	scale, ok := args[0].(*Integer)
+	if !ok || scale.GetValue() < 0 || scale.GetValue() > MaxDecimalScale {
+		return 0, 0, NewError(fmt.Sprintf("scale of `%s` must be an INTEGER from 0 to %d", fn, MaxDecimalScale), ErrCodeTypeMismatch, 0, 0)
+	}
+	mode := RoundHalfEven
+	if len(args) == 2 {
+		name, ok := args[1].(*String)
+		if !ok {
+			return 0, 0, NewError(fmt.Sprintf("rounding mode of `%s` must be STRING, got %s", fn, args[1].Type()), ErrCodeTypeMismatch, 0, 0)
+		}
+		if mode, ok = RoundingModes[name.GetValue()]; !ok {
+			return 0, 0, NewError(fmt.Sprintf("unknown rounding mode %q", name.GetValue()), ErrCodeInvalidOp, 0, 0)
+		}
+	}
+	return int(scale.GetValue()), mode, nil
+}
+
 func RegisterBuiltin(name string, fn BuiltinFunction) {
 	for i, def := range Builtins {
 		if def.Name == name {
@@ -0,0 +1,215 @@
+package object
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/VzoelFox/morphlang/pkg/memory"
+)
+
+// Exact decimal semantics for billing-style arithmetic. A decimal is an
+// unscaled integer times 10^-Scale, so 12.30 is {1230, 2}. Addition,
+// subtraction and multiplication are exact; division and rescaling round
+// with an explicit RoundingMode.
+
+// DecimalDivScale is the smallest scale of a `/` result, so 1 / 3 keeps some
+// digits even when both operands are whole numbers.
+const DecimalDivScale = 16
+
+// MaxDecimalScale bounds the digits after the point.
+const MaxDecimalScale = 10000
+
+type RoundingMode int
+
+const (
+	RoundHalfEven RoundingMode = iota // Ties to the even digit (banker's rounding)
+	RoundHalfUp                       // Ties away from zero
+	RoundHalfDown                     // Ties toward zero
+	RoundDown                         // Toward zero (truncate)
+	RoundUp                           // Away from zero
+	RoundFloor                        // Toward negative infinity
+	RoundCeiling                      // Toward positive infinity
+)
+
+// RoundingModes maps the names accepted by `desimal` to their modes.
+var RoundingModes = map[string]RoundingMode{
+	"setengah_genap": RoundHalfEven,
+	"setengah_atas":  RoundHalfUp,
+	"setengah_bawah": RoundHalfDown,
+	"menuju_nol":     RoundDown,
+	"menjauhi_nol":   RoundUp,
+	"lantai":         RoundFloor,
+	"atap":           RoundCeiling,
+}
+
+// DecimalValue is Unscaled * 10^-Scale.
+type DecimalValue struct {
+	Unscaled *big.Int
+	Scale    int
+}
+
+// DecimalFromInt returns v with scale 0.
+func DecimalFromInt(v *big.Int) DecimalValue {
+	return DecimalValue{Unscaled: v, Scale: 0}
+}
+
+// ParseDecimal parses "12.34", "-0.5", "1_000.00" or "1.5e3" exactly.
+func ParseDecimal(s string) (DecimalValue, error) {
+	text := strings.ReplaceAll(strings.TrimSpace(s), "_", "")
+	mantissa, exp := text, 0
+	if i := strings.IndexAny(text, "eE"); i >= 0 {
+		mantissa = text[:i]
+		var err error
+		if exp, err = strconv.Atoi(text[i+1:]); err != nil || exp > MaxDecimalScale || exp < -MaxDecimalScale {
+			return DecimalValue{}, fmt.Errorf("invalid decimal %q", s)
+		}
+	}
+
+	digits := mantissa
+	scale := 0
+	if i := strings.IndexByte(mantissa, '.'); i >= 0 {
+		digits = mantissa[:i] + mantissa[i+1:]
+		scale = len(mantissa) - i - 1
+	}
+	// Sign and at least one digit, nothing else
+	body := strings.TrimLeft(digits, "+-")
+	if len(digits)-len(body) > 1 || body == "" || strings.Trim(body, "0123456789") != "" {
+		return DecimalValue{}, fmt.Errorf("invalid decimal %q", s)
+	}
+
+	unscaled, _ := new(big.Int).SetString(digits, 10)
+	d := DecimalValue{Unscaled: unscaled, Scale: scale - exp}
+	if d.Scale < 0 {
+		d = d.Rescale(0, RoundDown)
+	}
+	if d.Scale > MaxDecimalScale {
+		return DecimalValue{}, fmt.Errorf("decimal %q has more than %d digits after the point", s, MaxDecimalScale)
+	}
+	return d, nil
+}
+
+// String formats the value with exactly Scale digits after the point.
+func (d DecimalValue) String() string {
+	digits := new(big.Int).Abs(d.Unscaled).String()
+	sign := ""
+	if d.Unscaled.Sign() < 0 {
+		sign = "-"
+	}
+	if d.Scale <= 0 {
+		return sign + digits + strings.Repeat("0", -d.Scale)
+	}
+	if len(digits) <= d.Scale {
+		digits = strings.Repeat("0", d.Scale-len(digits)+1) + digits
+	}
+	point := len(digits) - d.Scale
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// Rescale returns the value with the given scale, rounding with mode when
+// digits are dropped.
+func (d DecimalValue) Rescale(scale int, mode RoundingMode) DecimalValue {
+	if scale >= d.Scale {
+		shift := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale-d.Scale)), nil)
+		return DecimalValue{Unscaled: shift.Mul(shift, d.Unscaled), Scale: scale}
+	}
+	div := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(d.Scale-scale)), nil)
+	return DecimalValue{Unscaled: roundQuo(d.Unscaled, div, mode), Scale: scale}
+}
+
+// roundQuo divides a by a positive b, rounding the quotient with mode.
+func roundQuo(a, b *big.Int, mode RoundingMode) *big.Int {
+	q, r := new(big.Int).QuoRem(a, b, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+
+	// q is truncated toward zero; away moves it one step away from zero
+	away := false
+	switch mode {
+	case RoundUp:
+		away = true
+	case RoundFloor:
+		away = a.Sign() < 0
+	case RoundCeiling:
+		away = a.Sign() > 0
+	case RoundHalfEven, RoundHalfUp, RoundHalfDown:
+		twice := new(big.Int).Abs(r)
+		twice.Lsh(twice, 1)
+		switch twice.Cmp(b) {
+		case 1:
+			away = true
+		case 0:
+			away = mode == RoundHalfUp || mode == RoundHalfEven && q.Bit(0) == 1
+		}
+	}
+	if away {
+		q.Add(q, big.NewInt(int64(a.Sign())))
+	}
+	return q
+}
+
+// AlignDecimals brings a and b to their larger scale.
+func AlignDecimals(a, b DecimalValue) (*big.Int, *big.Int, int) {
+	scale := a.Scale
+	if b.Scale > scale {
+		scale = b.Scale
+	}
+	return a.Rescale(scale, RoundDown).Unscaled, b.Rescale(scale, RoundDown).Unscaled, scale
+}
+
+// Cmp compares the values regardless of scale: 1.50 equals 1.5.
+func (d DecimalValue) Cmp(other DecimalValue) int {
+	x, y, _ := AlignDecimals(d, other)
+	return x.Cmp(y)
+}
+
+// QuoDecimal divides a by a non-zero b, rounding to scale with mode.
+func QuoDecimal(a, b DecimalValue, scale int, mode RoundingMode) DecimalValue {
+	// a/b = (a.U * 10^(scale - a.S + b.S)) / b.U, at the target scale
+	num := new(big.Int).Set(a.Unscaled)
+	den := new(big.Int).Set(b.Unscaled)
+	if shift := scale - a.Scale + b.Scale; shift >= 0 {
+		num.Mul(num, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(shift)), nil))
+	} else {
+		den.Mul(den, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-shift)), nil))
+	}
+	if den.Sign() < 0 {
+		num.Neg(num)
+		den.Neg(den)
+	}
+	return DecimalValue{Unscaled: roundQuo(num, den, mode), Scale: scale}
+}
+
+type Decimal struct {
+	Address memory.Ptr
+}
+
+func NewDecimal(val DecimalValue) *Decimal {
+	ptr, err := memory.AllocDecimal(val.Unscaled, int32(val.Scale))
+	if err != nil { panic(err) }
+	return &Decimal{Address: ptr}
+}
+
+func (d *Decimal) Type() ObjectType { return DECIMAL_OBJ }
+func (d *Decimal) Inspect() string {
+	return d.GetValue().String()
+}
+func (d *Decimal) HashKey() HashKey {
+	// Trailing zeros do not change the key
+	val := d.GetValue()
+	for val.Scale > 0 && new(big.Int).Rem(val.Unscaled, big.NewInt(10)).Sign() == 0 {
+		val = val.Rescale(val.Scale-1, RoundDown)
+	}
+	h := fnv.New64a()
+	h.Write([]byte(val.String()))
+	return HashKey{Type: d.Type(), Value: h.Sum64()}
+}
+func (d *Decimal) GetAddress() memory.Ptr { return d.Address }
+func (d *Decimal) GetValue() DecimalValue {
+	unscaled, scale, err := memory.ReadDecimal(d.Address)
+	if err != nil { return DecimalValue{Unscaled: new(big.Int)} }
+	return DecimalValue{Unscaled: unscaled, Scale: int(scale)}
+}
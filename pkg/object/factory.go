@@ -27,6 +27,12 @@ func FromPtr(ptr memory.Ptr) Object {
 		return &Null{Address: ptr}
 	case memory.TagInteger:
 		return &Integer{Address: ptr}
+	case memory.TagBigInt:
+		return &BigInt{Address: ptr}
+	case memory.TagDecimal:
+		return &Decimal{Address: ptr}
+	case memory.TagRange:
+		return &Range{Address: ptr}
 	case memory.TagBoolean:
 		return &Boolean{Address: ptr}
 	case memory.TagFloat:
@@ -61,6 +67,14 @@ func FromPtr(ptr memory.Ptr) Object {
 		return &Pointer{Address: ptr}
 	case memory.TagModule:
 		return &Module{Address: ptr}
+	case memory.TagIterator:
+		return &Iterator{Address: ptr}
+	case memory.TagStruct:
+		return &Struct{Address: ptr}
+	case memory.TagSchema:
+		return &Schema{Address: ptr}
+	case memory.TagVariant:
+		return &Variant{Address: ptr}
 	default:
 		// Fallback or Panic
 		panic(fmt.Sprintf("FromPtr: unknown type tag %d", header.Type))
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"fmt"
 	"hash/fnv"
+	"math"
+	"math/big"
 	"os"
 	"strings"
 	"sync"
@@ -18,6 +20,9 @@ type ObjectType string
 const (
 	INTEGER_OBJ           = "INTEGER"
 	FLOAT_OBJ             = "FLOAT"
+	BIGINT_OBJ            = "BIGINT"
+	DECIMAL_OBJ           = "DECIMAL"
+	RANGE_OBJ             = "RANGE"
 	BOOLEAN_OBJ           = "BOOLEAN"
 	NULL_OBJ              = "NULL"
 	RETURN_VALUE_OBJ      = "RETURN_VALUE"
@@ -37,6 +42,10 @@ const (
 	FILE_OBJ              = "FILE"
 	POINTER_OBJ           = "POINTER"
 	MODULE_OBJ            = "MODULE"
+	ITERATOR_OBJ          = "ITERATOR"
+	STRUCT_OBJ            = "STRUCT"
+	SCHEMA_OBJ            = "SCHEMA"
+	VARIANT_OBJ           = "VARIANT"
 )
 
 type Object interface {
@@ -95,6 +104,90 @@ func (f *Float) GetValue() float64 {
 	return val
 }
 
+// BigInt is an integer outside the int64 range. Integer arithmetic promotes
+// to it on overflow, and results that fit again come back as *Integer, so a
+// BigInt never equals an Integer.
+type BigInt struct {
+	Address memory.Ptr
+}
+
+// NewInt returns val as an *Integer when it fits in int64, else a *BigInt.
+func NewInt(val *big.Int) Object {
+	if val.IsInt64() {
+		return NewInteger(val.Int64())
+	}
+	ptr, err := memory.AllocBigInt(val)
+	if err != nil { panic(err) }
+	return &BigInt{Address: ptr}
+}
+
+func (b *BigInt) Type() ObjectType { return BIGINT_OBJ }
+func (b *BigInt) Inspect() string {
+	return b.GetValue().String()
+}
+func (b *BigInt) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(b.Inspect()))
+	return HashKey{Type: b.Type(), Value: h.Sum64()}
+}
+func (b *BigInt) GetAddress() memory.Ptr { return b.Address }
+func (b *BigInt) GetValue() *big.Int {
+	val, err := memory.ReadBigInt(b.Address)
+	if err != nil { return new(big.Int) }
+	return val
+}
+
+// Float converts the value to the nearest float64 (±Inf when out of range).
+func (b *BigInt) Float() float64 {
+	f, _ := new(big.Float).SetInt(b.GetValue()).Float64()
+	return f
+}
+
+// Range is the integers from Start up to End, End included unless the
+// range was written with `..<`. A range whose End comes first is empty.
+type Range struct {
+	Address memory.Ptr
+}
+
+func NewRange(start, end int64, exclusive bool) *Range {
+	ptr, err := memory.AllocRange(start, end, exclusive)
+	if err != nil { panic(err) }
+	return &Range{Address: ptr}
+}
+
+func (r *Range) Type() ObjectType { return RANGE_OBJ }
+func (r *Range) Inspect() string {
+	start, end, exclusive := r.Bounds()
+	if exclusive {
+		return fmt.Sprintf("%d..<%d", start, end)
+	}
+	return fmt.Sprintf("%d..%d", start, end)
+}
+func (r *Range) GetAddress() memory.Ptr { return r.Address }
+func (r *Range) Bounds() (start, end int64, exclusive bool) {
+	start, end, exclusive, _ = memory.ReadRange(r.Address)
+	return start, end, exclusive
+}
+func (r *Range) Len() int64 {
+	n, _ := RangeLen(r.Bounds())
+	return n
+}
+
+// RangeLen counts the integers in a range; ok is false when the count does
+// not fit in int64.
+func RangeLen(start, end int64, exclusive bool) (n int64, ok bool) {
+	if !exclusive {
+		if end == math.MaxInt64 {
+			if start <= 0 { return 0, false }
+			return end - start + 1, true
+		}
+		end++
+	}
+	if end <= start { return 0, true }
+	n, ok = SubInt(end, start)
+	return n, ok
+}
+
 type Boolean struct {
 	Address memory.Ptr
 }
@@ -330,6 +423,124 @@ func (p *Pointer) GetValue() uint64 {
 	return val
 }
 
+// Iterator is the hidden cursor behind an `untuk` loop.
+type Iterator struct {
+	Address memory.Ptr
+}
+
+func (it *Iterator) Type() ObjectType       { return ITERATOR_OBJ }
+func (it *Iterator) GetAddress() memory.Ptr { return it.Address }
+func (it *Iterator) Inspect() string {
+	_, _, index, _ := memory.ReadIterator(it.Address)
+	return fmt.Sprintf("iterator[%d]", index)
+}
+
+// Schema is the definition created by a `struktur` statement.
+type Schema struct {
+	Address memory.Ptr
+}
+
+func (s *Schema) Type() ObjectType       { return SCHEMA_OBJ }
+func (s *Schema) GetAddress() memory.Ptr { return s.Address }
+func (s *Schema) Inspect() string {
+	if _, _, ok := s.Variant(); ok {
+		return "pilihan " + s.Name()
+	}
+	return "struktur " + s.Name()
+}
+
+// Name returns the schema name.
+func (s *Schema) Name() string {
+	namePtr, _, err := memory.ReadSchema(s.Address)
+	if err != nil { return "" }
+	name, _ := memory.ReadString(namePtr)
+	return name
+}
+
+// Variant splits the name of a variant constructor, "Status.Berjalan", into
+// its pilihan and variant names. ok is false for a struktur.
+func (s *Schema) Variant() (enum, name string, ok bool) {
+	return strings.Cut(s.Name(), ".")
+}
+
+// FieldNames returns the declared field names in order.
+func (s *Schema) FieldNames() []string {
+	_, fieldsPtr, err := memory.ReadSchema(s.Address)
+	if err != nil { return nil }
+	length, _ := memory.ReadArrayLength(fieldsPtr)
+	names := make([]string, length)
+	for i := 0; i < length; i++ {
+		p, _ := memory.ReadArrayElement(fieldsPtr, i)
+		names[i], _ = memory.ReadString(p)
+	}
+	return names
+}
+
+// Struct is an instance of a Schema.
+type Struct struct {
+	Address memory.Ptr
+}
+
+func (st *Struct) Type() ObjectType       { return STRUCT_OBJ }
+func (st *Struct) GetAddress() memory.Ptr { return st.Address }
+func (st *Struct) Schema() *Schema {
+	schemaPtr, _ := memory.ReadStructSchema(st.Address)
+	return &Schema{Address: schemaPtr}
+}
+func (st *Struct) Inspect() string {
+	schema := st.Schema()
+	var out bytes.Buffer
+	out.WriteString(schema.Name())
+	out.WriteString("{")
+	for i, name := range schema.FieldNames() {
+		if i > 0 {
+			out.WriteString(", ")
+		}
+		val, _ := memory.ReadStructField(st.Address, i)
+		out.WriteString(name + ": " + FromPtr(val).Inspect())
+	}
+	out.WriteString("}")
+	return out.String()
+}
+
+// Variant is a value of a `pilihan`, made by its variant constructor.
+type Variant struct {
+	Address memory.Ptr
+}
+
+func (v *Variant) Type() ObjectType       { return VARIANT_OBJ }
+func (v *Variant) GetAddress() memory.Ptr { return v.Address }
+func (v *Variant) Schema() *Schema {
+	schemaPtr, _ := memory.ReadStructSchema(v.Address)
+	return &Schema{Address: schemaPtr}
+}
+
+// Enum returns the name of the pilihan the variant belongs to.
+func (v *Variant) Enum() string {
+	enum, _, _ := v.Schema().Variant()
+	return enum
+}
+
+func (v *Variant) Inspect() string {
+	schema := v.Schema()
+	names := schema.FieldNames()
+	if len(names) == 0 {
+		return schema.Name()
+	}
+	var out bytes.Buffer
+	out.WriteString(schema.Name())
+	out.WriteString("{")
+	for i, name := range names {
+		if i > 0 {
+			out.WriteString(", ")
+		}
+		val, _ := memory.ReadStructField(v.Address, i)
+		out.WriteString(name + ": " + FromPtr(val).Inspect())
+	}
+	out.WriteString("}")
+	return out.String()
+}
+
 type BuiltinFunction func(args ...Object) Object
 
 type Builtin struct {
@@ -411,6 +622,20 @@ func (cf *CompiledFunction) NumParameters() int {
 	return n
 }
 
+// MinParameters is the number of arguments a call must pass at least.
+func (cf *CompiledFunction) MinParameters() int {
+	n, _, err := memory.ReadCompiledFunctionArity(cf.Address)
+	if err != nil { panic(err) }
+	return n
+}
+
+// IsVariadic reports whether the last parameter collects extra arguments.
+func (cf *CompiledFunction) IsVariadic() bool {
+	_, v, err := memory.ReadCompiledFunctionArity(cf.Address)
+	if err != nil { panic(err) }
+	return v
+}
+
 func (cf *CompiledFunction) Instructions() []byte {
 	instr, _, _, err := memory.ReadCompiledFunction(cf.Address)
 	if err != nil { panic(err) }
@@ -454,6 +679,9 @@ type Array struct {
 	Address memory.Ptr
 }
 
+// MaxArrayLength is the most elements an array can hold: it must fit in one tray.
+const MaxArrayLength = (memory.TRAY_SIZE - memory.HeaderSize - 8) / 8
+
 func NewArray(elements []Object) *Array {
 	count := len(elements)
 	ptr, err := memory.AllocArray(count, count)
@@ -501,8 +729,9 @@ func NewHash(pairs []HashPair) *Hash {
 	ptr, err := memory.AllocHash(count)
 	if err != nil { panic(err) }
 
-	for i, pair := range pairs {
-		memory.WriteHashPair(ptr, i, pair.Key.GetAddress(), pair.Value.GetAddress())
+	for _, pair := range pairs {
+		err := memory.HashSet(ptr, pair.Key.GetAddress(), pair.Value.GetAddress())
+		if err != nil { panic(err) }
 	}
 	return &Hash{Address: ptr}
 }
@@ -514,11 +743,10 @@ func (h *Hash) Inspect() string {
 }
 
 func (h *Hash) GetPairs() []HashPair {
-	count, _ := memory.ReadHashCount(h.Address)
-	pairs := make([]HashPair, count)
-	for i := 0; i < count; i++ {
-		kPtr, vPtr, _ := memory.ReadHashPair(h.Address, i)
-		pairs[i] = HashPair{Key: FromPtr(kPtr), Value: FromPtr(vPtr)}
+	keys, values, _ := memory.ReadHashPairs(h.Address)
+	pairs := make([]HashPair, len(keys))
+	for i := range keys {
+		pairs[i] = HashPair{Key: FromPtr(keys[i]), Value: FromPtr(values[i])}
 	}
 	return pairs
 }
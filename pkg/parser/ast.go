@@ -2,6 +2,7 @@ package parser
 
 import (
 	"bytes"
+	"sort"
 	"strings"
 
 	"github.com/VzoelFox/morphlang/pkg/lexer"
@@ -11,8 +12,32 @@ import (
 type Node interface {
 	TokenLiteral() string
 	String() string
+	Span() Span
 }
 
+// Span is the source range a node covers. Lines and columns are 1-based and
+// EndCol points just past the last character; Offset and Length are in bytes.
+type Span struct {
+	StartLine int
+	StartCol  int
+	EndLine   int
+	EndCol    int
+	Offset    int
+	Length    int
+}
+
+// IsZero reports whether the span was never filled in, as for nodes built
+// outside the parser.
+func (s Span) IsZero() bool { return s == Span{} }
+
+// NodeSpan is embedded in every node to carry its Span.
+type NodeSpan struct {
+	Range Span
+}
+
+func (n *NodeSpan) Span() Span     { return n.Range }
+func (n *NodeSpan) SetSpan(s Span) { n.Range = s }
+
 type Statement interface {
 	Node
 	statementNode()
@@ -24,6 +49,7 @@ type Expression interface {
 }
 
 type Program struct {
+	NodeSpan
 	Statements []Statement
 }
 
@@ -43,6 +69,7 @@ func (p *Program) String() string {
 }
 
 type ExpressionStatement struct {
+	NodeSpan
 	Token      lexer.Token
 	Expression Expression
 }
@@ -57,6 +84,7 @@ func (es *ExpressionStatement) String() string {
 }
 
 type Identifier struct {
+	NodeSpan
 	Token lexer.Token
 	Value string
 }
@@ -66,6 +94,7 @@ func (i *Identifier) TokenLiteral() string { return i.Token.Literal }
 func (i *Identifier) String() string       { return i.Value }
 
 type IntegerLiteral struct {
+	NodeSpan
 	Token lexer.Token
 	Value int64
 }
@@ -75,6 +104,7 @@ func (il *IntegerLiteral) TokenLiteral() string { return il.Token.Literal }
 func (il *IntegerLiteral) String() string       { return il.Token.Literal }
 
 type FloatLiteral struct {
+	NodeSpan
 	Token lexer.Token
 	Value float64
 }
@@ -84,6 +114,7 @@ func (fl *FloatLiteral) TokenLiteral() string { return fl.Token.Literal }
 func (fl *FloatLiteral) String() string       { return fl.Token.Literal }
 
 type StringLiteral struct {
+	NodeSpan
 	Token lexer.Token
 	Value string
 }
@@ -93,6 +124,7 @@ func (sl *StringLiteral) TokenLiteral() string { return sl.Token.Literal }
 func (sl *StringLiteral) String() string       { return sl.Token.Literal }
 
 type InterpolatedString struct {
+	NodeSpan
 	Token lexer.Token // The first part
 	Parts []Expression
 }
@@ -116,6 +148,7 @@ func (is *InterpolatedString) String() string {
 }
 
 type BooleanLiteral struct {
+	NodeSpan
 	Token lexer.Token
 	Value bool
 }
@@ -125,6 +158,7 @@ func (b *BooleanLiteral) TokenLiteral() string { return b.Token.Literal }
 func (b *BooleanLiteral) String() string       { return b.Token.Literal }
 
 type NullLiteral struct {
+	NodeSpan
 	Token lexer.Token
 }
 
@@ -133,6 +167,7 @@ func (n *NullLiteral) TokenLiteral() string { return n.Token.Literal }
 func (n *NullLiteral) String() string       { return n.Token.Literal }
 
 type ArrayLiteral struct {
+	NodeSpan
 	Token    lexer.Token // '['
 	Elements []Expression
 }
@@ -152,6 +187,7 @@ func (al *ArrayLiteral) String() string {
 }
 
 type HashLiteral struct {
+	NodeSpan
 	Token lexer.Token // '{'
 	Pairs map[Expression]Expression
 }
@@ -161,8 +197,8 @@ func (hl *HashLiteral) TokenLiteral() string { return hl.Token.Literal }
 func (hl *HashLiteral) String() string {
 	var out bytes.Buffer
 	pairs := []string{}
-	for key, value := range hl.Pairs {
-		pairs = append(pairs, key.String()+":"+value.String())
+	for _, key := range hl.Keys() {
+		pairs = append(pairs, key.String()+":"+hl.Pairs[key].String())
 	}
 	out.WriteString("{")
 	out.WriteString(strings.Join(pairs, ", "))
@@ -170,7 +206,25 @@ func (hl *HashLiteral) String() string {
 	return out.String()
 }
 
+// Keys returns the keys of the literal in source order. Keys without a
+// position (built outside the parser) sort by their text.
+func (hl *HashLiteral) Keys() []Expression {
+	keys := make([]Expression, 0, len(hl.Pairs))
+	for key := range hl.Pairs {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i].Span(), keys[j].Span()
+		if a.Offset != b.Offset {
+			return a.Offset < b.Offset
+		}
+		return keys[i].String() < keys[j].String()
+	})
+	return keys
+}
+
 type IndexExpression struct {
+	NodeSpan
 	Token lexer.Token // The [ token
 	Left  Expression
 	Index Expression
@@ -182,13 +236,65 @@ func (ie *IndexExpression) String() string {
 	var out bytes.Buffer
 	out.WriteString("(")
 	out.WriteString(ie.Left.String())
+	if ie.Optional() {
+		out.WriteString("?.")
+		out.WriteString(ie.Index.String())
+		out.WriteString(")")
+		return out.String()
+	}
 	out.WriteString("[")
 	out.WriteString(ie.Index.String())
 	out.WriteString("])")
 	return out.String()
 }
 
+// Optional reports whether this is a null-safe `left?.name` access.
+func (ie *IndexExpression) Optional() bool { return ie.Token.Type == lexer.QDOT }
+
+// SliceExpression is left[start:end]; a missing bound is nil.
+type SliceExpression struct {
+	NodeSpan
+	Token lexer.Token // The [ token
+	Left  Expression
+	Start Expression
+	End   Expression
+}
+
+func (se *SliceExpression) expressionNode()      {}
+func (se *SliceExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SliceExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString("(")
+	out.WriteString(se.Left.String())
+	out.WriteString("[")
+	if se.Start != nil {
+		out.WriteString(se.Start.String())
+	}
+	out.WriteString(":")
+	if se.End != nil {
+		out.WriteString(se.End.String())
+	}
+	out.WriteString("])")
+	return out.String()
+}
+
+// RangeExpression is start..end, or start..<end which leaves out end.
+type RangeExpression struct {
+	NodeSpan
+	Token     lexer.Token // The .. or ..< token
+	Start     Expression
+	End       Expression
+	Exclusive bool
+}
+
+func (re *RangeExpression) expressionNode()      {}
+func (re *RangeExpression) TokenLiteral() string { return re.Token.Literal }
+func (re *RangeExpression) String() string {
+	return "(" + re.Start.String() + re.Token.Literal + re.End.String() + ")"
+}
+
 type PrefixExpression struct {
+	NodeSpan
 	Token    lexer.Token
 	Operator string
 	Right    Expression
@@ -205,7 +311,22 @@ func (pe *PrefixExpression) String() string {
 	return out.String()
 }
 
+// TryExpression is `coba nilai`: it returns a galat from the enclosing
+// function and otherwise evaluates to the value itself.
+type TryExpression struct {
+	NodeSpan
+	Token lexer.Token // The 'coba' token
+	Value Expression
+}
+
+func (te *TryExpression) expressionNode()      {}
+func (te *TryExpression) TokenLiteral() string { return te.Token.Literal }
+func (te *TryExpression) String() string {
+	return "(coba " + te.Value.String() + ")"
+}
+
 type InfixExpression struct {
+	NodeSpan
 	Token    lexer.Token
 	Left     Expression
 	Operator string
@@ -225,6 +346,7 @@ func (ie *InfixExpression) String() string {
 }
 
 type BlockStatement struct {
+	NodeSpan
 	Token      lexer.Token
 	Statements []Statement
 }
@@ -240,6 +362,7 @@ func (bs *BlockStatement) String() string {
 }
 
 type IfExpression struct {
+	NodeSpan
 	Token       lexer.Token
 	Condition   Expression
 	Consequence *BlockStatement
@@ -263,6 +386,7 @@ func (ie *IfExpression) String() string {
 }
 
 type WhileExpression struct {
+	NodeSpan
 	Token     lexer.Token
 	Condition Expression
 	Body      *BlockStatement
@@ -280,14 +404,247 @@ func (we *WhileExpression) String() string {
 	return out.String()
 }
 
+type ForInExpression struct {
+	NodeSpan
+	Token    lexer.Token // The 'untuk' token
+	Key      *Identifier // Optional: `untuk k, v dalam ...`
+	Value    *Identifier
+	Iterable Expression
+	Body     *BlockStatement
+}
+
+func (fe *ForInExpression) expressionNode()      {}
+func (fe *ForInExpression) TokenLiteral() string { return fe.Token.Literal }
+func (fe *ForInExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString("untuk ")
+	if fe.Key != nil {
+		out.WriteString(fe.Key.String())
+		out.WriteString(", ")
+	}
+	out.WriteString(fe.Value.String())
+	out.WriteString(" dalam ")
+	out.WriteString(fe.Iterable.String())
+	out.WriteString(" ")
+	out.WriteString(fe.Body.String())
+	out.WriteString(" akhir")
+	return out.String()
+}
+
+// MatchExpression is `cocokkan subjek kasus ... akhir`. Arms are tried in
+// order; the first whose pattern matches (and whose guard holds) wins.
+type MatchExpression struct {
+	NodeSpan
+	Token       lexer.Token // The 'cocokkan' token
+	Subject     Expression
+	Arms        []*MatchArm
+	Alternative *BlockStatement // Optional `lainnya` arm
+}
+
+func (me *MatchExpression) expressionNode()      {}
+func (me *MatchExpression) TokenLiteral() string { return me.Token.Literal }
+func (me *MatchExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString("cocokkan ")
+	out.WriteString(me.Subject.String())
+	for _, arm := range me.Arms {
+		out.WriteString(" ")
+		out.WriteString(arm.String())
+	}
+	if me.Alternative != nil {
+		out.WriteString(" lainnya ")
+		out.WriteString(me.Alternative.String())
+	}
+	out.WriteString(" akhir")
+	return out.String()
+}
+
+type MatchArm struct {
+	NodeSpan
+	Token   lexer.Token // The 'kasus' token
+	Pattern Pattern
+	Guard   Expression // Optional `jika` guard
+	Body    *BlockStatement
+}
+
+func (ma *MatchArm) TokenLiteral() string { return ma.Token.Literal }
+func (ma *MatchArm) String() string {
+	var out bytes.Buffer
+	out.WriteString("kasus ")
+	out.WriteString(ma.Pattern.String())
+	if ma.Guard != nil {
+		out.WriteString(" jika ")
+		out.WriteString(ma.Guard.String())
+	}
+	out.WriteString(" ")
+	out.WriteString(ma.Body.String())
+	return out.String()
+}
+
+// Pattern is the left-hand side of a `kasus` arm.
+type Pattern interface {
+	Node
+	patternNode()
+}
+
+// LiteralPattern matches a value equal to an integer, float, string,
+// boolean or kosong literal (numbers may be negated).
+type LiteralPattern struct {
+	NodeSpan
+	Token lexer.Token
+	Value Expression
+}
+
+func (lp *LiteralPattern) patternNode()         {}
+func (lp *LiteralPattern) TokenLiteral() string { return lp.Token.Literal }
+func (lp *LiteralPattern) String() string       { return lp.Value.String() }
+
+// BindingPattern matches anything and binds it to Name. The name `_` matches
+// without binding.
+type BindingPattern struct {
+	NodeSpan
+	Token lexer.Token
+	Name  *Identifier
+}
+
+func (bp *BindingPattern) patternNode()         {}
+func (bp *BindingPattern) TokenLiteral() string { return bp.Token.Literal }
+func (bp *BindingPattern) String() string       { return bp.Name.String() }
+
+// IsWildcard reports whether the pattern is `_`.
+func (bp *BindingPattern) IsWildcard() bool { return bp.Name.Value == "_" }
+
+// ArrayPattern matches arrays element by element: `[a, b, ...sisa]`.
+// Without a rest element the array length must match exactly.
+type ArrayPattern struct {
+	NodeSpan
+	Token    lexer.Token // The '[' token
+	Elements []Pattern
+	HasRest  bool
+	Rest     *Identifier // Optional name after `...`
+}
+
+func (ap *ArrayPattern) patternNode()         {}
+func (ap *ArrayPattern) TokenLiteral() string { return ap.Token.Literal }
+func (ap *ArrayPattern) String() string {
+	parts := []string{}
+	for _, el := range ap.Elements {
+		parts = append(parts, el.String())
+	}
+	if ap.HasRest {
+		rest := "..."
+		if ap.Rest != nil {
+			rest += ap.Rest.String()
+		}
+		parts = append(parts, rest)
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+// HashPattern matches hashes that contain every listed key: `{nama: n, umur}`.
+// With Schema set it matches struct instances instead: `Titik{x: 0, y}`.
+type HashPattern struct {
+	NodeSpan
+	Token  lexer.Token // The '{' token, or the schema name
+	Schema *Identifier
+	Keys   []Expression // String or integer literals
+	Values []Pattern
+}
+
+func (hp *HashPattern) patternNode()         {}
+func (hp *HashPattern) TokenLiteral() string { return hp.Token.Literal }
+func (hp *HashPattern) String() string {
+	parts := []string{}
+	for i, key := range hp.Keys {
+		parts = append(parts, key.String()+": "+hp.Values[i].String())
+	}
+	prefix := ""
+	if hp.Schema != nil {
+		prefix = hp.Schema.String()
+	}
+	return prefix + "{" + strings.Join(parts, ", ") + "}"
+}
+
+// ErrorPattern matches galat values: `galat`, `galat(pesan)` or
+// `galat(pesan, kode)`.
+type ErrorPattern struct {
+	NodeSpan
+	Token   lexer.Token
+	Message Pattern // Optional
+	Code    Pattern // Optional
+}
+
+func (ep *ErrorPattern) patternNode()         {}
+func (ep *ErrorPattern) TokenLiteral() string { return ep.Token.Literal }
+func (ep *ErrorPattern) String() string {
+	if ep.Message == nil {
+		return "galat"
+	}
+	if ep.Code == nil {
+		return "galat(" + ep.Message.String() + ")"
+	}
+	return "galat(" + ep.Message.String() + ", " + ep.Code.String() + ")"
+}
+
 type FunctionLiteral struct {
+	NodeSpan
 	Token      lexer.Token
 	Name       string
 	Parameters []*Identifier
+	Defaults   []Expression      // Default value per parameter (nil when required); empty if none has one
+	Types      []*TypeAnnotation // Declared type per parameter (nil when untyped); empty if none has one
+	Variadic   bool              // The last parameter collects the remaining arguments into an array
+	ReturnType *TypeAnnotation   // Declared after `->`, or nil
 	Body       *BlockStatement
 	Doc        string
 }
 
+// Default returns the default value of parameter i, or nil if it is required.
+func (fl *FunctionLiteral) Default(i int) Expression {
+	if i < len(fl.Defaults) {
+		return fl.Defaults[i]
+	}
+	return nil
+}
+
+// ParamType returns the declared type of parameter i, or nil.
+func (fl *FunctionLiteral) ParamType(i int) *TypeAnnotation {
+	if i < len(fl.Types) {
+		return fl.Types[i]
+	}
+	return nil
+}
+
+// MinArgs is the number of arguments a call must pass at least.
+func (fl *FunctionLiteral) MinArgs() int {
+	n := 0
+	for i := range fl.Parameters {
+		if fl.Default(i) == nil && !(fl.Variadic && i == len(fl.Parameters)-1) {
+			n++
+		}
+	}
+	return n
+}
+
+// Receiver is the implicit first parameter of a struktur method, bound to
+// the instance the method is called on.
+const Receiver = "ini"
+
+// WithReceiver returns a copy of a method with Receiver prepended to its
+// parameters, which is how methods are compiled and analyzed.
+func (fl *FunctionLiteral) WithReceiver() *FunctionLiteral {
+	m := *fl
+	receiver := &Identifier{Token: lexer.Token{Type: lexer.IDENT, Literal: Receiver}, Value: Receiver}
+	m.Parameters = append([]*Identifier{receiver}, fl.Parameters...)
+	if len(fl.Defaults) > 0 {
+		m.Defaults = append([]Expression{nil}, fl.Defaults...)
+	}
+	if len(fl.Types) > 0 {
+		m.Types = append([]*TypeAnnotation{nil}, fl.Types...)
+	}
+	return &m
+}
+
 func (fl *FunctionLiteral) expressionNode()      {}
 func (fl *FunctionLiteral) TokenLiteral() string { return fl.Token.Literal }
 func (fl *FunctionLiteral) String() string {
@@ -299,18 +656,32 @@ func (fl *FunctionLiteral) String() string {
 	}
 	out.WriteString("(")
 	for i, p := range fl.Parameters {
+		if fl.Variadic && i == len(fl.Parameters)-1 {
+			out.WriteString("...")
+		}
 		out.WriteString(p.String())
+		if t := fl.ParamType(i); t != nil {
+			out.WriteString(": " + t.String())
+		}
+		if d := fl.Default(i); d != nil {
+			out.WriteString(" = ")
+			out.WriteString(d.String())
+		}
 		if i < len(fl.Parameters)-1 {
 			out.WriteString(", ")
 		}
 	}
 	out.WriteString(") ")
+	if fl.ReturnType != nil {
+		out.WriteString("-> " + fl.ReturnType.String() + " ")
+	}
 	out.WriteString(fl.Body.String())
 	out.WriteString(" akhir")
 	return out.String()
 }
 
 type CallExpression struct {
+	NodeSpan
 	Token     lexer.Token
 	Function  Expression
 	Arguments []Expression
@@ -332,7 +703,34 @@ func (ce *CallExpression) String() string {
 	return out.String()
 }
 
+// NamedArgument is a call argument given by name: `Titik(x: 1)`.
+type NamedArgument struct {
+	NodeSpan
+	Token lexer.Token // The name
+	Name  *Identifier
+	Value Expression
+}
+
+func (na *NamedArgument) expressionNode()      {}
+func (na *NamedArgument) TokenLiteral() string { return na.Token.Literal }
+func (na *NamedArgument) String() string {
+	return na.Name.String() + ": " + na.Value.String()
+}
+
+// NamedArguments returns the named arguments of the call, which always
+// follow the positional ones.
+func (ce *CallExpression) NamedArguments() []*NamedArgument {
+	named := []*NamedArgument{}
+	for _, a := range ce.Arguments {
+		if na, ok := a.(*NamedArgument); ok {
+			named = append(named, na)
+		}
+	}
+	return named
+}
+
 type ReturnStatement struct {
+	NodeSpan
 	Token       lexer.Token
 	ReturnValue Expression
 }
@@ -350,10 +748,31 @@ func (rs *ReturnStatement) String() string {
 }
 
 type StructStatement struct {
-	Token  lexer.Token // The 'struktur' token
-	Name   *Identifier
-	Fields []*Identifier
-	Doc    string
+	NodeSpan
+	Token      lexer.Token // The 'struktur' token
+	Name       *Identifier
+	Fields     []*Identifier
+	FieldTypes []*TypeAnnotation  // Declared type per field (nil when untyped); empty if none has one
+	Methods    []*FunctionLiteral // Named functions; see FunctionLiteral.WithReceiver
+	Doc        string
+}
+
+// FieldType returns the declared type of field i, or nil.
+func (ss *StructStatement) FieldType(i int) *TypeAnnotation {
+	if i < len(ss.FieldTypes) {
+		return ss.FieldTypes[i]
+	}
+	return nil
+}
+
+// Method returns the method called name, or nil if there is none.
+func (ss *StructStatement) Method(name string) *FunctionLiteral {
+	for _, m := range ss.Methods {
+		if m.Name == name {
+			return m
+		}
+	}
+	return nil
 }
 
 func (ss *StructStatement) statementNode()       {}
@@ -363,14 +782,77 @@ func (ss *StructStatement) String() string {
 	out.WriteString("struktur ")
 	out.WriteString(ss.Name.String())
 	out.WriteString("\n")
-	for _, f := range ss.Fields {
-		out.WriteString("  " + f.String() + "\n")
+	for i, f := range ss.Fields {
+		out.WriteString("  " + f.String())
+		if t := ss.FieldType(i); t != nil {
+			out.WriteString(": " + t.String())
+		}
+		out.WriteString("\n")
+	}
+	for _, m := range ss.Methods {
+		out.WriteString("  " + m.String() + "\n")
+	}
+	out.WriteString("akhir")
+	return out.String()
+}
+
+// EnumStatement declares a closed set of variants, some carrying fields:
+// `pilihan Status Menunggu Berjalan(pid) akhir`.
+type EnumStatement struct {
+	NodeSpan
+	Token    lexer.Token // The 'pilihan' token
+	Name     *Identifier
+	Variants []*EnumVariant
+	Doc      string
+}
+
+// Variant returns the variant called name, or nil if there is none.
+func (es *EnumStatement) Variant(name string) *EnumVariant {
+	for _, v := range es.Variants {
+		if v.Name.Value == name {
+			return v
+		}
+	}
+	return nil
+}
+
+func (es *EnumStatement) statementNode()       {}
+func (es *EnumStatement) TokenLiteral() string { return es.Token.Literal }
+func (es *EnumStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString("pilihan ")
+	out.WriteString(es.Name.String())
+	out.WriteString("\n")
+	for _, v := range es.Variants {
+		out.WriteString("  " + v.String() + "\n")
 	}
 	out.WriteString("akhir")
 	return out.String()
 }
 
+// EnumVariant is one variant of a pilihan. Fields is nil for a variant
+// written without parentheses.
+type EnumVariant struct {
+	NodeSpan
+	Token  lexer.Token // The variant name
+	Name   *Identifier
+	Fields []*Identifier
+}
+
+func (ev *EnumVariant) TokenLiteral() string { return ev.Token.Literal }
+func (ev *EnumVariant) String() string {
+	if ev.Fields == nil {
+		return ev.Name.String()
+	}
+	fields := []string{}
+	for _, f := range ev.Fields {
+		fields = append(fields, f.String())
+	}
+	return ev.Name.String() + "(" + strings.Join(fields, ", ") + ")"
+}
+
 type BreakStatement struct {
+	NodeSpan
 	Token lexer.Token
 }
 
@@ -379,6 +861,7 @@ func (bs *BreakStatement) TokenLiteral() string { return bs.Token.Literal }
 func (bs *BreakStatement) String() string       { return bs.Token.Literal + ";" }
 
 type ContinueStatement struct {
+	NodeSpan
 	Token lexer.Token
 }
 
@@ -387,6 +870,7 @@ func (cs *ContinueStatement) TokenLiteral() string { return cs.Token.Literal }
 func (cs *ContinueStatement) String() string       { return cs.Token.Literal + ";" }
 
 type ImportStatement struct {
+	NodeSpan
 	Token       lexer.Token // The 'ambil' or 'dari' token
 	Path        string      // The file path
 	Identifiers []string    // Imported identifiers (if 'dari')
@@ -411,20 +895,111 @@ func (is *ImportStatement) String() string {
 }
 
 type AssignmentStatement struct {
+	NodeSpan
 	Token lexer.Token
 	Name  Expression
+	Type  *TypeAnnotation // Declared as in `x: string = ...`, or nil
 	Value Expression
 }
 
+// Operator returns the arithmetic operator of a compound assignment such as
+// `x += 1` ("+"), or "" for a plain `=`.
+func (as *AssignmentStatement) Operator() string {
+	if isCompoundAssign(as.Token.Type) {
+		return strings.TrimSuffix(as.Token.Literal, "=")
+	}
+	return ""
+}
+
 func (as *AssignmentStatement) statementNode()       {}
 func (as *AssignmentStatement) TokenLiteral() string { return as.Token.Literal }
 func (as *AssignmentStatement) String() string {
 	var out bytes.Buffer
 	out.WriteString(as.Name.String())
-	out.WriteString(" = ")
+	if as.Type != nil {
+		out.WriteString(": " + as.Type.String())
+	}
+	out.WriteString(" " + as.Operator() + "= ")
 	if as.Value != nil {
 		out.WriteString(as.Value.String())
 	}
 	out.WriteString(";")
 	return out.String()
 }
+
+// TypeAnnotation is a declared type such as `integer` or `integer | galat`.
+// Types are checked by the analyzer, and at runtime in strict mode.
+type TypeAnnotation struct {
+	NodeSpan
+	Token lexer.Token // The first type name
+	Names []string    // As written
+}
+
+func (ta *TypeAnnotation) TokenLiteral() string { return ta.Token.Literal }
+func (ta *TypeAnnotation) String() string       { return strings.Join(ta.Names, " | ") }
+
+// typeAliases maps the Indonesian spelling of a type to its name.
+var typeAliases = map[string]string{
+	"galat":   "error",
+	"fungsi":  "function",
+	"desimal": "decimal",
+}
+
+// Types returns the declared names with aliases resolved, so that
+// `galat` and `error` both read as "error".
+func (ta *TypeAnnotation) Types() []string {
+	types := make([]string, len(ta.Names))
+	for i, name := range ta.Names {
+		if alias, ok := typeAliases[name]; ok {
+			name = alias
+		}
+		types[i] = name
+	}
+	return types
+}
+
+// ConstStatement binds Name once, as in `tetap BATAS = 10`. The binding
+// cannot be assigned again.
+type ConstStatement struct {
+	NodeSpan
+	Token lexer.Token // The 'tetap' token
+	Name  *Identifier
+	Value Expression
+}
+
+func (cs *ConstStatement) statementNode()       {}
+func (cs *ConstStatement) TokenLiteral() string { return cs.Token.Literal }
+func (cs *ConstStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString(cs.TokenLiteral() + " ")
+	out.WriteString(cs.Name.String())
+	out.WriteString(" = ")
+	if cs.Value != nil {
+		out.WriteString(cs.Value.String())
+	}
+	out.WriteString(";")
+	return out.String()
+}
+
+// DestructuringStatement binds the parts of a value to names, as in
+// `[a, b, ...sisa] = xs`, `{nama, umur} = orang` or `Titik{x, y} = t`.
+// Target is an ArrayPattern or HashPattern made of bindings.
+type DestructuringStatement struct {
+	NodeSpan
+	Token  lexer.Token // The '=' token
+	Target Pattern
+	Value  Expression
+}
+
+func (ds *DestructuringStatement) statementNode()       {}
+func (ds *DestructuringStatement) TokenLiteral() string { return ds.Token.Literal }
+func (ds *DestructuringStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString(ds.Target.String())
+	out.WriteString(" = ")
+	if ds.Value != nil {
+		out.WriteString(ds.Value.String())
+	}
+	out.WriteString(";")
+	return out.String()
+}
@@ -0,0 +1,338 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/VzoelFox/morphlang/pkg/lexer"
+)
+
+// AST JSON format: every node is an object with its "kind", its "span" and
+// one snake_case key per field. Tokens keep their full position, so decoding
+// gives back the same tree the parser built, ready for compiler.Compile.
+
+// nodeKinds maps the "kind" of a JSON node to its Go type.
+var nodeKinds = map[string]reflect.Type{}
+
+func init() {
+	nodes := []Node{
+		&Program{},
+		&ExpressionStatement{},
+		&Identifier{},
+		&IntegerLiteral{},
+		&FloatLiteral{},
+		&StringLiteral{},
+		&InterpolatedString{},
+		&BooleanLiteral{},
+		&NullLiteral{},
+		&ArrayLiteral{},
+		&HashLiteral{},
+		&IndexExpression{},
+		&SliceExpression{},
+		&RangeExpression{},
+		&PrefixExpression{},
+		&TryExpression{},
+		&InfixExpression{},
+		&BlockStatement{},
+		&IfExpression{},
+		&WhileExpression{},
+		&ForInExpression{},
+		&MatchExpression{},
+		&MatchArm{},
+		&LiteralPattern{},
+		&BindingPattern{},
+		&ArrayPattern{},
+		&HashPattern{},
+		&ErrorPattern{},
+		&FunctionLiteral{},
+		&CallExpression{},
+		&NamedArgument{},
+		&ReturnStatement{},
+		&StructStatement{},
+		&EnumStatement{},
+		&EnumVariant{},
+		&BreakStatement{},
+		&ContinueStatement{},
+		&ImportStatement{},
+		&AssignmentStatement{},
+		&DestructuringStatement{},
+		&ConstStatement{},
+		&TypeAnnotation{},
+	}
+	for _, n := range nodes {
+		t := reflect.TypeOf(n).Elem()
+		nodeKinds[t.Name()] = t
+	}
+}
+
+var (
+	tokenType    = reflect.TypeOf(lexer.Token{})
+	nodeSpanType = reflect.TypeOf(NodeSpan{})
+)
+
+type jsonToken struct {
+	Type         lexer.TokenType `json:"type"`
+	Literal      string          `json:"literal"`
+	Line         int             `json:"line"`
+	Column       int             `json:"column"`
+	LeadingSpace bool            `json:"leading_space,omitempty"`
+	Offset       int             `json:"offset"`
+	EndLine      int             `json:"end_line"`
+	EndColumn    int             `json:"end_column"`
+	EndOffset    int             `json:"end_offset"`
+}
+
+type jsonSpan struct {
+	StartLine int `json:"start_line"`
+	StartCol  int `json:"start_col"`
+	EndLine   int `json:"end_line"`
+	EndCol    int `json:"end_col"`
+	Offset    int `json:"offset"`
+	Length    int `json:"length"`
+}
+
+type jsonPair struct {
+	Key   json.RawMessage `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// MarshalJSON encodes the whole tree in the AST JSON format.
+func (p *Program) MarshalJSON() ([]byte, error) {
+	v, err := encodeNode(reflect.ValueOf(p))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON rebuilds a program from the AST JSON format.
+func (p *Program) UnmarshalJSON(data []byte) error {
+	v, err := decodeNode(data)
+	if err != nil {
+		return err
+	}
+	prog, ok := v.Interface().(*Program)
+	if !ok {
+		return fmt.Errorf("ast json: expected Program at the root, got %s", v.Type().Elem().Name())
+	}
+	*p = *prog
+	return nil
+}
+
+func encodeNode(v reflect.Value) (interface{}, error) {
+	node := v.Interface().(Node)
+	s := node.Span()
+	obj := map[string]interface{}{
+		"kind": v.Elem().Type().Name(),
+		"span": jsonSpan{s.StartLine, s.StartCol, s.EndLine, s.EndCol, s.Offset, s.Length},
+	}
+
+	st := v.Elem()
+	for i := 0; i < st.NumField(); i++ {
+		field := st.Type().Field(i)
+		if field.Type == nodeSpanType {
+			continue
+		}
+		val, err := encodeValue(st.Field(i))
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", st.Type().Name(), field.Name, err)
+		}
+		obj[snakeCase(field.Name)] = val
+	}
+	return obj, nil
+}
+
+func encodeValue(v reflect.Value) (interface{}, error) {
+	if v.Type() == tokenType {
+		t := v.Interface().(lexer.Token)
+		return jsonToken{t.Type, t.Literal, t.Line, t.Column, t.HasLeadingSpace, t.Offset, t.EndLine, t.EndColumn, t.EndOffset}, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if v.Kind() == reflect.Interface {
+			return encodeValue(v.Elem())
+		}
+		return encodeNode(v)
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil, nil
+		}
+		list := make([]interface{}, v.Len())
+		for i := range list {
+			el, err := encodeValue(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			list[i] = el
+		}
+		return list, nil
+
+	case reflect.Map:
+		// Hash literal pairs, in source order
+		keys := (&HashLiteral{Pairs: v.Interface().(map[Expression]Expression)}).Keys()
+		pairs := make([]map[string]interface{}, len(keys))
+		for i, k := range keys {
+			key, err := encodeValue(reflect.ValueOf(k))
+			if err != nil {
+				return nil, err
+			}
+			value, err := encodeValue(v.MapIndex(reflect.ValueOf(k)))
+			if err != nil {
+				return nil, err
+			}
+			pairs[i] = map[string]interface{}{"key": key, "value": value}
+		}
+		return pairs, nil
+
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int64, reflect.Float64:
+		return v.Interface(), nil
+	}
+
+	return nil, fmt.Errorf("cannot encode %s", v.Type())
+}
+
+// decodeNode returns a pointer to the node described by data, or an invalid
+// value for null.
+func decodeNode(data json.RawMessage) (reflect.Value, error) {
+	if isNull(data) {
+		return reflect.Value{}, nil
+	}
+
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return reflect.Value{}, fmt.Errorf("ast json: %w", err)
+	}
+
+	var kind string
+	if err := json.Unmarshal(obj["kind"], &kind); err != nil {
+		return reflect.Value{}, fmt.Errorf("ast json: node without kind")
+	}
+	t, ok := nodeKinds[kind]
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("ast json: unknown node kind %q", kind)
+	}
+
+	v := reflect.New(t)
+	if raw, ok := obj["span"]; ok && !isNull(raw) {
+		var s jsonSpan
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return reflect.Value{}, fmt.Errorf("ast json: %s span: %w", kind, err)
+		}
+		v.Interface().(interface{ SetSpan(Span) }).SetSpan(Span{s.StartLine, s.StartCol, s.EndLine, s.EndCol, s.Offset, s.Length})
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Type == nodeSpanType {
+			continue
+		}
+		raw, ok := obj[snakeCase(field.Name)]
+		if !ok {
+			continue
+		}
+		if err := decodeValue(raw, v.Elem().Field(i)); err != nil {
+			return reflect.Value{}, fmt.Errorf("ast json: %s.%s: %w", kind, field.Name, err)
+		}
+	}
+	return v, nil
+}
+
+func decodeValue(data json.RawMessage, dst reflect.Value) error {
+	if dst.Type() == tokenType {
+		var t jsonToken
+		if err := json.Unmarshal(data, &t); err != nil {
+			return err
+		}
+		dst.Set(reflect.ValueOf(lexer.Token{
+			Type:            t.Type,
+			Literal:         t.Literal,
+			Line:            t.Line,
+			Column:          t.Column,
+			HasLeadingSpace: t.LeadingSpace,
+			Offset:          t.Offset,
+			EndLine:         t.EndLine,
+			EndColumn:       t.EndColumn,
+			EndOffset:       t.EndOffset,
+		}))
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		node, err := decodeNode(data)
+		if err != nil || !node.IsValid() {
+			return err
+		}
+		if !node.Type().AssignableTo(dst.Type()) {
+			return fmt.Errorf("%s cannot be used as %s", node.Type().Elem().Name(), dst.Type())
+		}
+		dst.Set(node)
+		return nil
+
+	case reflect.Slice:
+		if isNull(data) {
+			return nil
+		}
+		var list []json.RawMessage
+		if err := json.Unmarshal(data, &list); err != nil {
+			return err
+		}
+		slice := reflect.MakeSlice(dst.Type(), len(list), len(list))
+		for i, raw := range list {
+			if err := decodeValue(raw, slice.Index(i)); err != nil {
+				return err
+			}
+		}
+		dst.Set(slice)
+		return nil
+
+	case reflect.Map:
+		var pairs []jsonPair
+		if err := json.Unmarshal(data, &pairs); err != nil {
+			return err
+		}
+		m := reflect.MakeMapWithSize(dst.Type(), len(pairs))
+		for _, pair := range pairs {
+			key := reflect.New(dst.Type().Key()).Elem()
+			if err := decodeValue(pair.Key, key); err != nil {
+				return err
+			}
+			value := reflect.New(dst.Type().Elem()).Elem()
+			if err := decodeValue(pair.Value, value); err != nil {
+				return err
+			}
+			m.SetMapIndex(key, value)
+		}
+		dst.Set(m)
+		return nil
+	}
+
+	return json.Unmarshal(data, dst.Addr().Interface())
+}
+
+func isNull(data json.RawMessage) bool {
+	return len(data) == 0 || string(data) == "null"
+}
+
+// snakeCase turns a field name like ReturnValue into return_value.
+func snakeCase(name string) string {
+	var out strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				out.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		out.WriteRune(r)
+	}
+	return out.String()
+}
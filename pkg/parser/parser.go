@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -11,10 +12,12 @@ import (
 const (
 	_ int = iota
 	LOWEST
+	COALESCE    // ?? or ?!
 	OR          // atau
 	AND         // dan
 	EQUALS      // ==
 	LESSGREATER // > or <
+	RANGE       // .. or ..<
 	BITOR       // |
 	BITXOR      // ^
 	BITAND      // &
@@ -22,31 +25,40 @@ const (
 	SUM         // +
 	PRODUCT     // *
 	PREFIX      // -X or !X or ~X
+	POWER       // ** (binds tighter than unary minus: -2 ** 2 == -4)
 	CALL        // myFunction(X)
 	INDEX       // array[index]
 )
 
 var precedences = map[lexer.TokenType]int{
-	lexer.ATAU:     OR,
-	lexer.DAN:      AND,
-	lexer.OR:       BITOR,
-	lexer.XOR:      BITXOR,
-	lexer.AND:      BITAND,
-	lexer.EQ:       EQUALS,
-	lexer.NOT_EQ:   EQUALS,
-	lexer.LT:       LESSGREATER,
-	lexer.GT:       LESSGREATER,
-	lexer.LTE:      LESSGREATER,
-	lexer.GTE:      LESSGREATER,
-	lexer.LSHIFT:   SHIFT,
-	lexer.RSHIFT:   SHIFT,
-	lexer.PLUS:     SUM,
-	lexer.MINUS:    SUM,
-	lexer.SLASH:    PRODUCT,
-	lexer.ASTERISK: PRODUCT,
-	lexer.LPAREN:   CALL,
-	lexer.LBRACKET: INDEX,
-	lexer.DOT:      INDEX, // Dot has high precedence
+	lexer.NULL_COALESCE:  COALESCE,
+	lexer.ERROR_COALESCE: COALESCE,
+	lexer.ATAU:           OR,
+	lexer.DAN:            AND,
+	lexer.OR:             BITOR,
+	lexer.XOR:            BITXOR,
+	lexer.AND:            BITAND,
+	lexer.EQ:             EQUALS,
+	lexer.NOT_EQ:         EQUALS,
+	lexer.LT:             LESSGREATER,
+	lexer.GT:             LESSGREATER,
+	lexer.LTE:            LESSGREATER,
+	lexer.GTE:            LESSGREATER,
+	lexer.DOTDOT:         RANGE,
+	lexer.DOTDOT_LT:      RANGE,
+	lexer.LSHIFT:         SHIFT,
+	lexer.RSHIFT:         SHIFT,
+	lexer.PLUS:           SUM,
+	lexer.MINUS:          SUM,
+	lexer.SLASH:          PRODUCT,
+	lexer.ASTERISK:       PRODUCT,
+	lexer.PERCENT:        PRODUCT,
+	lexer.FLOORDIV:       PRODUCT,
+	lexer.POWER:          POWER,
+	lexer.LPAREN:         CALL,
+	lexer.LBRACKET:       INDEX,
+	lexer.DOT:            INDEX, // Dot has high precedence
+	lexer.QDOT:           INDEX,
 }
 
 type (
@@ -62,13 +74,22 @@ const (
 	LEVEL_PANIC   ErrorLevel = "PANIC"
 )
 
+// ErrMissingWhitespace is the code of errors for binary operators written
+// without surrounding spaces. The tree is still complete when these are the
+// only errors, so a formatter can repair them.
+const ErrMissingWhitespace = "E001"
+
 type ParserError struct {
-	Level   ErrorLevel
-	Message string
-	Line    int
-	Column  int
-	File    string
-	Context string
+	Level    ErrorLevel
+	Code     string // Set for errors with a documented code
+	Message  string
+	Line     int
+	Column   int
+	File     string
+	Context  string
+	Expected string // What the parser wanted here (token type or construct), if known
+	Found    string // Token type actually found at Line:Column
+	Span     Span   // Range of the offending token
 }
 
 func (e ParserError) String() string {
@@ -86,6 +107,7 @@ type Parser struct {
 	l      *lexer.Lexer
 	errors []ParserError
 
+	prevToken lexer.Token // Token before curToken, where a node ends when curToken is past it
 	curToken  lexer.Token
 	peekToken lexer.Token
 
@@ -94,6 +116,17 @@ type Parser struct {
 
 	prefixParseFns map[lexer.TokenType]prefixParseFn
 	infixParseFns  map[lexer.TokenType]infixParseFn
+
+	// Panic-mode recovery: after an error, further errors are suppressed
+	// until the statement loop resynchronizes.
+	panicking bool
+	blocks    []lexer.Token // Openers of the blocks still waiting for `akhir`
+
+	lexErrors int // Lexer errors already reported
+
+	// Set while parsing a `kasus` guard, where adjacent strings only merge
+	// on the same line so the guard stops before the body of its arm.
+	inGuard bool
 }
 
 func New(l *lexer.Lexer) *Parser {
@@ -117,6 +150,9 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefix(lexer.LPAREN, p.parseGroupedExpression)
 	p.registerPrefix(lexer.JIKA, p.parseIfExpression)
 	p.registerPrefix(lexer.SELAMA, p.parseWhileExpression)
+	p.registerPrefix(lexer.UNTUK, p.parseForInExpression)
+	p.registerPrefix(lexer.COCOKKAN, p.parseMatchExpression)
+	p.registerPrefix(lexer.COBA, p.parseTryExpression)
 	p.registerPrefix(lexer.FUNGSI, p.parseFunctionLiteral)
 	p.registerPrefix(lexer.LBRACKET, p.parseArrayLiteral)
 	p.registerPrefix(lexer.LBRACE, p.parseHashLiteral)
@@ -126,6 +162,9 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(lexer.MINUS, p.parseInfixExpression)
 	p.registerInfix(lexer.SLASH, p.parseInfixExpression)
 	p.registerInfix(lexer.ASTERISK, p.parseInfixExpression)
+	p.registerInfix(lexer.PERCENT, p.parseInfixExpression)
+	p.registerInfix(lexer.FLOORDIV, p.parseInfixExpression)
+	p.registerInfix(lexer.POWER, p.parseInfixExpression)
 	p.registerInfix(lexer.EQ, p.parseInfixExpression)
 	p.registerInfix(lexer.NOT_EQ, p.parseInfixExpression)
 	p.registerInfix(lexer.LT, p.parseInfixExpression)
@@ -139,9 +178,14 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(lexer.RSHIFT, p.parseInfixExpression)
 	p.registerInfix(lexer.DAN, p.parseInfixExpression)
 	p.registerInfix(lexer.ATAU, p.parseInfixExpression)
+	p.registerInfix(lexer.NULL_COALESCE, p.parseInfixExpression)
+	p.registerInfix(lexer.ERROR_COALESCE, p.parseInfixExpression)
 	p.registerInfix(lexer.LPAREN, p.parseCallExpression)
 	p.registerInfix(lexer.LBRACKET, p.parseIndexExpression)
 	p.registerInfix(lexer.DOT, p.parseDotExpression)
+	p.registerInfix(lexer.QDOT, p.parseDotExpression)
+	p.registerInfix(lexer.DOTDOT, p.parseRangeExpression)
+	p.registerInfix(lexer.DOTDOT_LT, p.parseRangeExpression)
 
 	// Read two tokens, so curToken and peekToken are both set
 	p.nextToken()
@@ -151,6 +195,7 @@ func New(l *lexer.Lexer) *Parser {
 }
 
 func (p *Parser) nextToken() {
+	p.prevToken = p.curToken
 	p.curToken = p.peekToken
 	p.curComment = p.peekComment
 	p.peekComment = ""
@@ -163,6 +208,12 @@ func (p *Parser) nextToken() {
 		p.peekComment += p.peekToken.Literal
 		p.peekToken = p.l.NextToken()
 	}
+
+	// Malformed literals, such as bad escapes, are reported where they occur
+	for _, e := range p.l.Errors()[p.lexErrors:] {
+		p.addDetailedError(e.Token, "%s", e.Message)
+	}
+	p.lexErrors = len(p.l.Errors())
 }
 
 func (p *Parser) Errors() []ParserError {
@@ -170,26 +221,71 @@ func (p *Parser) Errors() []ParserError {
 }
 
 func (p *Parser) addDetailedError(tok lexer.Token, format string, args ...interface{}) {
-	// De-duplicate errors at same location
-	if len(p.errors) > 0 {
-		lastErr := p.errors[len(p.errors)-1]
-		if lastErr.Line == tok.Line && lastErr.Column == tok.Column {
-			return
-		}
+	p.addExpectedError(tok, "", format, args...)
+}
+
+// addWhitespaceError records a missing space around an operator. The
+// expression itself parsed fine, so unlike other errors this does not enter
+// panic mode: the rest of the block is still parsed and can be formatted.
+// One per line is reported, as a+b+c is a single mistake.
+func (p *Parser) addWhitespaceError(tok lexer.Token, format string, args ...interface{}) {
+	if p.panicking {
+		return
+	}
+	if n := len(p.errors); n > 0 && p.errors[n-1].Code == ErrMissingWhitespace && p.errors[n-1].Line == tok.Line {
+		return
+	}
+	err := p.newError(tok, "", format, args...)
+	err.Code = ErrMissingWhitespace
+	p.errors = append(p.errors, err)
+}
+
+// addMemberError records a struktur member that parsed fine but clashes with
+// another. Like addWhitespaceError it does not enter panic mode, so the akhir
+// closing the struktur is still matched.
+func (p *Parser) addMemberError(tok lexer.Token, format string, args ...interface{}) {
+	if p.panicking || p.hasErrorAt(tok) {
+		return
+	}
+	p.errors = append(p.errors, p.newError(tok, "", format, args...))
+}
+
+// addExpectedError records an error at tok together with what the parser
+// expected there. Errors raised while recovering from an earlier one, or at a
+// position that already has an error, are dropped.
+func (p *Parser) addExpectedError(tok lexer.Token, expected string, format string, args ...interface{}) {
+	if p.panicking {
+		return
+	}
+	if p.hasErrorAt(tok) {
+		p.panicking = true
+		return
 	}
 
-	msg := fmt.Sprintf(format, args...)
-	lineContent := p.getLineContent(tok.Line)
+	p.errors = append(p.errors, p.newError(tok, expected, format, args...))
+	p.panicking = true
+}
 
-	err := ParserError{
-		Level:   LEVEL_ERROR,
-		Message: msg,
-		Line:    tok.Line,
-		Column:  tok.Column,
-		Context: lineContent,
+func (p *Parser) hasErrorAt(tok lexer.Token) bool {
+	for _, e := range p.errors {
+		if e.Line == tok.Line && e.Column == tok.Column {
+			return true
+		}
 	}
+	return false
+}
 
-	p.errors = append(p.errors, err)
+func (p *Parser) newError(tok lexer.Token, expected string, format string, args ...interface{}) ParserError {
+	return ParserError{
+		Level:    LEVEL_ERROR,
+		Message:  fmt.Sprintf(format, args...),
+		Line:     tok.Line,
+		Column:   tok.Column,
+		Context:  p.getLineContent(tok.Line),
+		Expected: expected,
+		Found:    string(tok.Type),
+		Span:     tokenSpan(tok, tok),
+	}
 }
 
 func (p *Parser) getLineContent(line int) string {
@@ -201,11 +297,160 @@ func (p *Parser) getLineContent(line int) string {
 }
 
 func (p *Parser) peekError(t lexer.TokenType) {
-	p.addDetailedError(p.peekToken, "expected next token to be %s, got %s instead", t, p.peekToken.Type)
+	p.addExpectedError(p.peekToken, string(t), "expected next token to be %s, got %s instead", t, p.peekToken.Type)
 }
 
 func (p *Parser) curError(t lexer.TokenType) {
-	p.addDetailedError(p.curToken, "expected token to be %s, got %s instead", t, p.curToken.Type)
+	p.addExpectedError(p.curToken, string(t), "expected token to be %s, got %s instead", t, p.curToken.Type)
+}
+
+// openBlock records that the construct starting at curToken needs an `akhir`.
+func (p *Parser) openBlock() {
+	p.blocks = append(p.blocks, p.curToken)
+}
+
+// closeBlock checks that tok is the `akhir` of the innermost open block.
+// A missing `akhir` is reported against the token that opened the block and
+// the block stays open so recovery can skip the rest of it.
+func (p *Parser) closeBlock(tok lexer.Token) bool {
+	if tok.Type != lexer.AKHIR {
+		opener := p.blocks[len(p.blocks)-1]
+		p.addExpectedError(tok, lexer.AKHIR, "expected AKHIR to close '%s' from %d:%d, got %s instead",
+			opener.Literal, opener.Line, opener.Column, tok.Type)
+		return false
+	}
+	p.blocks = p.blocks[:len(p.blocks)-1]
+	return true
+}
+
+// synchronize skips tokens after an error until parsing can resume: the
+// first statement on a later line, a block terminator, or EOF. Blocks opened
+// by the failed statement (unclosed) are skipped through their `akhir`.
+func (p *Parser) synchronize(depth int) {
+	// Errors reported at peekToken may sit on the next line already; that
+	// line can still hold a valid statement.
+	errLine := p.curToken.Line
+	if len(p.errors) > 0 && p.errors[len(p.errors)-1].Line < errLine {
+		errLine = p.errors[len(p.errors)-1].Line
+	}
+
+	unclosed := len(p.blocks) - depth
+	if unclosed > 0 && p.blocks[len(p.blocks)-1] == p.curToken {
+		p.nextToken() // The opener itself is already counted
+	}
+	p.blocks = p.blocks[:depth]
+
+	for !p.curTokenIs(lexer.EOF) {
+		if unclosed == 0 {
+			if isBlockEnd(p.curToken.Type) {
+				break
+			}
+			if p.curToken.Line > errLine && p.startsStatement(p.curToken.Type) {
+				break
+			}
+		}
+
+		if opensBlock(p.curToken.Type) {
+			unclosed++
+		} else if p.curTokenIs(lexer.AKHIR) && unclosed > 0 {
+			unclosed--
+			if unclosed == 0 {
+				errLine = 0 // The failed construct is closed: resume right after it
+			}
+		}
+		p.nextToken()
+	}
+
+	p.panicking = false
+}
+
+func (p *Parser) startsStatement(t lexer.TokenType) bool {
+	switch t {
+	case lexer.KEMBALIKAN, lexer.AMBIL, lexer.DARI, lexer.STRUKTUR, lexer.BERHENTI, lexer.LANJUT, lexer.TETAP, lexer.PILIHAN:
+		return true
+	}
+	_, ok := p.prefixParseFns[t]
+	return ok
+}
+
+func opensBlock(t lexer.TokenType) bool {
+	switch t {
+	case lexer.FUNGSI, lexer.JIKA, lexer.SELAMA, lexer.UNTUK, lexer.COCOKKAN, lexer.STRUKTUR, lexer.PILIHAN:
+		return true
+	}
+	return false
+}
+
+func isBlockEnd(t lexer.TokenType) bool {
+	switch t {
+	case lexer.AKHIR, lexer.LAINNYA, lexer.ATAU_JIKA, lexer.KASUS:
+		return true
+	}
+	return false
+}
+
+// tokenSpan returns the span from the first character of start to the last
+// character of end.
+func tokenSpan(start, end lexer.Token) Span {
+	return Span{
+		StartLine: start.Line,
+		StartCol:  start.Column,
+		EndLine:   end.EndLine,
+		EndCol:    end.EndColumn,
+		Offset:    start.Offset,
+		Length:    end.EndOffset - start.Offset,
+	}
+}
+
+// joinSpans returns the span from the start of a to the end of b.
+func joinSpans(a, b Span) Span {
+	return Span{
+		StartLine: a.StartLine,
+		StartCol:  a.StartCol,
+		EndLine:   b.EndLine,
+		EndCol:    b.EndCol,
+		Offset:    a.Offset,
+		Length:    b.Offset + b.Length - a.Offset,
+	}
+}
+
+// finishNode sets the span of node from start through curToken.
+func (p *Parser) finishNode(node Node, start lexer.Token) {
+	p.finishNodeAt(node, start, p.curToken)
+}
+
+// finishNodeAt sets the span of node from start through end unless a nested
+// parse already did, so a grouped expression keeps the span of its contents.
+func (p *Parser) finishNodeAt(node Node, start, end lexer.Token) {
+	if node == nil || !node.Span().IsZero() {
+		return
+	}
+	if end.EndOffset < start.Offset {
+		end = start
+	}
+	node.(interface{ SetSpan(Span) }).SetSpan(tokenSpan(start, end))
+}
+
+// curIdentifier returns an Identifier for curToken.
+func (p *Parser) curIdentifier() *Identifier {
+	ident := &Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	p.finishNode(ident, p.curToken)
+	return ident
+}
+
+// blockSpan covers the statements of a block. An empty block gets an empty
+// span where its body would start.
+func blockSpan(statements []Statement, at lexer.Token) Span {
+	if len(statements) == 0 {
+		return Span{
+			StartLine: at.Line,
+			StartCol:  at.Column,
+			EndLine:   at.Line,
+			EndCol:    at.Column,
+			Offset:    at.Offset,
+		}
+	}
+	return joinSpans(statements[0].Span(), statements[len(statements)-1].Span())
 }
 
 func (p *Parser) registerPrefix(tokenType lexer.TokenType, fn prefixParseFn) {
@@ -221,13 +466,38 @@ func (p *Parser) ParseProgram() *Program {
 	program.Statements = []Statement{}
 
 	for p.curToken.Type != lexer.EOF {
+		if isBlockEnd(p.curToken.Type) {
+			// A terminator with no open block: skip it (and the rest of an
+			// orphaned lainnya/atau_jika/kasus branch) as a single mistake.
+			p.addExpectedError(p.curToken, "statement", "unexpected %s outside of a block", p.curToken.Type)
+			if p.curTokenIs(lexer.AKHIR) {
+				p.nextToken()
+				p.panicking = false
+			} else {
+				p.openBlock()
+				p.synchronize(len(p.blocks) - 1)
+			}
+			continue
+		}
+
+		depth := len(p.blocks)
+		start := p.curToken
 		stmt := p.parseStatement()
 		if stmt != nil {
+			p.finishNode(stmt, start)
 			program.Statements = append(program.Statements, stmt)
 		}
+		if p.panicking {
+			p.synchronize(depth)
+			continue
+		}
 		p.nextToken()
 	}
 
+	if len(program.Statements) > 0 {
+		program.Range = blockSpan(program.Statements, p.curToken)
+	}
+
 	return program
 }
 
@@ -241,16 +511,20 @@ func (p *Parser) parseStatement() Statement {
 		return p.parseFromImportStatement()
 	case lexer.STRUKTUR:
 		return p.parseStructStatement()
+	case lexer.PILIHAN:
+		return p.parseEnumStatement()
 	case lexer.BERHENTI:
 		return p.parseBreakStatement()
 	case lexer.LANJUT:
 		return p.parseContinueStatement()
+	case lexer.TETAP:
+		return p.parseConstStatement()
 	default:
 		return p.parseExpressionOrAssignmentStatement()
 	}
 }
 
-func (p *Parser) parseImportStatement() *ImportStatement {
+func (p *Parser) parseImportStatement() Statement {
 	stmt := &ImportStatement{Token: p.curToken}
 
 	if !p.expectPeek(lexer.STRING) {
@@ -282,7 +556,7 @@ func (p *Parser) parseContinueStatement() *ContinueStatement {
 	return stmt
 }
 
-func (p *Parser) parseFromImportStatement() *ImportStatement {
+func (p *Parser) parseFromImportStatement() Statement {
 	stmt := &ImportStatement{Token: p.curToken}
 
 	if !p.expectPeek(lexer.STRING) {
@@ -316,14 +590,15 @@ func (p *Parser) parseFromImportStatement() *ImportStatement {
 	return stmt
 }
 
-func (p *Parser) parseStructStatement() *StructStatement {
+func (p *Parser) parseStructStatement() Statement {
 	stmt := &StructStatement{Token: p.curToken}
 	stmt.Doc = p.curComment
+	p.openBlock()
 
 	if !p.expectPeek(lexer.IDENT) {
 		return nil
 	}
-	stmt.Name = &Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	stmt.Name = p.curIdentifier()
 
 	p.nextToken()
 
@@ -334,18 +609,179 @@ func (p *Parser) parseStructStatement() *StructStatement {
 		}
 
 		if p.curTokenIs(lexer.IDENT) {
-			field := &Identifier{Token: p.curToken, Value: p.curToken.Literal}
-			stmt.Fields = append(stmt.Fields, field)
+			if stmt.Method(p.curToken.Literal) != nil {
+				p.addMemberError(p.curToken, "field %s has the same name as a method of %s", p.curToken.Literal, stmt.Name.Value)
+			}
+			stmt.Fields = append(stmt.Fields, p.curIdentifier())
+			if p.peekTokenIs(lexer.COLON) {
+				p.nextToken()
+				if stmt.FieldTypes == nil {
+					stmt.FieldTypes = make([]*TypeAnnotation, len(stmt.Fields)-1)
+				}
+				stmt.FieldTypes = append(stmt.FieldTypes, p.parseTypeAnnotation())
+			} else if stmt.FieldTypes != nil {
+				stmt.FieldTypes = append(stmt.FieldTypes, nil)
+			}
+			p.nextToken()
+		} else if p.curTokenIs(lexer.FUNGSI) {
+			if method := p.parseMethod(stmt); method != nil {
+				stmt.Methods = append(stmt.Methods, method)
+			}
 			p.nextToken()
 		} else {
 			p.nextToken()
 		}
 	}
 
-	if !p.curTokenIs(lexer.AKHIR) {
-		p.peekError(lexer.AKHIR)
+	if !p.closeBlock(p.curToken) {
+		return nil
+	}
+
+	return stmt
+}
+
+// parseEnumStatement parses `pilihan Nama` followed by its variants, each a
+// name with an optional parenthesized list of fields.
+func (p *Parser) parseEnumStatement() Statement {
+	stmt := &EnumStatement{Token: p.curToken}
+	stmt.Doc = p.curComment
+	p.openBlock()
+
+	if !p.expectPeek(lexer.IDENT) {
+		return nil
+	}
+	stmt.Name = p.curIdentifier()
+
+	p.nextToken()
+
+	for !p.curTokenIs(lexer.AKHIR) && !p.curTokenIs(lexer.EOF) {
+		if !p.curTokenIs(lexer.IDENT) {
+			if !p.curTokenIs(lexer.SEMICOLON) {
+				p.addDetailedError(p.curToken, "expected a variant name in pilihan %s, got %s", stmt.Name.Value, p.curToken.Literal)
+			}
+			p.nextToken()
+			continue
+		}
+
+		start := p.curToken
+		variant := &EnumVariant{Token: p.curToken, Name: p.curIdentifier()}
+		if stmt.Variant(variant.Name.Value) != nil {
+			p.addDetailedError(p.curToken, "variant %s is defined more than once in %s", variant.Name.Value, stmt.Name.Value)
+		}
+		if p.peekTokenIs(lexer.LPAREN) {
+			p.nextToken()
+			variant.Fields = p.parseVariantFields()
+		}
+		p.finishNode(variant, start)
+		stmt.Variants = append(stmt.Variants, variant)
+		p.nextToken()
+	}
+
+	if len(stmt.Variants) == 0 {
+		p.addDetailedError(stmt.Token, "pilihan %s must have at least one variant", stmt.Name.Value)
+	}
+	if !p.closeBlock(p.curToken) {
+		return nil
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseVariantFields() []*Identifier {
+	fields := []*Identifier{}
+	if p.peekTokenIs(lexer.RPAREN) {
+		p.nextToken()
+		return fields
+	}
+
+	for {
+		if !p.expectPeek(lexer.IDENT) {
+			return nil
+		}
+		for _, f := range fields {
+			if f.Value == p.curToken.Literal {
+				p.addDetailedError(p.curToken, "duplicate field %s", f.Value)
+			}
+		}
+		fields = append(fields, p.curIdentifier())
+		if !p.peekTokenIs(lexer.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	if !p.expectPeek(lexer.RPAREN) {
+		return nil
+	}
+	return fields
+}
+
+// parseMethod parses a `fungsi` inside a struktur body. A method must be named
+// and may not share its name with a field or another method.
+func (p *Parser) parseMethod(stmt *StructStatement) *FunctionLiteral {
+	start := p.curToken
+	lit, _ := p.parseFunctionLiteral().(*FunctionLiteral)
+	if lit == nil {
+		return nil
+	}
+	p.finishNode(lit, start)
+
+	if lit.Name == "" {
+		p.addMemberError(start, "method in struktur %s must have a name", stmt.Name.Value)
 		return nil
 	}
+	for _, f := range stmt.Fields {
+		if f.Value == lit.Name {
+			p.addMemberError(start, "method %s has the same name as a field of %s", lit.Name, stmt.Name.Value)
+			return nil
+		}
+	}
+	if stmt.Method(lit.Name) != nil {
+		p.addMemberError(start, "method %s is defined more than once in %s", lit.Name, stmt.Name.Value)
+		return nil
+	}
+	return lit
+}
+
+func (p *Parser) parseConstStatement() Statement {
+	stmt := &ConstStatement{Token: p.curToken}
+
+	if !p.expectPeek(lexer.IDENT) {
+		return nil
+	}
+	stmt.Name = p.curIdentifier()
+
+	if !p.expectPeek(lexer.ASSIGN) {
+		return nil
+	}
+	p.nextToken()
+
+	stmt.Value = p.parseExpression(LOWEST)
+
+	if p.peekTokenIs(lexer.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+// parseTypedAssignment parses `x: string = nilai`, which declares the type
+// of x.
+func (p *Parser) parseTypedAssignment() Statement {
+	name := p.curIdentifier()
+	p.nextToken()
+	typ := p.parseTypeAnnotation()
+	if typ == nil || !p.expectPeek(lexer.ASSIGN) {
+		return nil
+	}
+	stmt := &AssignmentStatement{Token: p.curToken, Name: name, Type: typ}
+	p.nextToken()
+
+	stmt.Value = p.parseExpression(LOWEST)
+
+	if p.peekTokenIs(lexer.SEMICOLON) {
+		p.nextToken()
+	}
 
 	return stmt
 }
@@ -365,12 +801,27 @@ func (p *Parser) parseReturnStatement() *ReturnStatement {
 }
 
 func (p *Parser) parseExpressionOrAssignmentStatement() Statement {
+	if p.startsDestructuring() {
+		return p.parseDestructuringStatement()
+	}
+	if p.curTokenIs(lexer.IDENT) && p.peekTokenIs(lexer.COLON) {
+		return p.parseTypedAssignment()
+	}
+
 	startToken := p.curToken
 	expr := p.parseExpression(LOWEST)
 
-	if p.peekTokenIs(lexer.ASSIGN) {
+	if p.peekTokenIs(lexer.ASSIGN) || isCompoundAssign(p.peekToken.Type) {
 		p.nextToken() // move to =
 		assignToken := p.curToken
+		if isCompoundAssign(assignToken.Type) {
+			if !assignToken.HasLeadingSpace {
+				p.addWhitespaceError(assignToken, "Assignment operator '%s' requires space before it", assignToken.Literal)
+			}
+			if !p.peekToken.HasLeadingSpace {
+				p.addWhitespaceError(assignToken, "Assignment operator '%s' requires space after it", assignToken.Literal)
+			}
+		}
 		p.nextToken() // move to RHS
 
 		val := p.parseExpression(LOWEST)
@@ -391,15 +842,136 @@ func (p *Parser) parseExpressionOrAssignmentStatement() Statement {
 	return stmt
 }
 
+// startsDestructuring reports whether the statement at curToken is a
+// destructuring assignment: a `[...]`, `{...}` or `Nama{...}` followed by `=`.
+// It looks ahead on a copy of the lexer.
+func (p *Parser) startsDestructuring() bool {
+	depth := 0
+	switch {
+	case p.curTokenIs(lexer.LBRACKET), p.curTokenIs(lexer.LBRACE):
+		depth = 1
+	case p.curTokenIs(lexer.IDENT) && p.peekTokenIs(lexer.LBRACE) && !p.peekToken.HasLeadingSpace:
+	default:
+		return false
+	}
+
+	return closesBeforeAssign(p.l.Clone(), p.peekToken, depth)
+}
+
+// peekStartsDestructuring reports whether a `[` at peekToken opens a
+// destructuring assignment rather than indexing the expression before it.
+func (p *Parser) peekStartsDestructuring() bool {
+	if !p.peekTokenIs(lexer.LBRACKET) || p.peekToken.Line == p.curToken.EndLine {
+		return false
+	}
+	l := p.l.Clone()
+	tok := l.NextToken()
+	for tok.Type == lexer.COMMENT {
+		tok = l.NextToken()
+	}
+	return closesBeforeAssign(l, tok, 1)
+}
+
+// closesBeforeAssign scans from tok, with depth brackets already open, and
+// reports whether the token after the closing bracket is `=`.
+func closesBeforeAssign(l *lexer.Lexer, tok lexer.Token, depth int) bool {
+	next := func() lexer.Token {
+		t := tok
+		tok = l.NextToken()
+		for tok.Type == lexer.COMMENT {
+			tok = l.NextToken()
+		}
+		return t
+	}
+	for {
+		t := next()
+		switch t.Type {
+		case lexer.LBRACKET, lexer.LBRACE, lexer.LPAREN, lexer.INTERP_START:
+			depth++
+		case lexer.RBRACKET, lexer.RBRACE, lexer.RPAREN:
+			depth--
+			if depth == 0 {
+				return next().Type == lexer.ASSIGN
+			}
+		case lexer.EOF:
+			return false
+		}
+	}
+}
+
+// parseDestructuringStatement parses `pola = nilai`, where the pattern may
+// only contain names to bind.
+func (p *Parser) parseDestructuringStatement() Statement {
+	start := p.curToken
+	target := p.parsePattern()
+	if target == nil {
+		return nil
+	}
+	if !bindsOnly(target) {
+		p.addDetailedError(start, "destructuring can only bind names, got %s", target.String())
+		return nil
+	}
+	if !p.expectPeek(lexer.ASSIGN) {
+		return nil
+	}
+	stmt := &DestructuringStatement{Token: p.curToken, Target: target}
+
+	p.nextToken()
+	stmt.Value = p.parseExpression(LOWEST)
+
+	if p.peekTokenIs(lexer.SEMICOLON) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+// bindsOnly reports whether pattern is made of bindings alone; literal and
+// galat patterns can be matched but not assigned to.
+func bindsOnly(pattern Pattern) bool {
+	switch pat := pattern.(type) {
+	case *BindingPattern:
+		return true
+	case *ArrayPattern:
+		for _, el := range pat.Elements {
+			if !bindsOnly(el) {
+				return false
+			}
+		}
+		return true
+	case *HashPattern:
+		for _, v := range pat.Values {
+			if !bindsOnly(v) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// isCompoundAssign reports whether t is one of `+= -= *= /=`.
+func isCompoundAssign(t lexer.TokenType) bool {
+	switch t {
+	case lexer.PLUS_ASSIGN, lexer.MINUS_ASSIGN, lexer.ASTERISK_ASSIGN, lexer.SLASH_ASSIGN:
+		return true
+	}
+	return false
+}
+
 func (p *Parser) parseExpression(precedence int) Expression {
 	prefix := p.prefixParseFns[p.curToken.Type]
 	if prefix == nil {
 		p.noPrefixParseFnError(p.curToken.Type)
 		return nil
 	}
+	start := p.curToken
 	leftExp := prefix()
+	p.finishNode(leftExp, start)
 
 	for !p.peekTokenIs(lexer.SEMICOLON) && precedence < p.peekPrecedence() {
+		if p.peekStartsDestructuring() {
+			return leftExp
+		}
 		infix := p.infixParseFns[p.peekToken.Type]
 		if infix == nil {
 			return leftExp
@@ -407,11 +979,21 @@ func (p *Parser) parseExpression(precedence int) Expression {
 
 		p.nextToken()
 		leftExp = infix(leftExp)
+		p.finishNode(leftExp, start)
 	}
 
 	return leftExp
 }
 
+// Precedence returns how tightly t binds as an infix operator, or LOWEST
+// when it is not one.
+func Precedence(t lexer.TokenType) int {
+	if p, ok := precedences[t]; ok {
+		return p
+	}
+	return LOWEST
+}
+
 func (p *Parser) peekPrecedence() int {
 	if p, ok := precedences[p.peekToken.Type]; ok {
 		return p
@@ -427,13 +1009,17 @@ func (p *Parser) curPrecedence() int {
 }
 
 func (p *Parser) parseIdentifier() Expression {
-	return &Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	return p.curIdentifier()
 }
 
 func (p *Parser) parseIntegerLiteral() Expression {
 	lit := &IntegerLiteral{Token: p.curToken}
 
-	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
+	value, err := parseInteger(p.curToken.Literal)
+	if errors.Is(err, strconv.ErrRange) {
+		p.addDetailedError(p.curToken, "integer literal %s is out of range for int64", p.curToken.Literal)
+		return nil
+	}
 	if err != nil {
 		p.addDetailedError(p.curToken, "could not parse %q as integer", p.curToken.Literal)
 		return nil
@@ -443,10 +1029,23 @@ func (p *Parser) parseIntegerLiteral() Expression {
 	return lit
 }
 
+// parseInteger reads an integer literal. Prefixed literals (0x, 0b, 0o) use
+// Go's rules; plain ones are decimal even with leading zeros.
+func parseInteger(literal string) (int64, error) {
+	if len(literal) > 1 && literal[0] == '0' && strings.ContainsRune("xXbBoO", rune(literal[1])) {
+		return strconv.ParseInt(literal, 0, 64)
+	}
+	return strconv.ParseInt(strings.ReplaceAll(literal, "_", ""), 10, 64)
+}
+
 func (p *Parser) parseFloatLiteral() Expression {
 	lit := &FloatLiteral{Token: p.curToken}
 
-	value, err := strconv.ParseFloat(p.curToken.Literal, 64)
+	value, err := strconv.ParseFloat(strings.ReplaceAll(p.curToken.Literal, "_", ""), 64)
+	if errors.Is(err, strconv.ErrRange) {
+		p.addDetailedError(p.curToken, "float literal %s is out of range for float64", p.curToken.Literal)
+		return nil
+	}
 	if err != nil {
 		p.addDetailedError(p.curToken, "could not parse %q as float", p.curToken.Literal)
 		return nil
@@ -457,8 +1056,15 @@ func (p *Parser) parseFloatLiteral() Expression {
 }
 
 func (p *Parser) parseStringLiteral() Expression {
+	continues := func() bool {
+		if !p.peekTokenIs(lexer.INTERP_START) && !p.peekTokenIs(lexer.STRING) {
+			return false
+		}
+		return !p.inGuard || p.peekToken.Line == p.curToken.Line
+	}
+
 	// Optimization: If simple string (starts with STRING and no following parts)
-	if p.curTokenIs(lexer.STRING) && p.peekToken.Type != lexer.INTERP_START && p.peekToken.Type != lexer.STRING {
+	if p.curTokenIs(lexer.STRING) && !continues() {
 		return &StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
 	}
 
@@ -466,7 +1072,9 @@ func (p *Parser) parseStringLiteral() Expression {
 
 	processToken := func() bool {
 		if p.curTokenIs(lexer.STRING) {
-			is.Parts = append(is.Parts, &StringLiteral{Token: p.curToken, Value: p.curToken.Literal})
+			part := &StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
+			p.finishNode(part, p.curToken)
+			is.Parts = append(is.Parts, part)
 			return true
 		}
 		if p.curTokenIs(lexer.INTERP_START) {
@@ -486,7 +1094,7 @@ func (p *Parser) parseStringLiteral() Expression {
 	}
 
 	for {
-		if p.peekTokenIs(lexer.INTERP_START) || p.peekTokenIs(lexer.STRING) {
+		if continues() {
 			p.nextToken()
 			if !processToken() {
 				return nil
@@ -566,10 +1174,31 @@ func (p *Parser) parseHashLiteral() Expression {
 }
 
 func (p *Parser) parseIndexExpression(left Expression) Expression {
-	exp := &IndexExpression{Token: p.curToken, Left: left}
+	tok := p.curToken
 
 	p.nextToken()
-	exp.Index = p.parseExpression(LOWEST)
+	if p.curTokenIs(lexer.COLON) {
+		return p.parseSliceEnd(&SliceExpression{Token: tok, Left: left})
+	}
+	index := p.parseExpression(LOWEST)
+	if p.peekTokenIs(lexer.COLON) {
+		p.nextToken()
+		return p.parseSliceEnd(&SliceExpression{Token: tok, Left: left, Start: index})
+	}
+
+	if !p.expectPeek(lexer.RBRACKET) {
+		return nil
+	}
+
+	return &IndexExpression{Token: tok, Left: left, Index: index}
+}
+
+// parseSliceEnd parses the optional end bound of a slice after its colon.
+func (p *Parser) parseSliceEnd(exp *SliceExpression) Expression {
+	if !p.peekTokenIs(lexer.RBRACKET) {
+		p.nextToken()
+		exp.End = p.parseExpression(LOWEST)
+	}
 
 	if !p.expectPeek(lexer.RBRACKET) {
 		return nil
@@ -578,6 +1207,21 @@ func (p *Parser) parseIndexExpression(left Expression) Expression {
 	return exp
 }
 
+// parseRangeExpression parses start..end and start..<end. Like `.`, the
+// range operators need no surrounding spaces.
+func (p *Parser) parseRangeExpression(left Expression) Expression {
+	expression := &RangeExpression{
+		Token:     p.curToken,
+		Start:     left,
+		Exclusive: p.curTokenIs(lexer.DOTDOT_LT),
+	}
+
+	p.nextToken()
+	expression.End = p.parseExpression(RANGE)
+
+	return expression
+}
+
 func (p *Parser) parsePrefixExpression() Expression {
 	expression := &PrefixExpression{
 		Token:    p.curToken,
@@ -591,8 +1235,19 @@ func (p *Parser) parsePrefixExpression() Expression {
 	return expression
 }
 
+func (p *Parser) parseTryExpression() Expression {
+	expression := &TryExpression{Token: p.curToken}
+
+	p.nextToken()
+
+	expression.Value = p.parseExpression(PREFIX)
+
+	return expression
+}
+
 func (p *Parser) parseWhileExpression() Expression {
 	expression := &WhileExpression{Token: p.curToken}
+	p.openBlock()
 	p.nextToken() // eat selama
 
 	expression.Condition = p.parseExpression(LOWEST)
@@ -601,16 +1256,274 @@ func (p *Parser) parseWhileExpression() Expression {
 
 	expression.Body = p.parseBlockStatement()
 
-	if p.curTokenIs(lexer.AKHIR) {
-		// Consumed by loop? No, loop breaks.
-		// We verify it is AKHIR, but do NOT consume it past this node.
-	} else {
-		p.curError(lexer.AKHIR)
+	// We verify it is AKHIR, but do NOT consume it past this node.
+	p.closeBlock(p.curToken)
+
+	return expression
+}
+
+func (p *Parser) parseForInExpression() Expression {
+	expression := &ForInExpression{Token: p.curToken}
+	p.openBlock()
+
+	if !p.expectPeek(lexer.IDENT) {
+		return nil
+	}
+	expression.Value = p.curIdentifier()
+
+	if p.peekTokenIs(lexer.COMMA) {
+		p.nextToken() // eat ,
+		if !p.expectPeek(lexer.IDENT) {
+			return nil
+		}
+		expression.Key = expression.Value
+		expression.Value = p.curIdentifier()
+	}
+
+	// `dalam` is contextual so existing code may keep using it as a name
+	if !p.peekTokenIs(lexer.IDENT) || p.peekToken.Literal != "dalam" {
+		p.addExpectedError(p.peekToken, "dalam", "expected 'dalam' after loop variable, got %s instead", p.peekToken.Type)
+		return nil
+	}
+	p.nextToken() // move to dalam
+	p.nextToken() // eat dalam
+
+	expression.Iterable = p.parseExpression(LOWEST)
+
+	p.nextToken() // move to block start
+
+	expression.Body = p.parseBlockStatement()
+
+	p.closeBlock(p.curToken)
+
+	return expression
+}
+
+func (p *Parser) parseMatchExpression() Expression {
+	expression := &MatchExpression{Token: p.curToken}
+	p.openBlock()
+
+	p.nextToken()
+	expression.Subject = p.parseExpression(LOWEST)
+
+	p.nextToken() // move to first kasus
+
+	for p.curTokenIs(lexer.KASUS) {
+		arm := &MatchArm{Token: p.curToken}
+
+		p.nextToken()
+		arm.Pattern = p.parsePattern()
+		if arm.Pattern == nil {
+			return nil
+		}
+
+		if p.peekTokenIs(lexer.JIKA) {
+			p.nextToken() // move to jika
+			p.nextToken() // eat jika
+			p.inGuard = true
+			arm.Guard = p.parseExpression(LOWEST)
+			p.inGuard = false
+		}
+
+		p.nextToken() // move to block start
+		arm.Body = p.parseBlockStatement()
+		p.finishNodeAt(arm, arm.Token, p.prevToken)
+
+		expression.Arms = append(expression.Arms, arm)
+	}
+
+	if p.curTokenIs(lexer.LAINNYA) {
+		p.nextToken()
+		expression.Alternative = p.parseBlockStatement()
+	}
+
+	if !p.closeBlock(p.curToken) {
+		return nil
 	}
 
 	return expression
 }
 
+// parsePattern parses the pattern of a `kasus` arm starting at curToken.
+func (p *Parser) parsePattern() Pattern {
+	start := p.curToken
+	pattern := p.parsePatternKind()
+	if pattern != nil {
+		p.finishNode(pattern, start)
+	}
+	return pattern
+}
+
+func (p *Parser) parsePatternKind() Pattern {
+	switch p.curToken.Type {
+	case lexer.STRING:
+		// Read the token directly: adjacent strings would otherwise merge
+		// with the arm body.
+		value := &StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
+		p.finishNode(value, p.curToken)
+		return &LiteralPattern{Token: p.curToken, Value: value}
+	case lexer.INT, lexer.FLOAT, lexer.BENAR, lexer.SALAH, lexer.KOSONG:
+		value := p.prefixParseFns[p.curToken.Type]()
+		if value == nil {
+			return nil
+		}
+		p.finishNode(value, p.curToken)
+		return &LiteralPattern{Token: p.curToken, Value: value}
+	case lexer.MINUS:
+		token := p.curToken
+		if !p.peekTokenIs(lexer.INT) && !p.peekTokenIs(lexer.FLOAT) {
+			p.addExpectedError(p.peekToken, "number", "expected number after '-' in pattern, got %s instead", p.peekToken.Type)
+			return nil
+		}
+		p.nextToken()
+		right := p.prefixParseFns[p.curToken.Type]()
+		if right == nil {
+			return nil
+		}
+		p.finishNode(right, p.curToken)
+		value := &PrefixExpression{Token: token, Operator: "-", Right: right}
+		p.finishNode(value, token)
+		return &LiteralPattern{Token: token, Value: value}
+	case lexer.LBRACKET:
+		return p.parseArrayPattern()
+	case lexer.LBRACE:
+		return p.parseHashPattern(nil)
+	case lexer.IDENT:
+		ident := p.curIdentifier()
+		if ident.Value == "galat" {
+			return p.parseErrorPattern()
+		}
+		// `Nama{...}` (no space before the brace) matches struct instances
+		if p.peekTokenIs(lexer.LBRACE) && !p.peekToken.HasLeadingSpace {
+			p.nextToken()
+			return p.parseHashPattern(ident)
+		}
+		return &BindingPattern{Token: p.curToken, Name: ident}
+	}
+
+	p.addExpectedError(p.curToken, "pattern", "unexpected %s in pattern", p.curToken.Type)
+	return nil
+}
+
+func (p *Parser) parseArrayPattern() Pattern {
+	pattern := &ArrayPattern{Token: p.curToken}
+
+	for !p.peekTokenIs(lexer.RBRACKET) {
+		p.nextToken()
+
+		if p.curTokenIs(lexer.ELLIPSIS) {
+			pattern.HasRest = true
+			if p.peekTokenIs(lexer.IDENT) {
+				p.nextToken()
+				pattern.Rest = p.curIdentifier()
+			}
+			if !p.peekTokenIs(lexer.RBRACKET) {
+				p.addExpectedError(p.peekToken, lexer.RBRACKET, "rest pattern must be the last element, got %s instead", p.peekToken.Type)
+				return nil
+			}
+			break
+		}
+
+		el := p.parsePattern()
+		if el == nil {
+			return nil
+		}
+		pattern.Elements = append(pattern.Elements, el)
+
+		if !p.peekTokenIs(lexer.RBRACKET) && !p.expectPeek(lexer.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(lexer.RBRACKET) {
+		return nil
+	}
+	return pattern
+}
+
+func (p *Parser) parseHashPattern(schema *Identifier) Pattern {
+	pattern := &HashPattern{Token: p.curToken, Schema: schema}
+	if schema != nil {
+		pattern.Token = schema.Token
+	}
+
+	for !p.peekTokenIs(lexer.RBRACE) {
+		p.nextToken()
+
+		var key Expression
+		switch p.curToken.Type {
+		case lexer.IDENT, lexer.STRING:
+			key = &StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
+		case lexer.INT:
+			key = p.parseIntegerLiteral()
+			if key == nil {
+				return nil
+			}
+		default:
+			p.addExpectedError(p.curToken, "pattern key", "unexpected %s as pattern key", p.curToken.Type)
+			return nil
+		}
+		p.finishNode(key, p.curToken)
+
+		if p.peekTokenIs(lexer.COLON) {
+			p.nextToken() // move to :
+			p.nextToken() // eat :
+			value := p.parsePattern()
+			if value == nil {
+				return nil
+			}
+			pattern.Values = append(pattern.Values, value)
+		} else if p.curTokenIs(lexer.IDENT) {
+			// Shorthand `{nama}` binds the value to a variable of the same name
+			binding := &BindingPattern{Token: p.curToken, Name: p.curIdentifier()}
+			p.finishNode(binding, p.curToken)
+			pattern.Values = append(pattern.Values, binding)
+		} else {
+			p.peekError(lexer.COLON)
+			return nil
+		}
+		pattern.Keys = append(pattern.Keys, key)
+
+		if !p.peekTokenIs(lexer.RBRACE) && !p.expectPeek(lexer.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(lexer.RBRACE) {
+		return nil
+	}
+	return pattern
+}
+
+func (p *Parser) parseErrorPattern() Pattern {
+	pattern := &ErrorPattern{Token: p.curToken}
+
+	if !p.peekTokenIs(lexer.LPAREN) || p.peekToken.HasLeadingSpace {
+		return pattern
+	}
+	p.nextToken() // move to (
+
+	p.nextToken()
+	pattern.Message = p.parsePattern()
+	if pattern.Message == nil {
+		return nil
+	}
+
+	if p.peekTokenIs(lexer.COMMA) {
+		p.nextToken() // move to ,
+		p.nextToken() // eat ,
+		pattern.Code = p.parsePattern()
+		if pattern.Code == nil {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(lexer.RPAREN) {
+		return nil
+	}
+	return pattern
+}
+
 func (p *Parser) parseInfixExpression(left Expression) Expression {
 	expression := &InfixExpression{
 		Token:    p.curToken,
@@ -621,14 +1534,17 @@ func (p *Parser) parseInfixExpression(left Expression) Expression {
 	// Strict Whitespace Check
 	if isBinaryOp(p.curToken.Type) {
 		if !p.curToken.HasLeadingSpace {
-			p.addDetailedError(p.curToken, "Binary operator '%s' requires space before it", p.curToken.Literal)
+			p.addWhitespaceError(p.curToken, "Binary operator '%s' requires space before it", p.curToken.Literal)
 		}
 		if !p.peekToken.HasLeadingSpace {
-			p.addDetailedError(p.curToken, "Binary operator '%s' requires space after it", p.curToken.Literal)
+			p.addWhitespaceError(p.curToken, "Binary operator '%s' requires space after it", p.curToken.Literal)
 		}
 	}
 
 	precedence := p.curPrecedence()
+	if p.curTokenIs(lexer.POWER) {
+		precedence-- // Right-associative: 2 ** 3 ** 2 == 2 ** (3 ** 2)
+	}
 	p.nextToken()
 	expression.Right = p.parseExpression(precedence)
 
@@ -638,8 +1554,9 @@ func (p *Parser) parseInfixExpression(left Expression) Expression {
 func isBinaryOp(t lexer.TokenType) bool {
 	switch t {
 	case lexer.PLUS, lexer.MINUS, lexer.SLASH, lexer.ASTERISK,
+		lexer.PERCENT, lexer.FLOORDIV, lexer.POWER,
 		lexer.EQ, lexer.NOT_EQ, lexer.LT, lexer.GT, lexer.LTE, lexer.GTE,
-		lexer.DAN, lexer.ATAU,
+		lexer.DAN, lexer.ATAU, lexer.NULL_COALESCE, lexer.ERROR_COALESCE,
 		lexer.AND, lexer.OR, lexer.XOR, lexer.LSHIFT, lexer.RSHIFT:
 		return true
 	}
@@ -660,7 +1577,11 @@ func (p *Parser) parseGroupedExpression() Expression {
 
 func (p *Parser) parseIfExpression() Expression {
 	expression := &IfExpression{Token: p.curToken}
-	// curToken is JIKA or ATAU_JIKA
+	// curToken is JIKA or ATAU_JIKA; an atau_jika chain shares the akhir of its jika
+	opens := p.curTokenIs(lexer.JIKA)
+	if opens {
+		p.openBlock()
+	}
 	p.nextToken() // eat jika/atau_jika
 
 	expression.Condition = p.parseExpression(LOWEST)
@@ -673,29 +1594,21 @@ func (p *Parser) parseIfExpression() Expression {
 	if p.curTokenIs(lexer.LAINNYA) {
 		p.nextToken() // eat lainnya
 		expression.Alternative = p.parseBlockStatement()
-
-		// Expect AKHIR after lainnya block
-		if p.curTokenIs(lexer.AKHIR) {
-			// Do not consume
-		} else {
-			p.curError(lexer.AKHIR)
-		}
 	} else if p.curTokenIs(lexer.ATAU_JIKA) {
 		// chain
+		start := p.curToken
 		child := p.parseIfExpression()
-		expression.Alternative = &BlockStatement{
-			Statements: []Statement{
-				&ExpressionStatement{Expression: child},
-			},
-		}
+		p.finishNode(child, start)
+		stmt := &ExpressionStatement{Token: start, Expression: child}
+		stmt.Range = child.Span()
+		expression.Alternative = &BlockStatement{Token: start, Statements: []Statement{stmt}}
+		expression.Alternative.Range = child.Span()
 		// child parseIfExpression finishes at AKHIR.
-	} else {
-		// Expect AKHIR
-		if p.curTokenIs(lexer.AKHIR) {
-			// Do not consume
-		} else {
-			p.curError(lexer.AKHIR)
-		}
+	}
+
+	// Expect AKHIR (do not consume)
+	if opens {
+		p.closeBlock(p.curToken)
 	}
 
 	return expression
@@ -704,6 +1617,7 @@ func (p *Parser) parseIfExpression() Expression {
 func (p *Parser) parseFunctionLiteral() Expression {
 	lit := &FunctionLiteral{Token: p.curToken}
 	lit.Doc = p.curComment
+	p.openBlock()
 
 	if p.peekTokenIs(lexer.IDENT) {
 		p.nextToken()
@@ -714,46 +1628,114 @@ func (p *Parser) parseFunctionLiteral() Expression {
 		return nil
 	}
 
-	lit.Parameters = p.parseFunctionParameters()
+	p.parseFunctionParameters(lit)
+
+	if p.peekTokenIs(lexer.ARROW) {
+		p.nextToken()
+		lit.ReturnType = p.parseTypeAnnotation()
+	}
 
 	p.nextToken() // eat ) to move to block
 
 	lit.Body = p.parseBlockStatement()
 
-	if p.curTokenIs(lexer.AKHIR) {
-		// Do not consume
-	} else {
-		p.curError(lexer.AKHIR)
-	}
+	// Do not consume
+	p.closeBlock(p.curToken)
 
 	return lit
 }
 
-func (p *Parser) parseFunctionParameters() []*Identifier {
-	identifiers := []*Identifier{}
+// parseFunctionParameters fills in the parameters of lit, as in
+// `(a: integer, b = 10, ...sisa)`. Parameters with a default value must come
+// after the required ones, and only the last parameter may be variadic.
+func (p *Parser) parseFunctionParameters(lit *FunctionLiteral) {
+	lit.Parameters = []*Identifier{}
 
 	if p.peekTokenIs(lexer.RPAREN) {
 		p.nextToken()
-		return identifiers
+		return
 	}
 
-	p.nextToken()
+	for {
+		p.nextToken()
 
-	ident := &Identifier{Token: p.curToken, Value: p.curToken.Literal}
-	identifiers = append(identifiers, ident)
+		if lit.Variadic {
+			rest := lit.Parameters[len(lit.Parameters)-1]
+			p.addDetailedError(rest.Token, "variadic parameter ...%s must be the last parameter", rest.Value)
+		}
 
-	for p.peekTokenIs(lexer.COMMA) {
-		p.nextToken()
+		variadic := p.curTokenIs(lexer.ELLIPSIS)
+		if variadic && !p.expectPeek(lexer.IDENT) {
+			return
+		}
+		ident := p.curIdentifier()
+		lit.Parameters = append(lit.Parameters, ident)
+
+		var typ *TypeAnnotation
+		if p.peekTokenIs(lexer.COLON) {
+			p.nextToken()
+			if variadic {
+				p.addDetailedError(p.curToken, "variadic parameter ...%s cannot have a type", ident.Value)
+			}
+			typ = p.parseTypeAnnotation()
+		}
+		if typ != nil && lit.Types == nil {
+			lit.Types = make([]*TypeAnnotation, len(lit.Parameters)-1)
+		}
+		if lit.Types != nil {
+			lit.Types = append(lit.Types, typ)
+		}
+
+		var def Expression
+		if p.peekTokenIs(lexer.ASSIGN) {
+			p.nextToken()
+			if variadic {
+				p.addDetailedError(p.curToken, "variadic parameter ...%s cannot have a default value", ident.Value)
+			}
+			p.nextToken()
+			def = p.parseExpression(LOWEST)
+		} else if len(lit.Defaults) > 0 && !variadic {
+			p.addDetailedError(ident.Token, "parameter %s needs a default value because it follows a parameter with one", ident.Value)
+		}
+
+		if def != nil && lit.Defaults == nil {
+			lit.Defaults = make([]Expression, len(lit.Parameters)-1)
+		}
+		if lit.Defaults != nil {
+			lit.Defaults = append(lit.Defaults, def)
+		}
+		lit.Variadic = variadic
+
+		if !p.peekTokenIs(lexer.COMMA) {
+			break
+		}
 		p.nextToken()
-		ident := &Identifier{Token: p.curToken, Value: p.curToken.Literal}
-		identifiers = append(identifiers, ident)
 	}
 
-	if !p.expectPeek(lexer.RPAREN) {
-		return nil
-	}
+	p.expectPeek(lexer.RPAREN)
+}
 
-	return identifiers
+// parseTypeAnnotation parses the type after a `:` or `->`, such as `integer`
+// or `integer | galat`. A name is any identifier (a struktur, or a built-in
+// type such as string), `fungsi` or `kosong`.
+func (p *Parser) parseTypeAnnotation() *TypeAnnotation {
+	ann := &TypeAnnotation{Token: p.peekToken}
+	for {
+		switch p.peekToken.Type {
+		case lexer.IDENT, lexer.FUNGSI, lexer.KOSONG:
+			p.nextToken()
+			ann.Names = append(ann.Names, p.curToken.Literal)
+		default:
+			p.addDetailedError(p.peekToken, "expected a type name, got %s", p.peekToken.Literal)
+			return nil
+		}
+		if !p.peekTokenIs(lexer.OR) {
+			break
+		}
+		p.nextToken()
+	}
+	p.finishNode(ann, ann.Token)
+	return ann
 }
 
 func (p *Parser) parseCallExpression(function Expression) Expression {
@@ -762,6 +1744,21 @@ func (p *Parser) parseCallExpression(function Expression) Expression {
 	return exp
 }
 
+// parseCallArgument parses one argument, either an expression or a named
+// argument such as `batas: 5`.
+func (p *Parser) parseCallArgument() Expression {
+	if !p.curTokenIs(lexer.IDENT) || !p.peekTokenIs(lexer.COLON) {
+		return p.parseExpression(LOWEST)
+	}
+
+	arg := &NamedArgument{Token: p.curToken, Name: p.curIdentifier()}
+	p.nextToken()
+	p.nextToken()
+	arg.Value = p.parseExpression(LOWEST)
+	p.finishNode(arg, arg.Token)
+	return arg
+}
+
 func (p *Parser) parseCallArguments() []Expression {
 	args := []Expression{}
 
@@ -770,13 +1767,21 @@ func (p *Parser) parseCallArguments() []Expression {
 		return args
 	}
 
-	p.nextToken()
-	args = append(args, p.parseExpression(LOWEST))
-
-	for p.peekTokenIs(lexer.COMMA) {
+	named := false
+	for {
 		p.nextToken()
+		arg := p.parseCallArgument()
+		if _, ok := arg.(*NamedArgument); ok {
+			named = true
+		} else if named && arg != nil {
+			p.addDetailedError(p.curToken, "positional argument %s must come before named arguments", arg.String())
+		}
+		args = append(args, arg)
+
+		if !p.peekTokenIs(lexer.COMMA) {
+			break
+		}
 		p.nextToken()
-		args = append(args, p.parseExpression(LOWEST))
 	}
 
 	if !p.expectPeek(lexer.RPAREN) {
@@ -790,14 +1795,22 @@ func (p *Parser) parseBlockStatement() *BlockStatement {
 	block := &BlockStatement{Token: p.curToken}
 	block.Statements = []Statement{}
 
-	for !p.curTokenIs(lexer.AKHIR) && !p.curTokenIs(lexer.LAINNYA) && !p.curTokenIs(lexer.ATAU_JIKA) && !p.curTokenIs(lexer.EOF) {
+	for !isBlockEnd(p.curToken.Type) && !p.curTokenIs(lexer.EOF) {
+		depth := len(p.blocks)
+		start := p.curToken
 		stmt := p.parseStatement()
 		if stmt != nil {
+			p.finishNode(stmt, start)
 			block.Statements = append(block.Statements, stmt)
 		}
+		if p.panicking {
+			p.synchronize(depth)
+			continue
+		}
 		p.nextToken()
 	}
 
+	block.Range = blockSpan(block.Statements, block.Token)
 	return block
 }
 
@@ -819,7 +1832,7 @@ func (p *Parser) expectPeek(t lexer.TokenType) bool {
 }
 
 func (p *Parser) noPrefixParseFnError(t lexer.TokenType) {
-	p.addDetailedError(p.curToken, "no prefix parse function for %s found", t)
+	p.addExpectedError(p.curToken, "expression", "no prefix parse function for %s found", t)
 }
 
 func (p *Parser) parseDotExpression(left Expression) Expression {
@@ -830,6 +1843,7 @@ func (p *Parser) parseDotExpression(left Expression) Expression {
 	}
 
 	index := &StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
+	p.finishNode(index, p.curToken)
 
 	return &IndexExpression{Token: token, Left: left, Index: index}
 }
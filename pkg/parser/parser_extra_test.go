@@ -129,3 +129,45 @@ func TestDetailedErrorFormatting(t *testing.T) {
 		t.Errorf("Error format wrong: %s", err)
 	}
 }
+
+func TestMalformedEscapeError(t *testing.T) {
+	input := "x = 1\ny = \"a\\qb #{x}\""
+	p := New(lexer.New(input))
+	p.ParseProgram()
+
+	errs := p.Errors()
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %d", len(errs))
+	}
+	if errs[0].Message != `unknown escape sequence \q` {
+		t.Errorf("message wrong: %q", errs[0].Message)
+	}
+	if errs[0].Line != 2 || errs[0].Column != 7 {
+		t.Errorf("position wrong. expected=2:7, got=%d:%d", errs[0].Line, errs[0].Column)
+	}
+	if errs[0].Span.Length != 2 {
+		t.Errorf("span should cover the escape, got length %d", errs[0].Span.Length)
+	}
+}
+
+func TestTripleQuotedString(t *testing.T) {
+	input := "x = \"\"\"\n  halo #{nama}\n  \"\"\""
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*AssignmentStatement)
+	is, ok := stmt.Value.(*InterpolatedString)
+	if !ok {
+		t.Fatalf("value not InterpolatedString. got=%T", stmt.Value)
+	}
+	if len(is.Parts) != 2 {
+		t.Fatalf("InterpolatedString parts wrong. want 2, got %d", len(is.Parts))
+	}
+	if sl, ok := is.Parts[0].(*StringLiteral); !ok || sl.Value != "halo " {
+		t.Errorf("Part 0 wrong. got %T %v", is.Parts[0], is.Parts[0])
+	}
+	if span := is.Span(); span.StartLine != 1 || span.EndLine != 3 {
+		t.Errorf("span wrong: %+v", span)
+	}
+}
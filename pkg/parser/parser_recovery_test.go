@@ -0,0 +1,133 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/VzoelFox/morphlang/pkg/lexer"
+)
+
+func TestParserRecovery(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		errors []ParserError // Only Line, Column, Expected and Found are compared
+	}{
+		{
+			"bad parameter list skips the function body",
+			`fungsi f(a b)
+  x = a + b
+  jika x > 1
+    cetak(x)
+  akhir
+akhir
+y = 1`,
+			[]ParserError{{Line: 1, Column: 12, Expected: ")", Found: "IDENT"}},
+		},
+		{
+			"independent mistakes are all reported",
+			`x = (1 + 2
+y = ]
+fungsi g()
+  z = )
+  w = 3
+akhir
+v = [1, 2
+u = 5`,
+			[]ParserError{
+				{Line: 2, Column: 1, Expected: ")", Found: "IDENT"},
+				{Line: 2, Column: 5, Expected: "expression", Found: "]"},
+				{Line: 4, Column: 7, Expected: "expression", Found: ")"},
+				{Line: 8, Column: 1, Expected: "]", Found: "IDENT"},
+			},
+		},
+		{
+			"missing akhir names the opener",
+			`jika x > 1
+  cetak("a")
+`,
+			[]ParserError{{Line: 3, Column: 1, Expected: "AKHIR", Found: "EOF"}},
+		},
+		{
+			"stray akhir",
+			`x = 1
+akhir
+y = 2`,
+			[]ParserError{{Line: 2, Column: 1, Expected: "statement", Found: "AKHIR"}},
+		},
+		{
+			"orphaned lainnya branch is one mistake",
+			`x = 1
+lainnya
+  cetak(2)
+akhir
+y = 2`,
+			[]ParserError{{Line: 2, Column: 1, Expected: "statement", Found: "LAINNYA"}},
+		},
+		{
+			"one error per statement",
+			`x = a+b+c
+y = 1`,
+			[]ParserError{{Line: 1, Column: 6, Found: "+"}},
+		},
+		{
+			"missing spaces do not skip the rest of the block",
+			`fungsi f(a)
+  jika a>1
+    kembalikan a
+  akhir
+  x = a+1
+akhir`,
+			[]ParserError{{Line: 2, Column: 9, Found: ">"}, {Line: 5, Column: 8, Found: "+"}},
+		},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) != len(tt.errors) {
+			t.Errorf("%s: expected %d errors, got %d: %v", tt.name, len(tt.errors), len(errors), errors)
+			continue
+		}
+		for i, want := range tt.errors {
+			got := errors[i]
+			if got.Line != want.Line || got.Column != want.Column || got.Expected != want.Expected || got.Found != want.Found {
+				t.Errorf("%s: error %d wrong. want=%d:%d expected=%q found=%q, got=%d:%d expected=%q found=%q (%s)",
+					tt.name, i, want.Line, want.Column, want.Expected, want.Found,
+					got.Line, got.Column, got.Expected, got.Found, got.Message)
+			}
+		}
+	}
+}
+
+func TestParserRecoveryKeepsLaterStatements(t *testing.T) {
+	input := `x = )
+y = 2
+fungsi f()
+  1
+akhir`
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != 1 {
+		t.Fatalf("expected 1 error, got %v", p.Errors())
+	}
+
+	found := map[string]bool{}
+	for _, stmt := range program.Statements {
+		switch s := stmt.(type) {
+		case *AssignmentStatement:
+			found[s.Name.String()] = true
+		case *ExpressionStatement:
+			if fn, ok := s.Expression.(*FunctionLiteral); ok {
+				found[fn.Name] = true
+			}
+		}
+	}
+	if !found["y"] || !found["f"] {
+		t.Errorf("statements after the error were not parsed: %s", program.String())
+	}
+}
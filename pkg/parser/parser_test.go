@@ -2,6 +2,7 @@ package parser
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/VzoelFox/morphlang/pkg/lexer"
@@ -37,6 +38,69 @@ func TestIntegerLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestNumericLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"0xFF", int64(255)},
+		{"0b1010", int64(10)},
+		{"0o755", int64(493)},
+		{"1_000_000", int64(1000000)},
+		{"0x_FF_FF", int64(65535)},
+		{"007", int64(7)},
+		{"9223372036854775807", int64(9223372036854775807)},
+		{"6.02e23", 6.02e23},
+		{"1E-3", 0.001},
+		{"2_500.5", 2500.5},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ExpressionStatement)
+		switch expected := tt.expected.(type) {
+		case int64:
+			lit, ok := stmt.Expression.(*IntegerLiteral)
+			if !ok || lit.Value != expected {
+				t.Errorf("%s: expected integer %d, got %T %v", tt.input, expected, stmt.Expression, stmt.Expression)
+			}
+		case float64:
+			lit, ok := stmt.Expression.(*FloatLiteral)
+			if !ok || lit.Value != expected {
+				t.Errorf("%s: expected float %g, got %T %v", tt.input, expected, stmt.Expression, stmt.Expression)
+			}
+		}
+	}
+}
+
+func TestNumericLiteralErrors(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedError  string
+		expectedColumn int
+	}{
+		{"x = 9223372036854775808", "integer literal 9223372036854775808 is out of range for int64", 5},
+		{"x = 0x1_0000_0000_0000_0000", "integer literal 0x1_0000_0000_0000_0000 is out of range for int64", 5},
+		{"x = 1e999", "float literal 1e999 is out of range for float64", 5},
+		{"x = 0b102", `could not parse "0b102" as integer`, 5},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+		errs := p.Errors()
+		if len(errs) == 0 {
+			t.Fatalf("%s: expected an error", tt.input)
+		}
+		if errs[0].Message != tt.expectedError || errs[0].Line != 1 || errs[0].Column != tt.expectedColumn {
+			t.Errorf("%s: expected %q at 1:%d, got %q at %d:%d", tt.input, tt.expectedError, tt.expectedColumn, errs[0].Message, errs[0].Line, errs[0].Column)
+		}
+	}
+}
+
 func TestParsingPrefixExpressions(t *testing.T) {
 	prefixTests := []struct {
 		input    string
@@ -217,6 +281,30 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"!(true == true)",
 			"(!(true == true))",
 		},
+		{
+			"a * b % c // d",
+			"(((a * b) % c) // d)",
+		},
+		{
+			"a ** b ** c",
+			"(a ** (b ** c))",
+		},
+		{
+			"-a ** b",
+			"(-(a ** b))",
+		},
+		{
+			"a * b ** c",
+			"(a * (b ** c))",
+		},
+		{
+			"coba f(x) + 1",
+			"((coba f(x)) + 1)",
+		},
+		{
+			"coba a[0].b",
+			"(coba ((a[0])[b]))",
+		},
 	}
 
 	for _, tt := range tests {
@@ -306,6 +394,227 @@ func TestAssignmentStatements(t *testing.T) {
 	}
 }
 
+func TestCompoundAssignment(t *testing.T) {
+	tests := []struct {
+		input    string
+		operator string
+		expected string
+	}{
+		{"x += 1", "+", "x += 1;"},
+		{"a[0] -= 2 * 3", "-", "(a[0]) -= (2 * 3);"},
+		{"t.x *= 2", "*", "(t[x]) *= 2;"},
+		{"n /= 4", "/", "n /= 4;"},
+		{"n = 4", "", "n = 4;"},
+	}
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*AssignmentStatement)
+		if !ok {
+			t.Fatalf("%q: not an AssignmentStatement, got %T", tt.input, program.Statements[0])
+		}
+		if stmt.Operator() != tt.operator {
+			t.Errorf("%q: operator wrong. want %q, got %q", tt.input, tt.operator, stmt.Operator())
+		}
+		if stmt.String() != tt.expected {
+			t.Errorf("%q: String() wrong. want %q, got %q", tt.input, tt.expected, stmt.String())
+		}
+	}
+
+	p := New(lexer.New("x+=1"))
+	p.ParseProgram()
+	if errs := p.Errors(); len(errs) == 0 || errs[0].Code != ErrMissingWhitespace {
+		t.Errorf("expected a whitespace error for x+=1, got %v", errs)
+	}
+}
+
+func TestConstStatement(t *testing.T) {
+	p := New(lexer.New("tetap BATAS = 10 * 2;"))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ConstStatement)
+	if !ok {
+		t.Fatalf("not a ConstStatement, got %T", program.Statements[0])
+	}
+	if stmt.Name.Value != "BATAS" {
+		t.Errorf("wrong name: %q", stmt.Name.Value)
+	}
+	if stmt.String() != "tetap BATAS = (10 * 2);" {
+		t.Errorf("String() wrong, got %q", stmt.String())
+	}
+
+	for _, input := range []string{"tetap = 1", "tetap a", "tetap a += 1"} {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("%q: expected a parse error", input)
+		}
+	}
+}
+
+func TestTypeAnnotations(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"fungsi bagi(a: integer, b: integer) -> integer | galat\n kembalikan a / b\nakhir",
+			"fungsi bagi(a: integer, b: integer) -> integer | galat kembalikan (a / b); akhir"},
+		{"fungsi f(x: string = \"a\", ...sisa)\nakhir", "fungsi f(x: string = a, ...sisa)  akhir"},
+		{"nama: string = \"budi\"", "nama: string = budi;"},
+		{"struktur Titik\n  x: integer\n  y\nakhir", "struktur Titik\n  x: integer\n  y\nakhir"},
+	}
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.Statements[0].String() != tt.expected {
+			t.Errorf("String() wrong.\nexpected=%q\ngot=%q", tt.expected, program.Statements[0].String())
+		}
+	}
+
+	p := New(lexer.New("fungsi f(a: integer | galat) -> kosong\nakhir"))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	fn := program.Statements[0].(*ExpressionStatement).Expression.(*FunctionLiteral)
+	if got := fn.ParamType(0).Types(); len(got) != 2 || got[0] != "integer" || got[1] != "error" {
+		t.Errorf("parameter types wrong: %v", got)
+	}
+	if got := fn.ReturnType.Types(); len(got) != 1 || got[0] != "kosong" {
+		t.Errorf("return types wrong: %v", got)
+	}
+
+	for _, input := range []string{"fungsi f(a:)\nakhir", "fungsi f(...a: array)\nakhir", "fungsi f() ->\nakhir"} {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("%q: expected a parse error", input)
+		}
+	}
+}
+
+func TestEnumStatement(t *testing.T) {
+	input := `pilihan Status
+  Menunggu
+  Berjalan(pid)
+  Selesai(kode, pesan); Batal()
+akhir`
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*EnumStatement)
+	if !ok {
+		t.Fatalf("not an EnumStatement, got %T", program.Statements[0])
+	}
+	want := []string{"Menunggu", "Berjalan(pid)", "Selesai(kode, pesan)", "Batal()"}
+	if len(stmt.Variants) != len(want) {
+		t.Fatalf("expected %d variants, got %d", len(want), len(stmt.Variants))
+	}
+	for i, v := range stmt.Variants {
+		if v.String() != want[i] {
+			t.Errorf("variant %d: want %q, got %q", i, want[i], v.String())
+		}
+	}
+	if stmt.Variants[0].Fields != nil || stmt.Variants[3].Fields == nil {
+		t.Errorf("a variant without parentheses has nil Fields, one with () has none")
+	}
+
+	for _, input := range []string{
+		"pilihan Status akhir",
+		"pilihan Status A A akhir",
+		"pilihan Status A(x, x) akhir",
+		"pilihan Status A(1) akhir",
+		"pilihan Status A",
+	} {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("%q: expected a parse error", input)
+		}
+	}
+}
+
+func TestDestructuringStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[a, b] = pasangan", "[a, b] = pasangan;"},
+		{"[kepala, ...sisa] = [1, 2, 3]", "[kepala, ...sisa] = [1, 2, 3];"},
+		{"{nama, umur: u} = orang", "{nama: nama, umur: u} = orang;"},
+		{"Orang{nama: n} = o", "Orang{nama: n} = o;"},
+	}
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*DestructuringStatement)
+		if !ok {
+			t.Fatalf("%q: not a DestructuringStatement, got %T", tt.input, program.Statements[0])
+		}
+		if stmt.String() != tt.expected {
+			t.Errorf("%q: String() wrong. want %q, got %q", tt.input, tt.expected, stmt.String())
+		}
+	}
+
+	// Array and hash literals that are not assigned stay expressions
+	p := New(lexer.New("[a, b][0]"))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	if _, ok := program.Statements[0].(*ExpressionStatement); !ok {
+		t.Errorf("expected an ExpressionStatement, got %T", program.Statements[0])
+	}
+
+	p = New(lexer.New("[a, 1] = pasangan"))
+	p.ParseProgram()
+	if errs := p.Errors(); len(errs) == 0 || !strings.Contains(errs[0].Message, "destructuring can only bind names") {
+		t.Errorf("expected a destructuring error, got %v", errs)
+	}
+}
+
+func TestDestructuringAfterExpression(t *testing.T) {
+	// A `[` that opens a new line and is assigned to starts a destructuring
+	// statement instead of indexing the expression on the line before.
+	p := New(lexer.New("x = 1\n[a, b] = [1, 2]"))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	if len(program.Statements) != 2 {
+		t.Fatalf("expected 2 statements, got %d", len(program.Statements))
+	}
+	if _, ok := program.Statements[1].(*DestructuringStatement); !ok {
+		t.Errorf("expected a DestructuringStatement, got %T", program.Statements[1])
+	}
+
+	input := `fungsi f(x)
+	p = [x, x * 2]
+	[a, b] = p
+	kembalikan a + b
+akhir`
+	p = New(lexer.New(input))
+	program = p.ParseProgram()
+	checkParserErrors(t, p)
+	fn := program.Statements[0].(*ExpressionStatement).Expression.(*FunctionLiteral)
+	if len(fn.Body.Statements) != 3 {
+		t.Fatalf("expected 3 statements in the body, got %d", len(fn.Body.Statements))
+	}
+	if _, ok := fn.Body.Statements[1].(*DestructuringStatement); !ok {
+		t.Errorf("expected a DestructuringStatement, got %T", fn.Body.Statements[1])
+	}
+
+	// An index on the next line that is not assigned to still indexes
+	p = New(lexer.New("x = daftar\n[0]"))
+	program = p.ParseProgram()
+	checkParserErrors(t, p)
+	if len(program.Statements) != 1 {
+		t.Errorf("expected 1 statement, got %d", len(program.Statements))
+	}
+}
+
 func TestIfExpression(t *testing.T) {
 	input := `jika x < y x akhir`
 
@@ -495,6 +804,104 @@ func TestFunctionParameterParsing(t *testing.T) {
 	}
 }
 
+func TestOptionalParameterParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		minArgs  int
+	}{
+		{"fungsi(a, b = 10) akhir", "fungsi(a, b = 10) ", 1},
+		{"fungsi(a = 1, b = a * 2) akhir", "fungsi(a = 1, b = (a * 2)) ", 0},
+		{"fungsi(...sisa) akhir", "fungsi(...sisa) ", 0},
+		{"fungsi(a, b = [], ...sisa) akhir", "fungsi(a, b = [], ...sisa) ", 1},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		function := program.Statements[0].(*ExpressionStatement).Expression.(*FunctionLiteral)
+		if got := function.String(); !strings.HasPrefix(got, tt.expected) {
+			t.Errorf("String() wrong. want prefix %q, got %q", tt.expected, got)
+		}
+		if got := function.MinArgs(); got != tt.minArgs {
+			t.Errorf("%q: MinArgs() want %d, got %d", tt.input, tt.minArgs, got)
+		}
+	}
+
+	errors := []string{
+		"fungsi(a = 1, b) akhir",
+		"fungsi(...sisa, a) akhir",
+		"fungsi(...sisa = []) akhir",
+	}
+	for _, input := range errors {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("%q: expected a parser error", input)
+		}
+	}
+}
+
+func TestNamedArgumentParsing(t *testing.T) {
+	p := New(lexer.New("Titik(1, y: 2 * 3, z: f(a: 1))"))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	call := program.Statements[0].(*ExpressionStatement).Expression.(*CallExpression)
+	if got, want := call.String(), "Titik(1, y: (2 * 3), z: f(a: 1))"; got != want {
+		t.Errorf("String() wrong. want %q, got %q", want, got)
+	}
+	named := call.NamedArguments()
+	if len(named) != 2 || named[0].Name.Value != "y" || named[1].Name.Value != "z" {
+		t.Fatalf("wrong named arguments: %v", named)
+	}
+
+	p = New(lexer.New("f(a: 1, 2)"))
+	p.ParseProgram()
+	if len(p.Errors()) == 0 {
+		t.Errorf("expected an error for a positional argument after a named one")
+	}
+}
+
+func TestStructMethodParsing(t *testing.T) {
+	input := `struktur Titik
+  x
+  fungsi geser(dx, dy = 0)
+    kembalikan Titik(ini.x + dx)
+  akhir
+akhir`
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*StructStatement)
+	if len(stmt.Fields) != 1 || len(stmt.Methods) != 1 {
+		t.Fatalf("wrong members: fields=%v methods=%v", stmt.Fields, stmt.Methods)
+	}
+	m := stmt.Method("geser").WithReceiver()
+	if got, want := len(m.Parameters), 3; got != want || m.Parameters[0].Value != Receiver {
+		t.Fatalf("WithReceiver parameters wrong: %v", m.Parameters)
+	}
+	if m.MinArgs() != 2 || m.Default(2) == nil {
+		t.Errorf("WithReceiver should keep defaults in place, min args %d", m.MinArgs())
+	}
+
+	for _, bad := range []string{
+		"struktur T\n  fungsi() 1 akhir\nakhir",
+		"struktur T\n  x\n  fungsi x() 1 akhir\nakhir",
+		"struktur T\n  fungsi f() 1 akhir\n  f\nakhir",
+		"struktur T\n  fungsi f() 1 akhir\n  fungsi f() 2 akhir\nakhir",
+	} {
+		p := New(lexer.New(bad))
+		p.ParseProgram()
+		if len(p.Errors()) != 1 {
+			t.Errorf("expected exactly one error for %q, got %v", bad, p.Errors())
+		}
+	}
+}
+
 func TestCallExpressionParsing(t *testing.T) {
 	input := "add(1, 2 * 3, 4 + 5);"
 
@@ -688,6 +1095,178 @@ func TestWhileExpression(t *testing.T) {
 	}
 }
 
+func TestForInExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		key      string
+		value    string
+		iterable string
+	}{
+		{`untuk x dalam daftar x akhir`, "", "x", "daftar"},
+		{`untuk k, v dalam peta k akhir`, "k", "v", "peta"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ExpressionStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ExpressionStatement. got=%T",
+				program.Statements[0])
+		}
+
+		exp, ok := stmt.Expression.(*ForInExpression)
+		if !ok {
+			t.Fatalf("stmt.Expression is not ForInExpression. got=%T",
+				stmt.Expression)
+		}
+
+		if tt.key == "" && exp.Key != nil {
+			t.Errorf("exp.Key should be nil. got=%s", exp.Key)
+		}
+		if tt.key != "" && !testIdentifier(t, exp.Key, tt.key) {
+			return
+		}
+		if !testIdentifier(t, exp.Value, tt.value) {
+			return
+		}
+		if !testIdentifier(t, exp.Iterable, tt.iterable) {
+			return
+		}
+		if len(exp.Body.Statements) != 1 {
+			t.Errorf("body is not 1 statements. got=%d",
+				len(exp.Body.Statements))
+		}
+	}
+}
+
+func TestMatchExpression(t *testing.T) {
+	input := `
+cocokkan nilai
+  kasus 0
+    "nol"
+  kasus [a, b, ...sisa]
+    a + b
+  kasus {nama: n, umur} jika umur > 17
+    n
+  kasus Titik{x: 0, y}
+    y
+  kasus galat(pesan, kode)
+    pesan
+  kasus -1 "minus"
+  lainnya
+    "lainnya"
+akhir`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ExpressionStatement. got=%T", program.Statements[0])
+	}
+
+	exp, ok := stmt.Expression.(*MatchExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not MatchExpression. got=%T", stmt.Expression)
+	}
+
+	if !testIdentifier(t, exp.Subject, "nilai") {
+		return
+	}
+
+	expectedPatterns := []string{
+		"0",
+		"[a, b, ...sisa]",
+		"{nama: n, umur: umur}",
+		"Titik{x: 0, y: y}",
+		"galat(pesan, kode)",
+		"(-1)",
+	}
+	if len(exp.Arms) != len(expectedPatterns) {
+		t.Fatalf("wrong number of arms. want=%d, got=%d", len(expectedPatterns), len(exp.Arms))
+	}
+	for i, want := range expectedPatterns {
+		if got := exp.Arms[i].Pattern.String(); got != want {
+			t.Errorf("arm %d pattern wrong. want=%q, got=%q", i, want, got)
+		}
+		if len(exp.Arms[i].Body.Statements) != 1 {
+			t.Errorf("arm %d body is not 1 statement. got=%d", i, len(exp.Arms[i].Body.Statements))
+		}
+	}
+
+	if exp.Arms[2].Guard == nil || exp.Arms[2].Guard.String() != "(umur > 17)" {
+		t.Errorf("arm 2 guard wrong. got=%v", exp.Arms[2].Guard)
+	}
+	if _, ok := exp.Arms[3].Pattern.(*HashPattern); !ok {
+		t.Errorf("arm 3 is not HashPattern. got=%T", exp.Arms[3].Pattern)
+	}
+	if exp.Alternative == nil || len(exp.Alternative.Statements) != 1 {
+		t.Errorf("lainnya arm not parsed")
+	}
+}
+
+func TestMatchGuardStopsAtLineEnd(t *testing.T) {
+	input := `
+cocokkan q
+  kasus q jika q == "a"
+    "guard ok"
+  kasus q jika q == "a" "b"
+    "sama baris"
+akhir`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	exp, ok := program.Statements[0].(*ExpressionStatement).Expression.(*MatchExpression)
+	if !ok {
+		t.Fatalf("expression is not MatchExpression. got=%T", program.Statements[0].(*ExpressionStatement).Expression)
+	}
+	if len(exp.Arms) != 2 {
+		t.Fatalf("wrong number of arms. want=2, got=%d", len(exp.Arms))
+	}
+
+	guard, ok := exp.Arms[0].Guard.(*InfixExpression)
+	if !ok {
+		t.Fatalf("arm 0 guard is not InfixExpression. got=%T", exp.Arms[0].Guard)
+	}
+	testStringLiteral(t, guard.Right, "a")
+	testArmBody(t, exp.Arms[0], "guard ok")
+
+	// Strings on the guard line still merge
+	guard, ok = exp.Arms[1].Guard.(*InfixExpression)
+	if !ok {
+		t.Fatalf("arm 1 guard is not InfixExpression. got=%T", exp.Arms[1].Guard)
+	}
+	if _, ok := guard.Right.(*InterpolatedString); !ok {
+		t.Errorf("arm 1 guard does not merge \"a\" \"b\". got=%T", guard.Right)
+	}
+	testArmBody(t, exp.Arms[1], "sama baris")
+}
+
+func testArmBody(t *testing.T, arm *MatchArm, want string) {
+	t.Helper()
+	if len(arm.Body.Statements) != 1 {
+		t.Fatalf("arm body is not 1 statement. got=%d", len(arm.Body.Statements))
+	}
+	stmt, ok := arm.Body.Statements[0].(*ExpressionStatement)
+	if !ok {
+		t.Fatalf("arm body is not ExpressionStatement. got=%T", arm.Body.Statements[0])
+	}
+	testStringLiteral(t, stmt.Expression, want)
+}
+
 func TestIfExpressionMissingAkhir(t *testing.T) {
 	input := `jika x < y x`
 	l := lexer.New(input)
@@ -747,3 +1326,74 @@ func checkParserErrors(t *testing.T, p *Parser) {
 	}
 	t.FailNow()
 }
+
+func TestSliceAndRangeParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a[1:3]", "(a[1:3])"},
+		{"a[:-1]", "(a[:(-1)])"},
+		{"teks[2:]", "(teks[2:])"},
+		{"a[:]", "(a[:])"},
+		{"a[i + 1:n][0]", "((a[(i + 1):n])[0])"},
+		{"1..10", "(1..10)"},
+		{"0..<n", "(0..<n)"},
+		{"a + 1..b * 2", "((a + 1)..(b * 2))"},
+		{"x == 1..3", "(x == (1..3))"},
+		{"(1..5)[0]", "((1..5)[0])"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if got := program.String(); got != tt.expected {
+			t.Errorf("%q: expected=%q, got=%q", tt.input, tt.expected, got)
+		}
+	}
+
+	p := New(lexer.New("0..<n"))
+	stmt := p.ParseProgram().Statements[0].(*ExpressionStatement)
+	rng, ok := stmt.Expression.(*RangeExpression)
+	if !ok {
+		t.Fatalf("not a RangeExpression, got %T", stmt.Expression)
+	}
+	if !rng.Exclusive {
+		t.Errorf("0..<n should be exclusive")
+	}
+}
+
+func TestOptionalAndCoalesceParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a?.b?.c", "((a?.b)?.c)"},
+		{"a?.b.c", "((a?.b)[c])"},
+		{"a?.m(1)", "(a?.m)(1)"},
+		{"x ?? 1", "(x ?? 1)"},
+		{"x ?! 1", "(x ?! 1)"},
+		{"a ?? b ?? c", "((a ?? b) ?? c)"},
+		{"x ?? a atau b", "(x ?? (a atau b))"},
+		{"x ?? 1 + 2", "(x ?? (1 + 2))"},
+		{"a?.b ?? c", "((a?.b) ?? c)"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if got := program.String(); got != tt.expected {
+			t.Errorf("%q: expected=%q, got=%q", tt.input, tt.expected, got)
+		}
+	}
+
+	p := New(lexer.New("x??1"))
+	p.ParseProgram()
+	if len(p.Errors()) == 0 {
+		t.Errorf("expected a whitespace error for x??1")
+	}
+}
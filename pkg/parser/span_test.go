@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/VzoelFox/morphlang/pkg/lexer"
+)
+
+func TestNodeSpans(t *testing.T) {
+	input := `x = (1 + 2) * 3
+fungsi f(a)
+  kembalikan "hi #{a}"
+akhir
+cocokkan x kasus [h, ...t] h akhir
+tetap BATAS = 10`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	assign := program.Statements[0].(*AssignmentStatement)
+	fnStmt := program.Statements[1].(*ExpressionStatement)
+	fn := fnStmt.Expression.(*FunctionLiteral)
+	ret := fn.Body.Statements[0].(*ReturnStatement)
+	match := program.Statements[2].(*ExpressionStatement).Expression.(*MatchExpression)
+	arm := match.Arms[0]
+	konst := program.Statements[3].(*ConstStatement)
+
+	tests := []struct {
+		name string
+		node Node
+		want Span
+		text string // Source covered by the span, checked when set
+	}{
+		{"assignment", assign, Span{1, 1, 1, 16, 0, 15}, "x = (1 + 2) * 3"},
+		{"assignment target", assign.Name, Span{1, 1, 1, 2, 0, 1}, "x"},
+		{"product", assign.Value, Span{1, 5, 1, 16, 4, 11}, "(1 + 2) * 3"},
+		{"grouped sum", assign.Value.(*InfixExpression).Left, Span{1, 6, 1, 11, 5, 5}, "1 + 2"},
+		{"function", fn, Span{2, 1, 4, 6, 16, 40}, "fungsi f(a)\n  kembalikan \"hi #{a}\"\nakhir"},
+		{"function statement", fnStmt, Span{2, 1, 4, 6, 16, 40}, ""},
+		{"parameter", fn.Parameters[0], Span{2, 10, 2, 11, 25, 1}, "a"},
+		{"body", fn.Body, Span{3, 3, 3, 23, 30, 20}, "kembalikan \"hi #{a}\""},
+		{"return value", ret.ReturnValue, Span{3, 14, 3, 23, 41, 9}, "\"hi #{a}\""},
+		{"match arm", arm, Span{5, 12, 5, 29, 68, 17}, "kasus [h, ...t] h"},
+		{"array pattern", arm.Pattern, Span{5, 18, 5, 27, 74, 9}, "[h, ...t]"},
+		{"constant", konst, Span{6, 1, 6, 17, 92, 16}, "tetap BATAS = 10"},
+		{"constant name", konst.Name, Span{6, 7, 6, 12, 98, 5}, "BATAS"},
+		{"program", program, Span{1, 1, 6, 17, 0, 108}, ""},
+	}
+
+	for _, tt := range tests {
+		got := tt.node.Span()
+		if got != tt.want {
+			t.Errorf("%s: span wrong. want=%+v, got=%+v", tt.name, tt.want, got)
+			continue
+		}
+		if tt.text != "" && input[got.Offset:got.Offset+got.Length] != tt.text {
+			t.Errorf("%s: span covers %q, want %q", tt.name, input[got.Offset:got.Offset+got.Length], tt.text)
+		}
+	}
+}
+
+func TestParserErrorSpan(t *testing.T) {
+	l := lexer.New("x = )")
+	p := New(l)
+	p.ParseProgram()
+
+	errors := p.Errors()
+	if len(errors) != 1 {
+		t.Fatalf("expected 1 error, got %v", errors)
+	}
+	want := Span{1, 5, 1, 6, 4, 1}
+	if errors[0].Span != want {
+		t.Errorf("error span wrong. want=%+v, got=%+v", want, errors[0].Span)
+	}
+}
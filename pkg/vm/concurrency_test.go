@@ -2,6 +2,8 @@ package vm
 
 import (
 	"testing"
+
+	"github.com/VzoelFox/morphlang/pkg/object"
 )
 
 func TestConcurrencyScaffolding(t *testing.T) {
@@ -91,3 +93,12 @@ func TestConcurrencyJoin(t *testing.T) {
 	}
 	runVmTests(t, tests)
 }
+
+func TestSpawnBindsParameters(t *testing.T) {
+	tests := []vmTestCase{
+		{`tunggu(luncurkan(fungsi(...xs) panjang(xs) akhir))`, 0},
+		{`tunggu(luncurkan(fungsi(a = 5, ...xs) [a, xs] akhir))`, []interface{}{5, []int64{}}},
+		{`tunggu(luncurkan(fungsi(a) a akhir))`, object.NewError("arg mismatch: want 1, got 0", "", 0, 0)},
+	}
+	runVmTests(t, tests)
+}
@@ -63,6 +63,24 @@ type VM struct {
 	Drawer  *memory.Drawer
 
 	openUpvalues map[int]memory.Ptr
+
+	// task is set for spawned VMs, whose first frame is a function rather
+	// than the main program
+	task bool
+}
+
+// UncaughtError is returned by Run when a galat propagates out of the main
+// program, through `coba`, a failed field write or a destructuring mismatch.
+type UncaughtError struct {
+	Message string
+	Code    string
+}
+
+func (e *UncaughtError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("uncaught galat [%s]: %s", e.Code, e.Message)
+	}
+	return "uncaught galat: " + e.Message
 }
 
 func New(bytecode *compiler.Bytecode) *VM {
@@ -134,6 +152,19 @@ func New(bytecode *compiler.Bytecode) *VM {
 	return vm
 }
 
+// NewWithGlobals returns a VM that uses globals instead of a fresh global
+// store, so values defined by an earlier run stay visible (used by the REPL).
+func NewWithGlobals(bytecode *compiler.Bytecode, globals []memory.Ptr) *VM {
+	vm := New(bytecode)
+	vm.globals = globals
+	return vm
+}
+
+// Close removes the VM from the garbage collector roots once it has finished.
+func (vm *VM) Close() {
+	activeVMs.Delete(vm)
+}
+
 func (vm *VM) currentFrame() *Frame {
 	return vm.frames[vm.framesIndex-1]
 }
@@ -152,6 +183,19 @@ func (vm *VM) popFrame() *Frame {
 	return vm.frames[vm.framesIndex]
 }
 
+// returnValue pops the current frame and leaves val where the caller expects
+// the call result.
+func (vm *VM) returnValue(val memory.Ptr) error {
+	frame := vm.popFrame()
+	vm.closeUpvalues(frame.basePointer)
+	if vm.framesIndex == 0 {
+		vm.sp = 0
+	} else {
+		vm.sp = frame.basePointer - 1
+	}
+	return vm.push(val)
+}
+
 func (vm *VM) StackTop() object.Object {
 	if vm.sp == 0 {
 		return nil
@@ -202,7 +246,8 @@ func (vm *VM) Run() (err error) {
 			if err := ensureOnHeap(obj); err != nil { return err }
 			if err := vm.push(getObjectAddress(obj)); err != nil { return err }
 
-		case compiler.OpAdd, compiler.OpSub, compiler.OpMul, compiler.OpDiv:
+		case compiler.OpAdd, compiler.OpSub, compiler.OpMul, compiler.OpDiv,
+			compiler.OpMod, compiler.OpFloorDiv, compiler.OpPow:
 			if err := vm.executeBinaryOperation(op); err != nil { return err }
 
 		case compiler.OpPop:
@@ -212,6 +257,11 @@ func (vm *VM) Run() (err error) {
 			top := vm.stack[vm.sp-1]
 			if err := vm.push(top); err != nil { return err }
 
+		case compiler.OpDup2:
+			a, b := vm.stack[vm.sp-2], vm.stack[vm.sp-1]
+			if err := vm.push(a); err != nil { return err }
+			if err := vm.push(b); err != nil { return err }
+
 		case compiler.OpStoreGlobal:
 			globalIndex := compiler.ReadUint16(ins[ip+1:])
 			vm.currentFrame().ip += 2
@@ -250,18 +300,40 @@ func (vm *VM) Run() (err error) {
 			vm.currentFrame().ip += 1
 			if err := vm.executeCall(numArgs); err != nil { return err }
 
+		case compiler.OpCallNamed:
+			numPositional := int(ins[ip+1])
+			numNamed := int(ins[ip+2])
+			vm.currentFrame().ip += 2
+			if err := vm.executeNamedCall(numPositional, numNamed); err != nil { return err }
+
+		case compiler.OpCallMethod:
+			nameIndex := compiler.ReadUint16(ins[ip+1:])
+			numPositional := int(ins[ip+3])
+			numNamed := int(ins[ip+4])
+			vm.currentFrame().ip += 4
+			name := getObjectAddress(vm.constants[nameIndex])
+			if err := vm.executeMethodCall(name, numPositional, numNamed); err != nil { return err }
+
 		case compiler.OpReturnValue:
 			returnValue, err := vm.pop()
 			if err != nil { return err }
-			frame := vm.popFrame()
-			vm.closeUpvalues(frame.basePointer)
+			if err := vm.returnValue(returnValue); err != nil { return err }
+			if vm.framesIndex == 0 { return nil }
+
+		case compiler.OpPropagate:
+			header, err := memory.ReadHeader(vm.stack[vm.sp-1])
+			if err != nil { return err }
+			if header.Type != memory.TagError { break }
+			errPtr, err := vm.pop()
+			if err != nil { return err }
+			if err := vm.returnValue(errPtr); err != nil { return err }
 			if vm.framesIndex == 0 {
-				vm.sp = 0
-			} else {
-				vm.sp = frame.basePointer - 1
+				vm.LastPoppedPtr = errPtr
+				if vm.task { return nil }
+				// Propagated out of the main program: the program fails
+				errObj := &object.Error{Address: errPtr}
+				return &UncaughtError{Message: errObj.GetMessage(), Code: errObj.GetCode()}
 			}
-			if err := vm.push(returnValue); err != nil { return err }
-			if vm.framesIndex == 0 { return nil }
 
 		case compiler.OpReturn:
 			frame := vm.popFrame()
@@ -342,16 +414,26 @@ func (vm *VM) Run() (err error) {
 		case compiler.OpStruct:
 			nameIndex := compiler.ReadUint16(ins[ip+1:])
 			fieldCount := int(compiler.ReadUint16(ins[ip+3:]))
-			vm.currentFrame().ip += 4
+			methodCount := int(ins[ip+5])
+			vm.currentFrame().ip += 5
 
 			nameObj := vm.constants[nameIndex]
 			namePtr := getObjectAddress(nameObj)
 
+			// Methods are (name, closure) pairs above the field names
+			methodsPtr := memory.NilPtr
+			if methodCount > 0 {
+				ptr, err := vm.buildHash(vm.sp-2*methodCount, vm.sp)
+				if err != nil { return err }
+				methodsPtr = ptr
+				vm.sp -= 2 * methodCount
+			}
+
 			fieldsPtr, err := vm.buildArray(vm.sp-fieldCount, vm.sp)
 			if err != nil { return err }
 			vm.sp -= fieldCount
 
-			ptr, err := memory.AllocSchema(namePtr, fieldsPtr)
+			ptr, err := memory.AllocSchema(namePtr, fieldsPtr, methodsPtr)
 			if err != nil { return err }
 			if err := vm.push(ptr); err != nil { return err }
 
@@ -371,6 +453,16 @@ func (vm *VM) Run() (err error) {
 			vm.sp -= numElements
 			if err := vm.push(ptr); err != nil { return err }
 
+		case compiler.OpFreeze:
+			if err := memory.FreezeHash(vm.stack[vm.sp-1]); err != nil { return err }
+
+		case compiler.OpFreezeKey:
+			constIndex := compiler.ReadUint16(ins[ip+1:])
+			vm.currentFrame().ip += 2
+			key := vm.constants[constIndex]
+			if err := ensureOnHeap(key); err != nil { return err }
+			if err := memory.FreezeHashKey(vm.stack[vm.sp-1], getObjectAddress(key)); err != nil { return err }
+
 		case compiler.OpIndex:
 			index, err := vm.pop()
 			if err != nil { return err }
@@ -378,6 +470,24 @@ func (vm *VM) Run() (err error) {
 			if err != nil { return err }
 			if err := vm.executeIndexExpression(left, index); err != nil { return err }
 
+		case compiler.OpSlice:
+			end, err := vm.pop()
+			if err != nil { return err }
+			start, err := vm.pop()
+			if err != nil { return err }
+			left, err := vm.pop()
+			if err != nil { return err }
+			if err := vm.executeSliceExpression(left, start, end); err != nil { return err }
+
+		case compiler.OpRange:
+			exclusive := ins[ip+1] == 1
+			vm.currentFrame().ip += 1
+			end, err := vm.pop()
+			if err != nil { return err }
+			start, err := vm.pop()
+			if err != nil { return err }
+			if err := vm.executeRange(start, end, exclusive); err != nil { return err }
+
 		case compiler.OpSetIndex:
 			val, err := vm.pop()
 			if err != nil { return err }
@@ -411,6 +521,65 @@ func (vm *VM) Run() (err error) {
 		case compiler.OpAnd, compiler.OpOr, compiler.OpXor, compiler.OpLShift, compiler.OpRShift:
 			if err := vm.executeBitwiseOperation(op); err != nil { return err }
 
+		case compiler.OpIter:
+			collection, err := vm.pop()
+			if err != nil { return err }
+			if err := vm.executeIter(collection); err != nil { return err }
+
+		case compiler.OpIterNext:
+			numVars := int(ins[ip+1])
+			vm.currentFrame().ip += 1
+			iter, err := vm.pop()
+			if err != nil { return err }
+			if err := vm.executeIterNext(iter, numVars); err != nil { return err }
+
+		case compiler.OpMatchType:
+			tag := memory.TypeTag(ins[ip+1])
+			vm.currentFrame().ip += 1
+			val, err := vm.pop()
+			if err != nil { return err }
+			header, err := memory.ReadHeader(val)
+			if err != nil { return err }
+			if err := vm.push(nativeBoolToPtr(header.Type == tag)); err != nil { return err }
+
+		case compiler.OpMatchSchema:
+			nameIndex := compiler.ReadUint16(ins[ip+1:])
+			vm.currentFrame().ip += 2
+			val, err := vm.pop()
+			if err != nil { return err }
+			name := vm.constants[nameIndex].(*object.String).GetValue()
+			if err := vm.executeMatchSchema(val, name); err != nil { return err }
+
+		case compiler.OpMatchKey:
+			key, err := vm.pop()
+			if err != nil { return err }
+			container, err := vm.pop()
+			if err != nil { return err }
+			if err := vm.executeMatchKey(container, key); err != nil { return err }
+
+		case compiler.OpArrayRest:
+			start := int(compiler.ReadUint16(ins[ip+1:]))
+			vm.currentFrame().ip += 2
+			arr, err := vm.pop()
+			if err != nil { return err }
+			if err := vm.executeArrayRest(arr, start); err != nil { return err }
+
+		case compiler.OpCheckType:
+			localIndex := int(ins[ip+1])
+			typesIndex := compiler.ReadUint16(ins[ip+2:])
+			vm.currentFrame().ip += 3
+			types := vm.constants[typesIndex].(*object.String).GetValue()
+			if err := vm.executeCheckType(localIndex, types); err != nil { return err }
+
+		case compiler.OpJumpIfPassed:
+			localIndex := int(ins[ip+1])
+			pos := int(compiler.ReadUint16(ins[ip+2:]))
+			vm.currentFrame().ip += 3
+			frame := vm.currentFrame()
+			if vm.stack[frame.basePointer+localIndex] != memory.NilPtr {
+				vm.currentFrame().ip = pos - 1
+			}
+
 		case compiler.OpJump:
 			pos := int(compiler.ReadUint16(ins[ip+1:]))
 			vm.currentFrame().ip = pos - 1
@@ -490,14 +659,10 @@ func (vm *VM) executeCall(numArgs int) error {
 		clWrapper := &object.Closure{Address: calleePtr}
 		fnWrapper := clWrapper.Fn()
 
-		if numArgs != fnWrapper.NumParameters() {
-			vm.sp -= (numArgs + 1)
-			errPtr, err := vm.newError(fmt.Sprintf("arg mismatch: want %d, got %d", fnWrapper.NumParameters(), numArgs))
-			if err != nil { return err }
-			return vm.push(errPtr)
-		}
+		ok, err := vm.bindArguments(fnWrapper, numArgs)
+		if err != nil || !ok { return err }
 
-		frame := NewFrame(clWrapper, vm.sp-numArgs)
+		frame := NewFrame(clWrapper, vm.sp-fnWrapper.NumParameters())
 		vm.pushFrame(frame)
 		vm.sp = frame.basePointer + fnWrapper.NumLocals()
 		return nil
@@ -527,6 +692,219 @@ func (vm *VM) executeCall(numArgs int) error {
 	return vm.push(errPtr)
 }
 
+// bindArguments makes the numArgs arguments on top of the stack fill exactly
+// the parameter slots of fn. Omitted optional parameters get an empty slot
+// (NilPtr) for the default value prologue to fill, and extra arguments are
+// packed into an array for the variadic parameter. When the count is not
+// accepted, the call is replaced by an Error and false is returned.
+func (vm *VM) bindArguments(fn *object.CompiledFunction, numArgs int) (bool, error) {
+	numParams := fn.NumParameters()
+	minParams, variadic, err := memory.ReadCompiledFunctionArity(fn.Address)
+	if err != nil { return false, err }
+
+	fixed := numParams
+	if variadic {
+		fixed--
+	}
+
+	if numArgs < minParams || (!variadic && numArgs > numParams) {
+		want := fmt.Sprintf("%d", numParams)
+		if variadic {
+			want = fmt.Sprintf("at least %d", minParams)
+		} else if minParams < numParams {
+			want = fmt.Sprintf("%d to %d", minParams, numParams)
+		}
+		vm.sp -= (numArgs + 1)
+		errPtr, err := vm.newError(fmt.Sprintf("arg mismatch: want %s, got %d", want, numArgs))
+		if err != nil { return false, err }
+		return false, vm.push(errPtr)
+	}
+
+	for ; numArgs < fixed; numArgs++ {
+		if err := vm.push(memory.NilPtr); err != nil { return false, err }
+	}
+
+	if variadic {
+		extra := numArgs - fixed
+		restPtr, err := vm.buildArray(vm.sp-extra, vm.sp)
+		if err != nil { return false, err }
+		vm.sp -= extra
+		if err := vm.push(restPtr); err != nil { return false, err }
+	}
+	return true, nil
+}
+
+// executeNamedCall binds named arguments to the parameters of a function or
+// the fields of a schema, then makes a positional call. The stack holds the
+// callee, the positional arguments and a (name, value) pair per named one.
+func (vm *VM) executeNamedCall(numPositional, numNamed int) error {
+	calleeIndex := vm.sp - 1 - numPositional - 2*numNamed
+	calleePtr := vm.stack[calleeIndex]
+
+	header, err := memory.ReadHeader(calleePtr)
+	if err != nil { return err }
+
+	// fixed is the number of slots names can fill, minArgs how many are required
+	var namesPtr memory.Ptr
+	fixed, minArgs, variadic := 0, 0, false
+
+	switch header.Type {
+	case memory.TagError:
+		// Propagate error: Drop args, leave error on stack
+		vm.sp = calleeIndex + 1
+		return nil
+
+	case memory.TagClosure:
+		fn := (&object.Closure{Address: calleePtr}).Fn()
+		if namesPtr, err = memory.ReadFunctionParamNames(fn.Address); err != nil { return err }
+		if minArgs, variadic, err = memory.ReadCompiledFunctionArity(fn.Address); err != nil { return err }
+		fixed = fn.NumParameters()
+		if variadic {
+			fixed--
+		}
+
+	case memory.TagSchema:
+		if _, namesPtr, err = memory.ReadSchema(calleePtr); err != nil { return err }
+		if fixed, err = memory.ReadArrayLength(namesPtr); err != nil { return err }
+		minArgs = fixed
+
+	case memory.TagBuiltin:
+		return vm.replaceCall(calleeIndex, "builtin functions do not accept named arguments")
+
+	default:
+		return vm.replaceCall(calleeIndex, fmt.Sprintf("named arguments need a function or struct, got type %d", header.Type))
+	}
+
+	if namesPtr == memory.NilPtr {
+		return vm.replaceCall(calleeIndex, "function does not accept named arguments")
+	}
+	names := make([]string, fixed)
+	for i := range names {
+		namePtr, err := memory.ReadArrayElement(namesPtr, i)
+		if err != nil { return err }
+		if names[i], err = memory.ReadString(namePtr); err != nil { return err }
+	}
+
+	args := make([]memory.Ptr, fixed)
+	extras := []memory.Ptr{}
+	for i := 0; i < numPositional; i++ {
+		val := vm.stack[calleeIndex+1+i]
+		if i < fixed {
+			args[i] = val
+		} else {
+			extras = append(extras, val)
+		}
+	}
+	if len(extras) > 0 && !variadic {
+		return vm.replaceCall(calleeIndex, fmt.Sprintf("arg mismatch: want at most %d positional, got %d", fixed, numPositional))
+	}
+
+	for i := 0; i < numNamed; i++ {
+		pair := calleeIndex + 1 + numPositional + 2*i
+		name, err := memory.ReadString(vm.stack[pair])
+		if err != nil { return err }
+
+		index := -1
+		for j, n := range names {
+			if n == name {
+				index = j
+				break
+			}
+		}
+		if index < 0 {
+			return vm.replaceCall(calleeIndex, fmt.Sprintf("unknown argument '%s'", name))
+		}
+		if args[index] != memory.NilPtr {
+			return vm.replaceCall(calleeIndex, fmt.Sprintf("argument '%s' given more than once", name))
+		}
+		args[index] = vm.stack[pair+1]
+	}
+
+	for i := 0; i < minArgs; i++ {
+		if args[i] == memory.NilPtr {
+			return vm.replaceCall(calleeIndex, fmt.Sprintf("missing argument '%s'", names[i]))
+		}
+	}
+
+	// Lay the arguments out by position; omitted optional ones stay empty
+	vm.sp = calleeIndex + 1
+	for _, val := range append(args, extras...) {
+		if err := vm.push(val); err != nil { return err }
+	}
+	return vm.executeCall(len(args) + len(extras))
+}
+
+// executeMethodCall calls receiver.name with the arguments above the
+// receiver. A method of a struct gets the receiver as its first argument
+// (`ini`); anything else, such as a function stored in a field or exported by
+// a module, is looked up like `receiver.name` and called as is.
+func (vm *VM) executeMethodCall(name memory.Ptr, numPositional, numNamed int) error {
+	receiverIndex := vm.sp - 1 - numPositional - 2*numNamed
+	receiver := vm.stack[receiverIndex]
+
+	header, err := memory.ReadHeader(receiver)
+	if err != nil { return err }
+
+	if header.Type == memory.TagStruct {
+		method, isField, err := structMember(receiver, name)
+		if err != nil { return err }
+		if method != memory.NilPtr {
+			// Slide the arguments up to put the method below its receiver
+			if err := vm.push(memory.NilPtr); err != nil { return err }
+			copy(vm.stack[receiverIndex+1:vm.sp], vm.stack[receiverIndex:vm.sp-1])
+			vm.stack[receiverIndex] = method
+			return vm.callWith(numPositional+1, numNamed)
+		}
+		if !isField {
+			schemaPtr, err := memory.ReadStructSchema(receiver)
+			if err != nil { return err }
+			key, _ := memory.ReadString(name)
+			return vm.replaceCall(receiverIndex, fmt.Sprintf("struktur %s has no method '%s'", (&object.Schema{Address: schemaPtr}).Name(), key))
+		}
+	}
+
+	if err := vm.executeIndexExpression(receiver, name); err != nil { return err }
+	callee, err := vm.pop()
+	if err != nil { return err }
+	vm.stack[receiverIndex] = callee
+	return vm.callWith(numPositional, numNamed)
+}
+
+// callWith calls the callee below the arguments on top of the stack.
+func (vm *VM) callWith(numPositional, numNamed int) error {
+	if numNamed > 0 {
+		return vm.executeNamedCall(numPositional, numNamed)
+	}
+	return vm.executeCall(numPositional)
+}
+
+// structMember looks name up in a struct instance. Fields take precedence
+// over methods; method is NilPtr when the schema has no method of that name.
+func structMember(structPtr, name memory.Ptr) (method memory.Ptr, isField bool, err error) {
+	key, err := memory.ReadString(name)
+	if err != nil { return memory.NilPtr, false, err }
+	i, err := structFieldIndex(structPtr, key)
+	if err != nil || i >= 0 { return memory.NilPtr, i >= 0, err }
+
+	schemaPtr, err := memory.ReadStructSchema(structPtr)
+	if err != nil { return memory.NilPtr, false, err }
+	methodsPtr, err := memory.ReadSchemaMethods(schemaPtr)
+	if err != nil || methodsPtr == memory.NilPtr { return memory.NilPtr, false, err }
+
+	method, found, err := memory.HashGet(methodsPtr, name)
+	if err != nil || !found { return memory.NilPtr, false, err }
+	return method, false, nil
+}
+
+// replaceCall drops a call whose callee sits at calleeIndex, together with
+// its arguments, and leaves an Error with msg in its place.
+func (vm *VM) replaceCall(calleeIndex int, msg string) error {
+	vm.sp = calleeIndex
+	errPtr, err := vm.newError(msg)
+	if err != nil { return err }
+	return vm.push(errPtr)
+}
+
 func (vm *VM) executeSchemaCall(schemaPtr memory.Ptr, numArgs int) error {
 	_, fieldsPtr, err := memory.ReadSchema(schemaPtr)
 	if err != nil { return err }
@@ -541,7 +919,11 @@ func (vm *VM) executeSchemaCall(schemaPtr memory.Ptr, numArgs int) error {
 		return vm.push(errPtr)
 	}
 
-	structPtr, err := memory.AllocStruct(schemaPtr, numArgs)
+	alloc := memory.AllocStruct
+	if _, _, ok := (&object.Schema{Address: schemaPtr}).Variant(); ok {
+		alloc = memory.AllocVariant
+	}
+	structPtr, err := alloc(schemaPtr, numArgs)
 	if err != nil { return err }
 
 	for i := numArgs - 1; i >= 0; i-- {
@@ -660,22 +1042,32 @@ func executeTask(ptr memory.Ptr) {
 	taskRegistry.Delete(id)
 	ctx := val.(TaskContext)
 
-	frames := make([]*Frame, MaxFrames)
-	frames[0] = NewFrame(ctx.Closure, 0)
-
 	newVM := &VM{
 		constants: ctx.Constants,
 		globals: ctx.Globals,
 		stack: [StackSize]memory.Ptr{},
-		sp: ctx.Closure.Fn().NumLocals(),
-		frames: frames,
-		framesIndex: 1,
+		frames: make([]*Frame, MaxFrames),
 		Cabinet: &memory.Lemari,
+		task: true,
 	}
 	activeVMs.Store(newVM, true)
 	defer activeVMs.Delete(newVM)
 
-	err := newVM.Run()
+	// Bind like a call without arguments, so default and variadic parameters
+	// get their values; an arity mismatch leaves its Error as the result
+	fn := ctx.Closure.Fn()
+	newVM.stack[0] = ctx.Closure.Address
+	newVM.sp = 1
+	GlobalVMLock.RLock()
+	ok, err := newVM.bindArguments(fn, 0)
+	GlobalVMLock.RUnlock()
+
+	if err == nil && ok {
+		frame := NewFrame(ctx.Closure, newVM.sp-fn.NumParameters())
+		newVM.pushFrame(frame)
+		newVM.sp = frame.basePointer + fn.NumLocals()
+		err = newVM.Run()
+	}
 	if err != nil {
 		ctx.ResultCh <- object.NewError(err.Error(), "", 0, 0)
 	} else {
@@ -0,0 +1,96 @@
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/VzoelFox/morphlang/pkg/object"
+)
+
+func bigInt(s string) *big.Int {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("bad big.Int literal " + s)
+	}
+	return n
+}
+
+func TestBigIntPromotion(t *testing.T) {
+	tests := []vmTestCase{
+		{"9223372036854775807 + 1", bigInt("9223372036854775808")},
+		{"-9223372036854775807 - 2", bigInt("-9223372036854775809")},
+		{"4294967296 * 4294967296", bigInt("18446744073709551616")},
+		{"2 ** 100", bigInt("1267650600228229401496703205376")},
+		{"1 << 70", bigInt("1180591620717411303424")},
+		{"-(-9223372036854775807 - 1)", bigInt("9223372036854775808")},
+		{"(-9223372036854775807 - 1) // -1", bigInt("9223372036854775808")},
+		// Results that fit come back as plain integers
+		{"(9223372036854775807 + 1) - 1", 9223372036854775807},
+		{"2 ** 100 // 2 ** 98", 4},
+		{"(2 ** 64 + 5) % 2 ** 64", 5},
+		{"-(2 ** 65) % 3", 1},
+		{"(2 ** 70) >> 68", 4},
+		{"(2 ** 64) & 1", 0},
+		{"~(2 ** 64)", bigInt("-18446744073709551617")},
+		{"2 ** -1", 0.5},
+		{"2 ** 64 + 0.5", 18446744073709551616.5},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestBigIntComparison(t *testing.T) {
+	tests := []vmTestCase{
+		{"2 ** 64 == 2 ** 64", true},
+		{"2 ** 64 != 2 ** 65", true},
+		{"2 ** 64 > 9223372036854775807", true},
+		{"-(2 ** 64) < 0", true},
+		{"2 ** 64 == 18446744073709551616.0", true},
+		{"2 ** 64 < 1.0e30", true},
+		{"9223372036854775807 + 1 == 9223372036854775807", false},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestBigIntConversions(t *testing.T) {
+	tests := []vmTestCase{
+		{`"n=" + 2 ** 64`, "n=18446744073709551616"},
+		{`tipe(2 ** 64)`, "INTEGER"},
+		{`h = {}; h[2 ** 64] = "besar"; h[2 ** 63 * 2]`, "besar"},
+		{`{2 ** 64: 1}[2 ** 64 - 1]`, nil},
+		{`[1, 2][2 ** 64]`, nil},
+		{`abs(-(2 ** 64))`, bigInt("18446744073709551616")},
+		{`sqrt(2 ** 64)`, 4294967296.0},
+		{`a = 2 ** 70; max(a, 1)`, bigInt("1180591620717411303424")},
+		{`max(1, 2 ** 70)`, bigInt("1180591620717411303424")},
+		{`max(-(2 ** 70), 1)`, 1},
+		{`min(2 ** 70, 1)`, 1},
+		{`min(-(2 ** 70), 1)`, bigInt("-1180591620717411303424")},
+		{`min(2 ** 70, 2 ** 71)`, bigInt("1180591620717411303424")},
+		{`max(2 ** 70, 1.5)`, object.NewError("second argument to `max` must be INTEGER, got FLOAT", "", 0, 0)},
+		{
+			input: `
+			f = 1
+			i = 1
+			selama i <= 25
+			  f = f * i
+			  i = i + 1
+			akhir
+			f`,
+			expected: bigInt("15511210043330985984000000"),
+		},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestBigIntErrors(t *testing.T) {
+	tests := []vmTestCase{
+		{"2 ** 10000000", object.NewError("integer too large: results are limited to 524032 bits", "", 0, 0)},
+		{"1 << 10000000", object.NewError("integer too large: results are limited to 524032 bits", "", 0, 0)},
+		{"2 ** 64 // 0", object.NewError("integer divide by zero", "", 0, 0)},
+	}
+
+	runVmTests(t, tests)
+}
@@ -48,6 +48,10 @@ func TestBitwiseIntegerArithmetic(t *testing.T) {
 		{"5 | 2 & 1", 5},
 		{"1 << 2 + 1", 8},
 		{"10 * 2 >> 1", 10},
+
+		{"0xFF & 0b1010", 10},
+		{"0o755 | 0x1000", 4589},
+		{"1_000 << 0b10", 4000},
 	}
 
 	runVmTests(t, tests)
@@ -0,0 +1,94 @@
+package vm
+
+import (
+	"testing"
+
+	"github.com/VzoelFox/morphlang/pkg/object"
+)
+
+func decimal(s string) object.DecimalValue {
+	d, err := object.ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	tests := []vmTestCase{
+		{`desimal("0.1") + desimal("0.2")`, decimal("0.3")},
+		{`desimal("0.1") + desimal("0.2") == desimal("0.3")`, true},
+		{`desimal("19.99") * 3`, decimal("59.97")},
+		{`desimal("1.1") * desimal("1.1")`, decimal("1.21")},
+		{`desimal("10.00") - 2`, decimal("8.00")},
+		{`-desimal("3.10")`, decimal("-3.10")},
+		// Division rounds half to even at 16 places (or the operand scale)
+		{`desimal("10.00") / 3`, decimal("3.3333333333333333")},
+		{`desimal("2") / desimal("3")`, decimal("0.6666666666666667")},
+		{`desimal("1") / desimal("0.00000000000000000008")`, decimal("12500000000000000000.00000000000000000000")},
+		{`desimal("-7.5") % 2`, decimal("0.5")},
+		{`desimal("7.5") // 2`, decimal("3")},
+		{`desimal("1.5") ** 2`, decimal("2.25")},
+		{`desimal("2") ** -2`, decimal("0.2500000000000000")},
+		{`desimal("1.50") > 1`, true},
+		{`desimal("1.50") == desimal("1.5")`, true},
+		{`2 >= desimal("2.01")`, false},
+		{`"Total: " + desimal("12.5", 2)`, "Total: 12.50"},
+		{`h = {}; h[desimal("1.50")] = "x"; h[desimal("1.5")]`, "x"},
+		{`tipe(desimal("1"))`, "DECIMAL"},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestDecimalConstructor(t *testing.T) {
+	tests := []vmTestCase{
+		{`desimal("1_234.50")`, decimal("1234.50")},
+		{`desimal("1.5e3")`, decimal("1500")},
+		{`desimal(42)`, decimal("42")},
+		{`desimal(2 ** 70)`, decimal("1180591620717411303424")},
+		{`desimal(0.1)`, decimal("0.1")},
+		{`desimal(1, 2)`, decimal("1.00")},
+		{`desimal("2.675", 2)`, decimal("2.68")},
+		{`desimal("2.665", 2)`, decimal("2.66")},
+		{`desimal("2.665", 2, "setengah_atas")`, decimal("2.67")},
+		{`desimal("2.665", 2, "setengah_bawah")`, decimal("2.66")},
+		{`desimal("-2.5", 0, "menuju_nol")`, decimal("-2")},
+		{`desimal("-2.1", 0, "menjauhi_nol")`, decimal("-3")},
+		{`desimal("-2.1", 0, "lantai")`, decimal("-3")},
+		{`desimal("-2.9", 0, "atap")`, decimal("-2")},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestDecimalDivision(t *testing.T) {
+	tests := []vmTestCase{
+		// The exact quotient 0.00500000000000000333... is rounded once. Going
+		// through `/` first would leave 0.0050000000000000, a tie rounded down
+		{`bagi_desimal(desimal("0.01500000000000001"), desimal("3"), 2, "setengah_bawah")`, decimal("0.01")},
+		{`bagi_desimal(desimal("10.00"), 3, 2)`, decimal("3.33")},
+		{`bagi_desimal(2, 3, 0, "menuju_nol")`, decimal("0")},
+		{`bagi_desimal(desimal("-1"), 8, 2, "lantai")`, decimal("-0.13")},
+		{`bagi_desimal(desimal("1"), 0, 2)`, object.NewError("decimal divide by zero", object.ErrCodeRuntime, 0, 0)},
+		{`bagi_desimal(1.5, 3, 2)`, object.NewError("arguments to `bagi_desimal` must be DECIMAL or INTEGER, got FLOAT", object.ErrCodeTypeMismatch, 0, 0)},
+		{`bagi_desimal(1, 3, 2, "acak")`, object.NewError(`unknown rounding mode "acak"`, "", 0, 0)},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestDecimalErrors(t *testing.T) {
+	tests := []vmTestCase{
+		{`desimal("0.5") + 0.5`, object.NewError("type mismatch: DECIMAL and FLOAT cannot be mixed, convert with desimal()", "", 0, 0)},
+		{`1.5 < desimal("2")`, object.NewError("type mismatch: DECIMAL and FLOAT cannot be mixed, convert with desimal()", "", 0, 0)},
+		{`desimal("1") * benar`, object.NewError("type mismatch: unsupported operand boolean for DECIMAL", "", 0, 0)},
+		{`desimal("2") ** desimal("0.5")`, object.NewError("type mismatch: the exponent of a DECIMAL must be an INTEGER", "", 0, 0)},
+		{`desimal("1") / 0`, object.NewError("decimal divide by zero", "", 0, 0)},
+		{`desimal("12,5")`, object.NewError(`invalid decimal "12,5"`, "", 0, 0)},
+		{`desimal("1", -1)`, object.NewError("scale of `desimal` must be an INTEGER from 0 to 10000", "", 0, 0)},
+		{`desimal("1", 2, "acak")`, object.NewError(`unknown rounding mode "acak"`, "", 0, 0)},
+	}
+
+	runVmTests(t, tests)
+}
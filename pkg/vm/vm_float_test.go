@@ -2,6 +2,8 @@ package vm
 
 import (
 	"testing"
+
+	"github.com/VzoelFox/morphlang/pkg/object"
 )
 
 func TestFloatArithmetic(t *testing.T) {
@@ -35,6 +37,33 @@ func TestFloatArithmetic(t *testing.T) {
 	runVmTests(t, tests)
 }
 
+func TestModuloDivisionPower(t *testing.T) {
+	tests := []vmTestCase{
+		{"7 % 3", 1},
+		{"-7 % 3", 2},
+		{"7 % -3", -2},
+		{"7 // 2", 3},
+		{"-7 // 2", -4},
+		{"2 ** 10", 1024},
+		{"2 ** 3 ** 2", 512},
+		{"-2 ** 2", -4},
+		{"2 ** 0", 1},
+		{"2 ** -1", 0.5},
+		{"7.5 % 2", 1.5},
+		{"-1.5 % 1", 0.5},
+		{"7.0 // 2", 3.0},
+		{"2.0 ** 3", 8.0},
+		{"10 % 4 * 2", 4},
+		{"5 % 0", object.NewError("integer divide by zero", "", 0, 0)},
+		{"5 // 0", object.NewError("integer divide by zero", "", 0, 0)},
+		{"5.0 % 0", object.NewError("float divide by zero", "", 0, 0)},
+		{"1.0 / 0", object.NewError("float divide by zero", "", 0, 0)},
+		{"1 / 0.0", object.NewError("float divide by zero", "", 0, 0)},
+	}
+
+	runVmTests(t, tests)
+}
+
 func TestStringConcatenationMixed(t *testing.T) {
 	tests := []struct {
 		input    string
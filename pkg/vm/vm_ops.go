@@ -2,8 +2,13 @@ package vm
 
 import (
 	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"unicode/utf8"
 	"github.com/VzoelFox/morphlang/pkg/compiler"
 	"github.com/VzoelFox/morphlang/pkg/memory"
+	"github.com/VzoelFox/morphlang/pkg/object"
 )
 
 // Helper to check and propagate error objects
@@ -26,6 +31,17 @@ func (vm *VM) pushRuntimeError(msg string) error {
 	return vm.push(errPtr)
 }
 
+// Helper to create and push a type mismatch error
+func (vm *VM) pushTypeMismatch(msg string) error {
+	msgPtr, err := memory.AllocString(msg)
+	if err != nil { return err }
+	codePtr, err := memory.AllocString(object.ErrCodeTypeMismatch)
+	if err != nil { return err }
+	errPtr, err := memory.AllocError(msgPtr, codePtr, 0, 0)
+	if err != nil { return err }
+	return vm.push(errPtr)
+}
+
 func (vm *VM) executeBinaryOperation(op compiler.Opcode) error {
 	right, err := vm.pop()
 	if err != nil { return err }
@@ -53,6 +69,10 @@ func (vm *VM) executeBinaryOperation(op compiler.Opcode) error {
 		return vm.push(ptr)
 	}
 
+	if leftHeader.Type == memory.TagDecimal || rightHeader.Type == memory.TagDecimal {
+		return vm.executeDecimalOperation(op, left, right)
+	}
+
 	// Numeric Arithmetic
 	isFloat := leftHeader.Type == memory.TagFloat || rightHeader.Type == memory.TagFloat
 
@@ -64,23 +84,53 @@ func (vm *VM) executeBinaryOperation(op compiler.Opcode) error {
 		case compiler.OpAdd: res = leftVal + rightVal
 		case compiler.OpSub: res = leftVal - rightVal
 		case compiler.OpMul: res = leftVal * rightVal
-		case compiler.OpDiv: res = leftVal / rightVal
+		case compiler.OpDiv:
+			if rightVal == 0 { return vm.pushRuntimeError("float divide by zero") }
+			res = leftVal / rightVal
+		case compiler.OpMod:
+			if rightVal == 0 { return vm.pushRuntimeError("float divide by zero") }
+			res = object.FloorModFloat(leftVal, rightVal)
+		case compiler.OpFloorDiv:
+			if rightVal == 0 { return vm.pushRuntimeError("float divide by zero") }
+			res = math.Floor(leftVal / rightVal)
+		case compiler.OpPow: res = math.Pow(leftVal, rightVal)
 		}
 		ptr, err := memory.AllocFloat(res)
 		if err != nil { return err }
 		return vm.push(ptr)
+	} else if leftHeader.Type == memory.TagBigInt || rightHeader.Type == memory.TagBigInt {
+		return vm.executeBigIntOperation(op, left, right)
 	} else {
 		// Integer
 		leftVal, _ := memory.ReadInteger(left)
 		rightVal, _ := memory.ReadInteger(right)
 		var res int64
+		ok := true
 		switch op {
-		case compiler.OpAdd: res = leftVal + rightVal
-		case compiler.OpSub: res = leftVal - rightVal
-		case compiler.OpMul: res = leftVal * rightVal
+		case compiler.OpAdd: res, ok = object.AddInt(leftVal, rightVal)
+		case compiler.OpSub: res, ok = object.SubInt(leftVal, rightVal)
+		case compiler.OpMul: res, ok = object.MulInt(leftVal, rightVal)
 		case compiler.OpDiv:
 			if rightVal == 0 { return vm.pushRuntimeError("integer divide by zero") }
-			res = leftVal / rightVal
+			res, ok = leftVal / rightVal, !(leftVal == math.MinInt64 && rightVal == -1)
+		case compiler.OpMod:
+			if rightVal == 0 { return vm.pushRuntimeError("integer divide by zero") }
+			res = object.FloorModInt(leftVal, rightVal)
+		case compiler.OpFloorDiv:
+			if rightVal == 0 { return vm.pushRuntimeError("integer divide by zero") }
+			res, ok = object.FloorDivInt(leftVal, rightVal), !(leftVal == math.MinInt64 && rightVal == -1)
+		case compiler.OpPow:
+			if rightVal < 0 {
+				// Negative exponents leave the integers
+				ptr, err := memory.AllocFloat(math.Pow(float64(leftVal), float64(rightVal)))
+				if err != nil { return err }
+				return vm.push(ptr)
+			}
+			res, ok = object.PowInt64(leftVal, rightVal)
+		}
+		if !ok {
+			// Overflow promotes to a BigInt
+			return vm.executeBigIntOperation(op, left, right)
 		}
 		ptr, err := memory.AllocInteger(res)
 		if err != nil { return err }
@@ -88,6 +138,142 @@ func (vm *VM) executeBinaryOperation(op compiler.Opcode) error {
 	}
 }
 
+// executeBigIntOperation redoes integer arithmetic with math/big when an
+// operand is a BigInt or the int64 result would overflow.
+func (vm *VM) executeBigIntOperation(op compiler.Opcode, left, right memory.Ptr) error {
+	leftVal := toBigInt(left)
+	rightVal := toBigInt(right)
+	res := new(big.Int)
+	switch op {
+	case compiler.OpAdd: res.Add(leftVal, rightVal)
+	case compiler.OpSub: res.Sub(leftVal, rightVal)
+	case compiler.OpMul:
+		if leftVal.BitLen()+rightVal.BitLen() > memory.MaxBigIntBits+1 { return vm.pushIntegerTooLarge() }
+		res.Mul(leftVal, rightVal)
+	case compiler.OpDiv:
+		if rightVal.Sign() == 0 { return vm.pushRuntimeError("integer divide by zero") }
+		res.Quo(leftVal, rightVal)
+	case compiler.OpMod:
+		if rightVal.Sign() == 0 { return vm.pushRuntimeError("integer divide by zero") }
+		res = object.FloorModBig(leftVal, rightVal)
+	case compiler.OpFloorDiv:
+		if rightVal.Sign() == 0 { return vm.pushRuntimeError("integer divide by zero") }
+		res = object.FloorDivBig(leftVal, rightVal)
+	case compiler.OpPow:
+		if rightVal.Sign() < 0 {
+			ptr, err := memory.AllocFloat(math.Pow(toFloat(left), toFloat(right)))
+			if err != nil { return err }
+			return vm.push(ptr)
+		}
+		// The result has at least (bits - 1) * exponent bits; refuse before computing it
+		if leftVal.CmpAbs(big.NewInt(1)) > 0 {
+			if !rightVal.IsInt64() || rightVal.Int64() > int64(memory.MaxBigIntBits/(leftVal.BitLen()-1)) {
+				return vm.pushIntegerTooLarge()
+			}
+		}
+		res.Exp(leftVal, rightVal, nil)
+	}
+	return vm.pushBigInt(res)
+}
+
+// executeDecimalOperation does exact decimal arithmetic. The other operand
+// may be an integer; a float is a type mismatch rather than a silent
+// conversion. Only `/` (and `**` with a negative exponent) rounds, half to
+// even at object.DecimalDivScale or the larger operand scale.
+func (vm *VM) executeDecimalOperation(op compiler.Opcode, left, right memory.Ptr) error {
+	leftVal, ok := toDecimal(left)
+	if !ok { return vm.pushDecimalMismatch(left) }
+	rightVal, ok := toDecimal(right)
+	if !ok { return vm.pushDecimalMismatch(right) }
+
+	var res object.DecimalValue
+	switch op {
+	case compiler.OpAdd, compiler.OpSub:
+		x, y, scale := object.AlignDecimals(leftVal, rightVal)
+		if op == compiler.OpAdd {
+			res = object.DecimalValue{Unscaled: x.Add(x, y), Scale: scale}
+		} else {
+			res = object.DecimalValue{Unscaled: x.Sub(x, y), Scale: scale}
+		}
+	case compiler.OpMul:
+		if leftVal.Unscaled.BitLen()+rightVal.Unscaled.BitLen() > memory.MaxBigIntBits+1 { return vm.pushDecimalTooLarge() }
+		res = object.DecimalValue{Unscaled: new(big.Int).Mul(leftVal.Unscaled, rightVal.Unscaled), Scale: leftVal.Scale + rightVal.Scale}
+	case compiler.OpDiv:
+		if rightVal.Unscaled.Sign() == 0 { return vm.pushRuntimeError("decimal divide by zero") }
+		res = object.QuoDecimal(leftVal, rightVal, divScale(leftVal, rightVal), object.RoundHalfEven)
+	case compiler.OpMod, compiler.OpFloorDiv:
+		if rightVal.Unscaled.Sign() == 0 { return vm.pushRuntimeError("decimal divide by zero") }
+		x, y, scale := object.AlignDecimals(leftVal, rightVal)
+		if op == compiler.OpMod {
+			res = object.DecimalValue{Unscaled: object.FloorModBig(x, y), Scale: scale}
+		} else {
+			res = object.DecimalFromInt(object.FloorDivBig(x, y))
+		}
+	case compiler.OpPow:
+		exp, err := memory.ReadHeader(right)
+		if err != nil { return err }
+		if !isInteger(exp.Type) { return vm.pushTypeMismatch("type mismatch: the exponent of a DECIMAL must be an INTEGER") }
+		n := toBigInt(right)
+		abs := new(big.Int).Abs(n)
+		if leftVal.Unscaled.BitLen() > 1 {
+			if !abs.IsInt64() || abs.Int64() > int64(memory.MaxBigIntBits/(leftVal.Unscaled.BitLen()-1)) { return vm.pushDecimalTooLarge() }
+		}
+		if !abs.IsInt64() || leftVal.Scale > 0 && abs.Int64() > int64(object.MaxDecimalScale/leftVal.Scale) { return vm.pushDecimalTooLarge() }
+		res = object.DecimalValue{Unscaled: new(big.Int).Exp(leftVal.Unscaled, abs, nil), Scale: leftVal.Scale * int(abs.Int64())}
+		if n.Sign() < 0 {
+			if res.Unscaled.Sign() == 0 { return vm.pushRuntimeError("decimal divide by zero") }
+			one := object.DecimalFromInt(big.NewInt(1))
+			res = object.QuoDecimal(one, res, divScale(leftVal, leftVal), object.RoundHalfEven)
+		}
+	}
+	if res.Unscaled.BitLen() > memory.MaxBigIntBits || res.Scale > object.MaxDecimalScale { return vm.pushDecimalTooLarge() }
+
+	ptr, err := memory.AllocDecimal(res.Unscaled, int32(res.Scale))
+	if err != nil { return err }
+	return vm.push(ptr)
+}
+
+// divScale is the scale of a rounded decimal quotient.
+func divScale(a, b object.DecimalValue) int {
+	scale := object.DecimalDivScale
+	if a.Scale > scale { scale = a.Scale }
+	if b.Scale > scale { scale = b.Scale }
+	return scale
+}
+
+func (vm *VM) pushDecimalMismatch(operand memory.Ptr) error {
+	header, err := memory.ReadHeader(operand)
+	if err != nil { return err }
+	if header.Type == memory.TagFloat {
+		return vm.pushTypeMismatch("type mismatch: DECIMAL and FLOAT cannot be mixed, convert with desimal()")
+	}
+	got, err := typeNameOf(operand)
+	if err != nil { return err }
+	return vm.pushTypeMismatch(fmt.Sprintf("type mismatch: unsupported operand %s for DECIMAL", got))
+}
+
+func (vm *VM) pushDecimalTooLarge() error {
+	return vm.pushRuntimeError("decimal too large")
+}
+
+// pushBigInt pushes an integer result, stored as a BigInt only when it does
+// not fit in int64.
+func (vm *VM) pushBigInt(val *big.Int) error {
+	if val.IsInt64() {
+		ptr, err := memory.AllocInteger(val.Int64())
+		if err != nil { return err }
+		return vm.push(ptr)
+	}
+	if val.BitLen() > memory.MaxBigIntBits { return vm.pushIntegerTooLarge() }
+	ptr, err := memory.AllocBigInt(val)
+	if err != nil { return err }
+	return vm.push(ptr)
+}
+
+func (vm *VM) pushIntegerTooLarge() error {
+	return vm.pushRuntimeError(fmt.Sprintf("integer too large: results are limited to %d bits", memory.MaxBigIntBits))
+}
+
 func (vm *VM) executeComparison(op compiler.Opcode) error {
 	right, err := vm.pop()
 	if err != nil { return err }
@@ -115,9 +301,41 @@ func (vm *VM) executeComparison(op compiler.Opcode) error {
 		return vm.push(ptr)
 	}
 
+	if isInteger(leftHeader.Type) && isInteger(rightHeader.Type) {
+		// At least one side is a BigInt
+		cmp := toBigInt(left).Cmp(toBigInt(right))
+		var val bool
+		switch op {
+		case compiler.OpEqual: val = cmp == 0
+		case compiler.OpNotEqual: val = cmp != 0
+		case compiler.OpGreaterThan: val = cmp > 0
+		case compiler.OpGreaterEqual: val = cmp >= 0
+		}
+		return vm.push(nativeBoolToPtr(val))
+	}
+
+	if leftHeader.Type == memory.TagDecimal || rightHeader.Type == memory.TagDecimal {
+		leftVal, leftOk := toDecimal(left)
+		rightVal, rightOk := toDecimal(right)
+		if leftHeader.Type == memory.TagFloat || rightHeader.Type == memory.TagFloat {
+			return vm.pushTypeMismatch("type mismatch: DECIMAL and FLOAT cannot be mixed, convert with desimal()")
+		}
+		if leftOk && rightOk {
+			cmp := leftVal.Cmp(rightVal)
+			var val bool
+			switch op {
+			case compiler.OpEqual: val = cmp == 0
+			case compiler.OpNotEqual: val = cmp != 0
+			case compiler.OpGreaterThan: val = cmp > 0
+			case compiler.OpGreaterEqual: val = cmp >= 0
+			}
+			return vm.push(nativeBoolToPtr(val))
+		}
+	}
+
 	// Mixed Float/Int Comparison
-	if (leftHeader.Type == memory.TagInteger || leftHeader.Type == memory.TagFloat) &&
-	   (rightHeader.Type == memory.TagInteger || rightHeader.Type == memory.TagFloat) {
+	if (isInteger(leftHeader.Type) || leftHeader.Type == memory.TagFloat) &&
+	   (isInteger(rightHeader.Type) || rightHeader.Type == memory.TagFloat) {
 		leftVal := toFloat(left)
 		rightVal := toFloat(right)
 		var val bool
@@ -155,6 +373,20 @@ func (vm *VM) executeComparison(op compiler.Opcode) error {
 		return vm.push(ptr)
 	}
 
+	if leftHeader.Type == memory.TagVariant || rightHeader.Type == memory.TagVariant {
+		switch op {
+		case compiler.OpEqual: return vm.push(nativeBoolToPtr(variantMatches(left, right)))
+		case compiler.OpNotEqual: return vm.push(nativeBoolToPtr(!variantMatches(left, right)))
+		}
+	}
+
+	if leftHeader.Type == memory.TagRange && rightHeader.Type == memory.TagRange {
+		switch op {
+		case compiler.OpEqual: return vm.push(nativeBoolToPtr(equals(left, right)))
+		case compiler.OpNotEqual: return vm.push(nativeBoolToPtr(!equals(left, right)))
+		}
+	}
+
 	if leftHeader.Type == memory.TagNull && rightHeader.Type == memory.TagNull {
 		if op == compiler.OpEqual { return vm.push(TruePtr) }
 		if op == compiler.OpNotEqual { return vm.push(FalsePtr) }
@@ -183,9 +415,12 @@ func (vm *VM) executeBitwiseOperation(op compiler.Opcode) error {
 	leftHeader, _ := memory.ReadHeader(left)
 	rightHeader, _ := memory.ReadHeader(right)
 
-	if leftHeader.Type != memory.TagInteger || rightHeader.Type != memory.TagInteger {
+	if !isInteger(leftHeader.Type) || !isInteger(rightHeader.Type) {
 		return vm.pushRuntimeError(fmt.Sprintf("unsupported types for bitwise operation: type tag %d type tag %d", leftHeader.Type, rightHeader.Type))
 	}
+	if leftHeader.Type == memory.TagBigInt || rightHeader.Type == memory.TagBigInt {
+		return vm.executeBigIntBitwise(op, left, right)
+	}
 
 	leftVal, _ := memory.ReadInteger(left)
 	rightVal, _ := memory.ReadInteger(right)
@@ -201,6 +436,10 @@ func (vm *VM) executeBitwiseOperation(op compiler.Opcode) error {
 	case compiler.OpLShift:
 		if rightVal < 0 { return vm.pushRuntimeError("negative shift count") }
 		res = leftVal << rightVal
+		if rightVal >= 64 && leftVal != 0 || res>>rightVal != leftVal {
+			// Bits shifted out promote to a BigInt
+			return vm.executeBigIntBitwise(op, left, right)
+		}
 	case compiler.OpRShift:
 		if rightVal < 0 { return vm.pushRuntimeError("negative shift count") }
 		res = leftVal >> rightVal
@@ -211,6 +450,32 @@ func (vm *VM) executeBitwiseOperation(op compiler.Opcode) error {
 	return vm.push(ptr)
 }
 
+// executeBigIntBitwise applies a bitwise operator with math/big, which uses
+// two's complement semantics for negative values like int64 does.
+func (vm *VM) executeBigIntBitwise(op compiler.Opcode, left, right memory.Ptr) error {
+	leftVal := toBigInt(left)
+	rightVal := toBigInt(right)
+	res := new(big.Int)
+	switch op {
+	case compiler.OpAnd: res.And(leftVal, rightVal)
+	case compiler.OpOr: res.Or(leftVal, rightVal)
+	case compiler.OpXor: res.Xor(leftVal, rightVal)
+	case compiler.OpLShift, compiler.OpRShift:
+		if rightVal.Sign() < 0 { return vm.pushRuntimeError("negative shift count") }
+		if op == compiler.OpRShift {
+			// Shifting past every bit leaves 0 or -1
+			n := uint(leftVal.BitLen() + 1)
+			if rightVal.IsInt64() && rightVal.Int64() < int64(n) { n = uint(rightVal.Int64()) }
+			res.Rsh(leftVal, n)
+			break
+		}
+		if leftVal.Sign() == 0 { break }
+		if !rightVal.IsInt64() || rightVal.Int64() > int64(memory.MaxBigIntBits) { return vm.pushIntegerTooLarge() }
+		res.Lsh(leftVal, uint(rightVal.Int64()))
+	}
+	return vm.pushBigInt(res)
+}
+
 func (vm *VM) executeBangOperator() error {
 	operand, err := vm.pop()
 	if err != nil { return err }
@@ -238,8 +503,16 @@ func (vm *VM) executeMinusOperator() error {
 	header, _ := memory.ReadHeader(operand)
 	if header.Type == memory.TagInteger {
 		val, _ := memory.ReadInteger(operand)
+		if val == math.MinInt64 { return vm.pushBigInt(new(big.Int).Neg(big.NewInt(val))) }
 		ptr, _ := memory.AllocInteger(-val)
 		return vm.push(ptr)
+	} else if header.Type == memory.TagBigInt {
+		return vm.pushBigInt(new(big.Int).Neg(toBigInt(operand)))
+	} else if header.Type == memory.TagDecimal {
+		val, _ := toDecimal(operand)
+		ptr, err := memory.AllocDecimal(val.Unscaled.Neg(val.Unscaled), int32(val.Scale))
+		if err != nil { return err }
+		return vm.push(ptr)
 	} else if header.Type == memory.TagFloat {
 		val, _ := memory.ReadFloat(operand)
 		ptr, _ := memory.AllocFloat(-val)
@@ -262,6 +535,9 @@ func (vm *VM) executeBitNotOperator() error {
 		ptr, _ := memory.AllocInteger(^val)
 		return vm.push(ptr)
 	}
+	if header.Type == memory.TagBigInt {
+		return vm.pushBigInt(new(big.Int).Not(toBigInt(operand)))
+	}
 	return vm.pushRuntimeError(fmt.Sprintf("bitnot not supported for type tag %d", header.Type))
 }
 
@@ -285,7 +561,10 @@ func (vm *VM) buildHash(startIndex, endIndex int) (memory.Ptr, error) {
 	for i := 0; i < count; i++ {
 		key := vm.stack[startIndex + i*2]
 		val := vm.stack[startIndex + i*2 + 1]
-		memory.WriteHashPair(ptr, i, key, val)
+		if err := memory.HashSet(ptr, key, val); err != nil {
+			if err == memory.ErrUnhashableKey { return vm.newError(hashKeyError(key)) }
+			return memory.NilPtr, err
+		}
 	}
 	return ptr, nil
 }
@@ -299,72 +578,97 @@ func (vm *VM) executeIndexExpression(left, index memory.Ptr) error {
 	if err != nil { return err }
 
 	if header.Type == memory.TagArray {
+		// A BigInt index is always out of range
+		if isBigInt(index) { return vm.push(NullPtr) }
 		idx, err := memory.ReadInteger(index)
 		if err != nil { return vm.pushRuntimeError("index must be integer") }
 
+		// Negative indexes count from the end
+		if idx < 0 {
+			length, err := memory.ReadArrayLength(left)
+			if err != nil { return err }
+			idx += int64(length)
+		}
+		if idx < 0 { return vm.push(NullPtr) }
 		elemPtr, err := memory.ReadArrayElement(left, int(idx))
 		if err != nil { return vm.push(NullPtr) }
 		return vm.push(elemPtr)
 	}
 
 	if header.Type == memory.TagHash {
-		count, _ := memory.ReadHashCount(left)
-		for i := 0; i < count; i++ {
-			k, v, _ := memory.ReadHashPair(left, i)
-			if equals(k, index) {
-				return vm.push(v)
-			}
-		}
-		return vm.push(NullPtr)
+		v, found, err := memory.HashGet(left, index)
+		if err == memory.ErrUnhashableKey { return vm.pushRuntimeError(hashKeyError(index)) }
+		if err != nil { return err }
+		if !found { return vm.push(NullPtr) }
+		return vm.push(v)
 	}
 
 	if header.Type == memory.TagString {
+		if isBigInt(index) { return vm.push(NullPtr) }
 		idx, err := memory.ReadInteger(index)
 		if err != nil { return vm.pushRuntimeError("string index must be integer") }
 
 		str, err := memory.ReadString(left)
 		if err != nil { return err }
 
-		if idx < 0 || int(idx) >= len(str) {
+		// Strings index by code point, not byte; negative indexes count from the end
+		if idx < 0 { idx += int64(utf8.RuneCountInString(str)) }
+		charStr, ok := runeAt(str, idx)
+		if !ok {
 			return vm.push(NullPtr)
 		}
 
-		charStr := string(str[idx])
 		ptr, err := memory.AllocString(charStr)
 		if err != nil { return err }
 		return vm.push(ptr)
 	}
 
-	if header.Type == memory.TagStruct {
-		schemaPtr, err := memory.ReadStructSchema(left)
+	if header.Type == memory.TagRange {
+		if isBigInt(index) { return vm.push(NullPtr) }
+		idxHeader, err := memory.ReadHeader(index)
 		if err != nil { return err }
+		if idxHeader.Type != memory.TagInteger { return vm.pushRuntimeError("range index must be integer") }
+		idx, _ := memory.ReadInteger(index)
 
-		_, fieldsArrPtr, err := memory.ReadSchema(schemaPtr)
-		if err != nil { return err }
+		r := &object.Range{Address: left}
+		start, _, _ := r.Bounds()
+		length := r.Len()
+		if idx < 0 { idx += length }
+		if idx < 0 || idx >= length { return vm.push(NullPtr) }
 
-		length, _ := memory.ReadArrayLength(fieldsArrPtr)
+		ptr, err := memory.AllocInteger(start + idx)
+		if err != nil { return err }
+		return vm.push(ptr)
+	}
 
+	if header.Type == memory.TagStruct || header.Type == memory.TagVariant {
 		// Expect index to be String
 		targetKey, err := memory.ReadString(index)
 		if err != nil { return vm.pushRuntimeError("struct key must be string") }
 
-		for i := 0; i < length; i++ {
-			fieldPtr, _ := memory.ReadArrayElement(fieldsArrPtr, i)
-			fieldName, _ := memory.ReadString(fieldPtr)
-			if fieldName == targetKey {
-				valPtr, err := memory.ReadStructField(left, i)
-				if err != nil { return err }
-				return vm.push(valPtr)
-			}
+		i, err := structFieldIndex(left, targetKey)
+		if err != nil { return err }
+		if i < 0 && header.Type == memory.TagStruct {
+			// Methods only exist as calls, see executeMethodCall
+			method, _, err := structMember(left, index)
+			if err != nil { return err }
+			if method != memory.NilPtr { return vm.pushRuntimeError(fmt.Sprintf("method %s must be called", targetKey)) }
 		}
-		return vm.push(NullPtr)
+		if i < 0 { return vm.push(NullPtr) }
+
+		valPtr, err := memory.ReadStructField(left, i)
+		if err != nil { return err }
+		return vm.push(valPtr)
 	}
 
 	return vm.pushRuntimeError(fmt.Sprintf("index not supported for type tag %d", header.Type))
 }
 
+// executeSetIndexExpression writes val into an array element, hash entry or
+// struct field and pushes kosong, or an Error when the write is not possible.
+// An Error val is stored like any other value.
 func (vm *VM) executeSetIndexExpression(left, index, val memory.Ptr) error {
-	if handled, err := vm.checkAndPropagateError(left, index, val); handled || err != nil {
+	if handled, err := vm.checkAndPropagateError(left, index); handled || err != nil {
 		return err
 	}
 
@@ -374,30 +678,410 @@ func (vm *VM) executeSetIndexExpression(left, index, val memory.Ptr) error {
 		idx, err := memory.ReadInteger(index)
 		if err != nil { return vm.pushRuntimeError("array index must be integer") }
 
+		if idx < 0 {
+			length, err := memory.ReadArrayLength(left)
+			if err != nil { return err }
+			idx += int64(length)
+		}
 		err = memory.WriteArrayElement(left, int(idx), val)
 		if err != nil { return err }
 		return vm.push(NullPtr)
 	}
 
 	if header.Type == memory.TagHash {
-		count, _ := memory.ReadHashCount(left)
-		// Update existing
-		for i := 0; i < count; i++ {
-			k, _, _ := memory.ReadHashPair(left, i)
-			if equals(k, index) {
-				memory.WriteHashPair(left, i, k, val)
-				return vm.push(NullPtr)
-			}
+		err := memory.HashSet(left, index, val)
+		if err == memory.ErrUnhashableKey { return vm.pushRuntimeError(hashKeyError(index)) }
+		if err == memory.ErrReadOnlyHash { return vm.pushRuntimeError("the variants of a pilihan cannot be assigned") }
+		if err == memory.ErrReadOnlyKey { return vm.pushRuntimeError(constantKeyError(index)) }
+		if err != nil { return err }
+		return vm.push(NullPtr)
+	}
+
+	if header.Type == memory.TagVariant {
+		return vm.pushRuntimeError("fields of a pilihan variant cannot be assigned")
+	}
+
+	if header.Type == memory.TagStruct {
+		keyHeader, err := memory.ReadHeader(index)
+		if err != nil { return err }
+		if keyHeader.Type != memory.TagString { return vm.pushRuntimeError("struct key must be string") }
+		name, err := memory.ReadString(index)
+		if err != nil { return err }
+
+		i, err := structFieldIndex(left, name)
+		if err != nil { return err }
+		if i < 0 {
+			schemaPtr, err := memory.ReadStructSchema(left)
+			if err != nil { return err }
+			schema := &object.Schema{Address: schemaPtr}
+			return vm.pushRuntimeError(fmt.Sprintf("struktur %s has no field '%s'", schema.Name(), name))
 		}
-		// Append (Dynamic) - Not supported in fixed Hash
-		return vm.pushRuntimeError("hash update key not found (dynamic hash unsupported)")
+
+		if err := memory.WriteStructField(left, i, val); err != nil { return err }
+		return vm.push(NullPtr)
 	}
 
 	return vm.pushRuntimeError("set index not supported")
 }
 
+// executeSliceExpression pushes left[start:end] as a new array, string or
+// range. Bounds may be kosong (open), negative (counted from the end) or out
+// of range (clamped), as in Python.
+func (vm *VM) executeSliceExpression(left, start, end memory.Ptr) error {
+	if handled, err := vm.checkAndPropagateError(left, start, end); handled || err != nil {
+		return err
+	}
+
+	header, err := memory.ReadHeader(left)
+	if err != nil { return err }
+
+	var length int64
+	switch header.Type {
+	case memory.TagArray:
+		n, err := memory.ReadArrayLength(left)
+		if err != nil { return err }
+		length = int64(n)
+	case memory.TagString:
+		str, err := memory.ReadString(left)
+		if err != nil { return err }
+		length = int64(utf8.RuneCountInString(str))
+	case memory.TagRange:
+		length = (&object.Range{Address: left}).Len()
+	default:
+		got, err := typeNameOf(left)
+		if err != nil { return err }
+		return vm.pushTypeMismatch(fmt.Sprintf("type mismatch: cannot slice %s", got))
+	}
+
+	from, ok := sliceBound(start, 0, length)
+	if !ok { return vm.pushSliceBoundMismatch(start) }
+	to, ok := sliceBound(end, length, length)
+	if !ok { return vm.pushSliceBoundMismatch(end) }
+	if to < from { to = from }
+
+	switch header.Type {
+	case memory.TagArray:
+		ptr, err := memory.AllocArray(int(to-from), int(to-from))
+		if err != nil { return err }
+		for i := from; i < to; i++ {
+			elem, err := memory.ReadArrayElement(left, int(i))
+			if err != nil { return err }
+			if err := memory.WriteArrayElement(ptr, int(i-from), elem); err != nil { return err }
+		}
+		return vm.push(ptr)
+
+	case memory.TagString:
+		str, _ := memory.ReadString(left)
+		// Walk code points to byte offsets so invalid bytes are copied unchanged
+		lo, hi, i := len(str), len(str), int64(0)
+		for offset := range str {
+			if i == from { lo = offset }
+			if i == to { hi = offset; break }
+			i++
+		}
+		ptr, err := memory.AllocString(str[lo:hi])
+		if err != nil { return err }
+		return vm.push(ptr)
+
+	default:
+		first, _, _ := (&object.Range{Address: left}).Bounds()
+		ptr, err := memory.AllocRange(first+from, first+to, true)
+		if err != nil { return err }
+		return vm.push(ptr)
+	}
+}
+
+// sliceBound resolves a slice bound against length: kosong gives def,
+// negatives count from the end, and the result is clamped to [0, length].
+// ok is false when the bound is not an integer.
+func sliceBound(bound memory.Ptr, def, length int64) (int64, bool) {
+	header, err := memory.ReadHeader(bound)
+	if err != nil { return 0, false }
+
+	var idx int64
+	switch header.Type {
+	case memory.TagNull:
+		return def, true
+	case memory.TagInteger:
+		idx, _ = memory.ReadInteger(bound)
+	case memory.TagBigInt:
+		// Beyond either end
+		if toBigInt(bound).Sign() < 0 { return 0, true }
+		return length, true
+	default:
+		return 0, false
+	}
+
+	if idx < 0 { idx += length }
+	if idx < 0 { return 0, true }
+	if idx > length { return length, true }
+	return idx, true
+}
+
+func (vm *VM) pushSliceBoundMismatch(bound memory.Ptr) error {
+	got, err := typeNameOf(bound)
+	if err != nil { return err }
+	return vm.pushTypeMismatch(fmt.Sprintf("type mismatch: slice bounds must be integer, got %s", got))
+}
+
+// executeRange pushes the range start..end, or start..<end when exclusive.
+func (vm *VM) executeRange(start, end memory.Ptr, exclusive bool) error {
+	if handled, err := vm.checkAndPropagateError(start, end); handled || err != nil {
+		return err
+	}
+
+	for _, bound := range []memory.Ptr{start, end} {
+		header, err := memory.ReadHeader(bound)
+		if err != nil { return err }
+		if header.Type != memory.TagInteger {
+			got, err := typeNameOf(bound)
+			if err != nil { return err }
+			return vm.pushTypeMismatch(fmt.Sprintf("type mismatch: range bounds must be integer, got %s", got))
+		}
+	}
+
+	first, _ := memory.ReadInteger(start)
+	last, _ := memory.ReadInteger(end)
+	if _, ok := object.RangeLen(first, last, exclusive); !ok {
+		return vm.pushRuntimeError("range too large")
+	}
+
+	ptr, err := memory.AllocRange(first, last, exclusive)
+	if err != nil { return err }
+	return vm.push(ptr)
+}
+
+// executeIter pushes the initial `untuk` loop value followed by an iterator.
+// Non-iterable values push the Error twice: it becomes the loop result and
+// OpIterNext stops immediately on it.
+func (vm *VM) executeIter(collection memory.Ptr) error {
+	header, err := memory.ReadHeader(collection)
+	if err != nil { return err }
+
+	iterable := false
+	switch header.Type {
+	case memory.TagArray, memory.TagHash, memory.TagString, memory.TagRange:
+		iterable = true
+	case memory.TagResource:
+		_, iterable = object.GetResource(collection).(*object.Channel)
+	}
+
+	if header.Type == memory.TagError {
+		if err := vm.push(collection); err != nil { return err }
+		return vm.push(collection)
+	}
+
+	if !iterable {
+		name, err := typeNameOf(collection)
+		if err != nil { return err }
+		errPtr, err := vm.newError(fmt.Sprintf("cannot iterate over %s", name))
+		if err != nil { return err }
+		if err := vm.push(errPtr); err != nil { return err }
+		return vm.push(errPtr)
+	}
+
+	iter, err := memory.AllocIterator(collection)
+	if err != nil { return err }
+	if err := vm.push(NullPtr); err != nil { return err }
+	return vm.push(iter)
+}
+
+// executeIterNext advances an iterator. With one loop variable it yields the
+// element (array, channel), character (string) or key (hash); with two it
+// yields index/key first, then the value.
+func (vm *VM) executeIterNext(iter memory.Ptr, numVars int) error {
+	header, err := memory.ReadHeader(iter)
+	if err != nil { return err }
+	if header.Type != memory.TagIterator {
+		return vm.push(FalsePtr)
+	}
+
+	collection, cursor, index, err := memory.ReadIterator(iter)
+	if err != nil { return err }
+
+	collHeader, err := memory.ReadHeader(collection)
+	if err != nil { return err }
+
+	var key, val memory.Ptr
+	next := cursor + 1
+
+	switch collHeader.Type {
+	case memory.TagArray:
+		length, err := memory.ReadArrayLength(collection)
+		if err != nil { return err }
+		if int(cursor) >= length { return vm.push(FalsePtr) }
+
+		val, err = memory.ReadArrayElement(collection, int(cursor))
+		if err != nil { return err }
+
+	case memory.TagRange:
+		r := &object.Range{Address: collection}
+		if cursor >= r.Len() { return vm.push(FalsePtr) }
+
+		first, _, _ := r.Bounds()
+		val, err = memory.AllocInteger(first + cursor)
+		if err != nil { return err }
+
+	case memory.TagHash:
+		k, v, n, err := memory.HashNext(collection, int(cursor))
+		if err != nil { return err }
+		if n < 0 { return vm.push(FalsePtr) }
+
+		if numVars == 1 {
+			val = k
+		} else {
+			key, val = k, v
+		}
+		next = int64(n)
+
+	case memory.TagString:
+		// The cursor is a byte offset; each step yields one code point
+		str, err := memory.ReadString(collection)
+		if err != nil { return err }
+		if int(cursor) >= len(str) { return vm.push(FalsePtr) }
+
+		_, size := utf8.DecodeRuneInString(str[cursor:])
+		val, err = memory.AllocString(str[cursor : int(cursor)+size])
+		if err != nil { return err }
+		next = cursor + int64(size)
+
+	case memory.TagResource:
+		ch, ok := object.GetResource(collection).(*object.Channel)
+		if !ok { return vm.push(FalsePtr) }
+
+		obj, open := <-ch.Value
+		if !open { return vm.push(FalsePtr) }
+		if err := ensureOnHeap(obj); err != nil { return err }
+		val = getObjectAddress(obj)
+
+	default:
+		return vm.push(FalsePtr)
+	}
+
+	if numVars == 2 && key == memory.NilPtr {
+		key, err = memory.AllocInteger(index)
+		if err != nil { return err }
+	}
+
+	if err := memory.WriteIteratorCursor(iter, next, index+1); err != nil { return err }
+
+	if numVars == 2 {
+		if err := vm.push(key); err != nil { return err }
+	}
+	if err := vm.push(val); err != nil { return err }
+	return vm.push(TruePtr)
+}
+
 // Helpers
 
+// runeAt returns the code point at rune index idx of str.
+func runeAt(str string, idx int64) (string, bool) {
+	if idx < 0 {
+		return "", false
+	}
+	for i := range str {
+		if idx == 0 {
+			_, size := utf8.DecodeRuneInString(str[i:])
+			return str[i : i+size], true
+		}
+		idx--
+	}
+	return "", false
+}
+
+func hashKeyError(key memory.Ptr) string {
+	name, _ := typeNameOf(key)
+	return fmt.Sprintf("unusable as hash key: %s", name)
+}
+
+// constantKeyError names the module constant a write was refused for.
+func constantKeyError(key memory.Ptr) string {
+	name, err := memory.ReadString(key)
+	if err != nil {
+		return "cannot assign to a constant"
+	}
+	return fmt.Sprintf("cannot assign to constant %s", name)
+}
+
+// structFieldIndex returns the position of a named field in a struct
+// instance, or -1 when its schema has no such field.
+func structFieldIndex(structPtr memory.Ptr, name string) (int, error) {
+	schemaPtr, err := memory.ReadStructSchema(structPtr)
+	if err != nil { return -1, err }
+
+	_, fieldsArrPtr, err := memory.ReadSchema(schemaPtr)
+	if err != nil { return -1, err }
+
+	length, _ := memory.ReadArrayLength(fieldsArrPtr)
+	for i := 0; i < length; i++ {
+		fieldPtr, _ := memory.ReadArrayElement(fieldsArrPtr, i)
+		fieldName, _ := memory.ReadString(fieldPtr)
+		if fieldName == name {
+			return i, nil
+		}
+	}
+	return -1, nil
+}
+
+// executeMatchSchema pushes whether val is an instance of the schema called name.
+func (vm *VM) executeMatchSchema(val memory.Ptr, name string) error {
+	header, err := memory.ReadHeader(val)
+	if err != nil { return err }
+	if header.Type != memory.TagStruct { return vm.push(FalsePtr) }
+
+	schemaPtr, err := memory.ReadStructSchema(val)
+	if err != nil { return err }
+	namePtr, _, err := memory.ReadSchema(schemaPtr)
+	if err != nil { return err }
+	schemaName, err := memory.ReadString(namePtr)
+	if err != nil { return err }
+
+	return vm.push(nativeBoolToPtr(schemaName == name))
+}
+
+// executeMatchKey pushes whether a hash contains key or a struct has a field
+// named key. Other containers never match.
+func (vm *VM) executeMatchKey(container, key memory.Ptr) error {
+	header, err := memory.ReadHeader(container)
+	if err != nil { return err }
+
+	switch header.Type {
+	case memory.TagHash:
+		_, found, err := memory.HashGet(container, key)
+		if err == memory.ErrUnhashableKey { return vm.push(FalsePtr) }
+		if err != nil { return err }
+		return vm.push(nativeBoolToPtr(found))
+	case memory.TagStruct:
+		name, err := memory.ReadString(key)
+		if err != nil { return vm.push(FalsePtr) }
+		i, err := structFieldIndex(container, name)
+		if err != nil { return err }
+		return vm.push(nativeBoolToPtr(i >= 0))
+	}
+	return vm.push(FalsePtr)
+}
+
+// executeArrayRest pushes a new array holding the elements of arr from start on.
+func (vm *VM) executeArrayRest(arr memory.Ptr, start int) error {
+	length, err := memory.ReadArrayLength(arr)
+	if err != nil { return err }
+	if start > length { start = length }
+
+	restPtr, err := memory.AllocArray(length-start, length-start)
+	if err != nil { return err }
+	for i := start; i < length; i++ {
+		elem, err := memory.ReadArrayElement(arr, i)
+		if err != nil { return err }
+		if err := memory.WriteArrayElement(restPtr, i-start, elem); err != nil { return err }
+	}
+	return vm.push(restPtr)
+}
+
+func nativeBoolToPtr(b bool) memory.Ptr {
+	if b { return TruePtr }
+	return FalsePtr
+}
+
 func isTruthy(ptr memory.Ptr) bool {
 	if ptr == memory.NilPtr { return false }
 	header, _ := memory.ReadHeader(ptr)
@@ -425,9 +1109,44 @@ func toFloat(ptr memory.Ptr) float64 {
 		val, _ := memory.ReadInteger(ptr)
 		return float64(val)
 	}
+	if header.Type == memory.TagBigInt {
+		return (&object.BigInt{Address: ptr}).Float()
+	}
 	return 0
 }
 
+// toBigInt reads an Integer or BigInt as a *big.Int.
+func toBigInt(ptr memory.Ptr) *big.Int {
+	header, _ := memory.ReadHeader(ptr)
+	if header.Type == memory.TagBigInt {
+		val, _ := memory.ReadBigInt(ptr)
+		return val
+	}
+	val, _ := memory.ReadInteger(ptr)
+	return big.NewInt(val)
+}
+
+// toDecimal reads a Decimal, or an integer as a decimal of scale 0.
+func toDecimal(ptr memory.Ptr) (object.DecimalValue, bool) {
+	header, _ := memory.ReadHeader(ptr)
+	if header.Type == memory.TagDecimal {
+		return (&object.Decimal{Address: ptr}).GetValue(), true
+	}
+	if isInteger(header.Type) {
+		return object.DecimalFromInt(toBigInt(ptr)), true
+	}
+	return object.DecimalValue{}, false
+}
+
+func isInteger(tag memory.TypeTag) bool {
+	return tag == memory.TagInteger || tag == memory.TagBigInt
+}
+
+func isBigInt(ptr memory.Ptr) bool {
+	header, _ := memory.ReadHeader(ptr)
+	return header.Type == memory.TagBigInt
+}
+
 func stringify(ptr memory.Ptr) string {
 	header, _ := memory.ReadHeader(ptr)
 	if header.Type == memory.TagString {
@@ -438,6 +1157,13 @@ func stringify(ptr memory.Ptr) string {
 		val, _ := memory.ReadInteger(ptr)
 		return fmt.Sprintf("%d", val)
 	}
+	if header.Type == memory.TagBigInt {
+		return toBigInt(ptr).String()
+	}
+	if header.Type == memory.TagDecimal {
+		val, _ := toDecimal(ptr)
+		return val.String()
+	}
 	if header.Type == memory.TagFloat {
 		val, _ := memory.ReadFloat(ptr)
 		return fmt.Sprintf("%g", val)
@@ -447,6 +1173,9 @@ func stringify(ptr memory.Ptr) string {
 		if val { return "benar" } else { return "salah" }
 	}
 	if header.Type == memory.TagNull { return "kosong" }
+	if header.Type == memory.TagRange {
+		return (&object.Range{Address: ptr}).Inspect()
+	}
 	if header.Type == memory.TagError {
 		msgPtr, _, _, _, _ := memory.ReadError(ptr)
 		msg, _ := memory.ReadString(msgPtr)
@@ -468,6 +1197,14 @@ func equals(p1, p2 memory.Ptr) bool {
 		v2, _ := memory.ReadInteger(p2)
 		return v1 == v2
 	}
+	if h1.Type == memory.TagBigInt {
+		return toBigInt(p1).Cmp(toBigInt(p2)) == 0
+	}
+	if h1.Type == memory.TagDecimal {
+		v1, _ := toDecimal(p1)
+		v2, _ := toDecimal(p2)
+		return v1.Cmp(v2) == 0
+	}
 	if h1.Type == memory.TagString {
 		v1, _ := memory.ReadString(p1)
 		v2, _ := memory.ReadString(p2)
@@ -479,5 +1216,110 @@ func equals(p1, p2 memory.Ptr) bool {
 		return v1 == v2
 	}
 	if h1.Type == memory.TagNull { return true }
+	if h1.Type == memory.TagRange {
+		s1, e1, x1, _ := memory.ReadRange(p1)
+		s2, e2, x2, _ := memory.ReadRange(p2)
+		return s1 == s2 && e1 == e2 && x1 == x2
+	}
+	if h1.Type == memory.TagVariant {
+		if schemaName(p1) != schemaName(p2) { return false }
+		for i := 0; i < (int(h1.Size)-memory.HeaderSize)/8-1; i++ {
+			f1, _ := memory.ReadStructField(p1, i)
+			f2, _ := memory.ReadStructField(p2, i)
+			if !equals(f1, f2) { return false }
+		}
+		return true
+	}
 	return false
 }
+
+// variantMatches compares a variant with another value for `==`. Two
+// variants are equal when they are the same variant with equal fields; a
+// variant constructor such as Status.Berjalan matches any value it made.
+func variantMatches(left, right memory.Ptr) bool {
+	lh, _ := memory.ReadHeader(left)
+	rh, _ := memory.ReadHeader(right)
+	switch {
+	case lh.Type == memory.TagVariant && rh.Type == memory.TagVariant:
+		return equals(left, right)
+	case lh.Type == memory.TagSchema:
+		return schemaName(right) == (&object.Schema{Address: left}).Name()
+	case rh.Type == memory.TagSchema:
+		return schemaName(left) == (&object.Schema{Address: right}).Name()
+	}
+	return false
+}
+
+// schemaName names the schema of a struct or variant instance.
+func schemaName(instance memory.Ptr) string {
+	schemaPtr, err := memory.ReadStructSchema(instance)
+	if err != nil { return "" }
+	return (&object.Schema{Address: schemaPtr}).Name()
+}
+
+// executeCheckType guards a typed parameter in strict mode. An argument of
+// none of the types (joined with "|") returns a type mismatch Error from the
+// current function instead of running its body.
+func (vm *VM) executeCheckType(localIndex int, types string) error {
+	frame := vm.currentFrame()
+	val := vm.stack[frame.basePointer+localIndex]
+	got, err := typeNameOf(val)
+	if err != nil { return err }
+
+	for _, t := range strings.Split(types, "|") {
+		if t == got || t == "any" { return nil }
+	}
+
+	param := fmt.Sprintf("%d", localIndex+1)
+	namesPtr, err := memory.ReadFunctionParamNames(frame.cl.Fn().Address)
+	if err == nil && namesPtr != memory.NilPtr {
+		if namePtr, err := memory.ReadArrayElement(namesPtr, localIndex); err == nil {
+			if name, err := memory.ReadString(namePtr); err == nil { param = name }
+		}
+	}
+
+	msg := fmt.Sprintf("type mismatch: argument %s must be %s, got %s", param, strings.ReplaceAll(types, "|", " | "), got)
+	msgPtr, err := memory.AllocString(msg)
+	if err != nil { return err }
+	codePtr, err := memory.AllocString(object.ErrCodeTypeMismatch)
+	if err != nil { return err }
+	errPtr, err := memory.AllocError(msgPtr, codePtr, 0, 0)
+	if err != nil { return err }
+	return vm.returnValue(errPtr)
+}
+
+// typeNameOf names the type of a value the way type annotations do.
+func typeNameOf(val memory.Ptr) (string, error) {
+	header, err := memory.ReadHeader(val)
+	if err != nil { return "", err }
+
+	switch header.Type {
+	case memory.TagInteger, memory.TagBigInt:
+		return "integer", nil
+	case memory.TagFloat:
+		return "float", nil
+	case memory.TagDecimal:
+		return "decimal", nil
+	case memory.TagString:
+		return "string", nil
+	case memory.TagBoolean:
+		return "boolean", nil
+	case memory.TagNull:
+		return "kosong", nil
+	case memory.TagArray:
+		return "array", nil
+	case memory.TagHash:
+		return "hash", nil
+	case memory.TagRange:
+		return "range", nil
+	case memory.TagError:
+		return "error", nil
+	case memory.TagClosure, memory.TagCompiledFunction, memory.TagBuiltin:
+		return "function", nil
+	case memory.TagStruct:
+		return schemaName(val), nil
+	case memory.TagVariant:
+		return (&object.Variant{Address: val}).Enum(), nil
+	}
+	return fmt.Sprintf("type %d", header.Type), nil
+}
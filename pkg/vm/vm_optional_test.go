@@ -0,0 +1,63 @@
+package vm
+
+import (
+	"testing"
+
+	"github.com/VzoelFox/morphlang/pkg/object"
+)
+
+func TestOptionalChaining(t *testing.T) {
+	tests := []vmTestCase{
+		{`cfg = {db: {host: "lokal"}}; cfg?.db?.host`, "lokal"},
+		{`cfg = {db: {host: "lokal"}}; cfg?.cache?.host`, nil},
+		{`cfg = kosong; cfg?.db.host`, nil},
+		{`cfg = kosong; cfg?.db[0][1:]`, nil},
+		{`cfg = kosong; cfg?.db.muat()`, nil},
+		// Only ?. short-circuits; a plain dot on kosong is still an error
+		{`cfg = {}; cfg?.db.host`, object.NewError("index not supported for type tag 5", "", 0, 0)},
+		// The chain ends at the parentheses of an argument
+		{`cfg = kosong; [cfg?.db, 1][1]`, 1},
+		{`
+struktur T
+  x
+  fungsi dua()
+    kembalikan ini.x * 2
+  akhir
+akhir
+t = T(x: 4)
+t?.dua()`, 8},
+		{`
+struktur T
+  x
+  fungsi dua()
+    kembalikan ini.x * 2
+  akhir
+akhir
+t = kosong
+t?.dua()`, nil},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestCoalescing(t *testing.T) {
+	tests := []vmTestCase{
+		{`kosong ?? 5`, 5},
+		{`0 ?? 5`, 0},
+		{`salah ?? benar`, false},
+		{`kosong ?? kosong ?? "c"`, "c"},
+		{`{}.port ?? 80`, 80},
+		{`cfg = {db: kosong}; cfg?.db?.port ?? 5432`, 5432},
+		{`galat("gagal") ?! "cadangan"`, "cadangan"},
+		{`3 ?! 4`, 3},
+		{`kosong ?! 4`, nil},
+		{`galat("gagal") ?? 1`, object.NewError("gagal", "", 0, 0)},
+		// The right side is only evaluated when needed
+		{`n = 0; fungsi f() n = 1; kembalikan 2 akhir; x = 1 ?? f(); n`, 0},
+		// Lower precedence than arithmetic and atau
+		{`x = kosong; x ?? 1 + 2`, 3},
+		{`x = kosong; x ?? salah atau benar`, true},
+	}
+
+	runVmTests(t, tests)
+}
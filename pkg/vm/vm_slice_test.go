@@ -0,0 +1,71 @@
+package vm
+
+import (
+	"testing"
+
+	"github.com/VzoelFox/morphlang/pkg/object"
+)
+
+func TestSliceExpressions(t *testing.T) {
+	tests := []vmTestCase{
+		{`[1, 2, 3, 4, 5][1:3]`, []int64{2, 3}},
+		{`[1, 2, 3, 4, 5][:-1]`, []int64{1, 2, 3, 4}},
+		{`[1, 2, 3, 4, 5][-2:]`, []int64{4, 5}},
+		{`[1, 2, 3][:]`, []int64{1, 2, 3}},
+		{`[1, 2, 3][-10:10]`, []int64{1, 2, 3}},
+		{`[1, 2, 3][2:1]`, []int64{}},
+		{`[1, 2, 3][2 ** 64:]`, []int64{}},
+		// The slice is a copy
+		{`a = [1, 2, 3]; b = a[:]; b[0] = 9; a[0]`, 1},
+		{`"morphlang"[5:]`, "lang"},
+		{`"morphlang"[:-4]`, "morph"},
+		{`"héllo"[1:3]`, "él"},
+		{`"日本語"[-1:]`, "語"},
+		{`"abc"[5:]`, ""},
+		{`[1, 2][1.5:]`, object.NewError("type mismatch: slice bounds must be integer, got float", "", 0, 0)},
+		{`5[1:]`, object.NewError("type mismatch: cannot slice integer", "", 0, 0)},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestNegativeIndexing(t *testing.T) {
+	tests := []vmTestCase{
+		{`[1, 2, 3][-1]`, 3},
+		{`[1, 2, 3][-3]`, 1},
+		{`[1, 2, 3][-4]`, nil},
+		{`a = [1, 2, 3]; a[-1] = 30; a`, []int64{1, 2, 30}},
+		{`"abc"[-1]`, "c"},
+		{`"héllo"[-4]`, "é"},
+		{`"abc"[-4]`, nil},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestRanges(t *testing.T) {
+	tests := []vmTestCase{
+		{`panjang(1..10)`, 10},
+		{`panjang(1..<10)`, 9},
+		{`panjang(5..1)`, 0},
+		{`(1..10)[0]`, 1},
+		{`(1..10)[-1]`, 10},
+		{`(1..<10)[-1]`, 9},
+		{`(1..10)[10]`, nil},
+		{`ke_array(1..<4)`, []int64{1, 2, 3}},
+		{`ke_array(-2..0)`, []int64{-2, -1, 0}},
+		{`ke_array((0..10)[2:5])`, []int64{2, 3, 4}},
+		{`ke_array("héh")`, []string{"h", "é", "h"}},
+		{`s = 0; untuk i dalam 1..100 s += i akhir; s`, 5050},
+		{`s = 0; untuk i, x dalam 10..<13 s += i * x akhir; s`, 0*10 + 1*11 + 2*12},
+		{`n = 0; untuk i dalam 3..1 n += 1 akhir; n`, 0},
+		{`1..3 == 1..3`, true},
+		{`1..3 == 1..<3`, false},
+		{`tipe(1..2)`, "RANGE"},
+		{`1.5..2`, object.NewError("type mismatch: range bounds must be integer, got float", "", 0, 0)},
+		{`panjang(ke_array(1..8189))`, 8189},
+		{`ke_array(0..10000)`, object.NewError("range 0..10000 has 10001 elements, more than an array can hold (8189)", "", 0, 0)},
+	}
+
+	runVmTests(t, tests)
+}
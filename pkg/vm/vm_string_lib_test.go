@@ -29,6 +29,34 @@ func TestStringBuiltins(t *testing.T) {
 	runVmTests(t, tests)
 }
 
+func TestUnicodeStrings(t *testing.T) {
+	tests := []vmTestCase{
+		{input: `panjang("héllo")`, expected: 5},
+		{input: `panjang_byte("héllo")`, expected: 6},
+		{input: `"héllo"[1]`, expected: "é"},
+		{input: `"héllo"[4]`, expected: "o"},
+		{input: `"héllo"[5]`, expected: nil},
+		{input: `huruf_besar("wörld")`, expected: "WÖRLD"},
+		{input: `pisah("a→b→c", "→")`, expected: []string{"a", "b", "c"}},
+		{input: `ord("€")`, expected: 8364},
+		{input: `chr(8364)`, expected: "€"},
+		{input: `ke_byte("é")`, expected: []int64{195, 169}},
+		{input: `dari_byte([195, 169])`, expected: "é"},
+		{input: `dari_byte(ke_byte("añb")) == "añb"`, expected: true},
+		{
+			input: `
+			hasil = "."
+			untuk h dalam "ñé"
+			  hasil = h + hasil
+			akhir
+			hasil`,
+			expected: "éñ.",
+		},
+	}
+
+	runVmTests(t, tests)
+}
+
 func TestStringBuiltinsErrors(t *testing.T) {
 	tests := []vmTestCase{
 		{
@@ -39,6 +67,14 @@ func TestStringBuiltinsErrors(t *testing.T) {
 			input: `gabung(["a", 1], "-")`,
 			expected: object.NewError("array elements must be STRING, got INTEGER", "", 0, 0),
 		},
+		{
+			input: `chr(-1)`,
+			expected: object.NewError("invalid code point -1", "", 0, 0),
+		},
+		{
+			input: `dari_byte([300])`,
+			expected: object.NewError("element 0 of `dari_byte` must be an INTEGER from 0 to 255", "", 0, 0),
+		},
 	}
 
 	runVmTests(t, tests)
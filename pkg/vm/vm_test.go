@@ -1,6 +1,10 @@
 package vm
 
 import (
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -173,7 +177,8 @@ func TestIndexExpressions(t *testing.T) {
 		{"[[1, 1, 1]][0][0]", 1},
 		{"[][0]", nil},
 		{"[1, 2, 3][99]", nil},
-		{"[1][-1]", nil},
+		{"[1][-1]", 1},
+		{"[1][-2]", nil},
 		{"{1: 1, 2: 2}[1]", 1},
 		{"{1: 1, 2: 2}[2]", 2},
 		{"{1: 1}[99]", nil},
@@ -183,6 +188,27 @@ func TestIndexExpressions(t *testing.T) {
 	runVmTests(t, tests)
 }
 
+func TestHashMutation(t *testing.T) {
+	tests := []vmTestCase{
+		{`h = {"a": 1}; h["a"] = 5; h["a"]`, 5},
+		{`h = {}; h["baru"] = 7; h["baru"]`, 7},
+		{`h = {}; i = 0; selama (i < 100) h[i] = i * 2; i = i + 1; akhir; h[99]`, 198},
+		{`h = {}; i = 0; selama (i < 100) h[i] = i; i = i + 1; akhir; panjang(h)`, 100},
+		// Index assignments leave nothing behind on the stack
+		{`h = {"n": 0}; i = 0; selama (i < 5000) h["n"] = i; i = i + 1; akhir; h["n"]`, 4999},
+		{`h = {1: 1, 2: 2}; hapus_kunci(h, 1); h[1]`, nil},
+		{`h = {1: 1, 2: 2}; hapus_kunci(h, 1); panjang(h)`, 1},
+		{`h = {1: 1}; hapus_kunci(h, 5)`, false},
+		{`h = {"b": 1}; h["a"] = 2; h["c"] = 3; kunci(h)`, []string{"b", "a", "c"}},
+		{`h = {}; h[[1]]`, object.NewError("unusable as hash key: array", "", 0, 0)},
+		{`h = {}; h[{}] = 1`, object.NewError("unusable as hash key: hash", "", 0, 0)},
+		// Large hashes grow past a single tray
+		{`h = {}; i = 0; selama (i < 3000) h[i] = i * 2; i = i + 1; akhir; [panjang(h), h[0], h[2999]]`, []interface{}{3000, 0, 5998}},
+	}
+
+	runVmTests(t, tests)
+}
+
 func TestWhileLoops(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -197,6 +223,147 @@ func TestWhileLoops(t *testing.T) {
 	runVmTests(t, tests)
 }
 
+func TestForInLoops(t *testing.T) {
+	tests := []vmTestCase{
+		{`s = 0; untuk x dalam [1, 2, 3] s = s + x akhir; s`, 6},
+		{`s = 0; untuk i, x dalam [10, 20] s = s + i * x akhir; s`, 20},
+		{`s = 0; untuk k dalam {1: 10, 2: 20} s = s + k akhir; s`, 3},
+		{`s = 0; untuk k, v dalam {1: 10, 2: 20} s = s + v akhir; s`, 30},
+		{`s = "-"; untuk c dalam "abc" s = c + s akhir; s`, "cba-"},
+		{`s = 0; untuk x dalam [1, 2, 3, 4] jika x == 3 berhenti akhir; s = s + x akhir; s`, 3},
+		{`s = 0; untuk x dalam [1, 2, 3, 4] jika x == 2 lanjut akhir; s = s + x akhir; s`, 8},
+		{`untuk x dalam [] x akhir`, nil},
+		{`untuk x dalam [1, 2] x akhir`, 2},
+		{`s = 0; untuk a dalam [[1, 2], [3]] untuk b dalam a s = s + b akhir akhir; s`, 6},
+		{`fungsi jumlah(xs) t = 0; untuk x dalam xs t = t + x akhir; t akhir; jumlah([4, 5])`, 9},
+		{`untuk x dalam 5 x akhir`, object.NewError("cannot iterate over integer", "RUNTIME_ERROR", 0, 0)},
+		{`fungsi f() untuk x dalam 5 x akhir; "tidak sampai" akhir; f()`, object.NewError("cannot iterate over integer", "RUNTIME_ERROR", 0, 0)},
+		{`fungsi f() untuk x dalam galat("g") x akhir; "tidak sampai" akhir; pesan_galat(f())`, "g"},
+		{`
+		ch = saluran_baru(3)
+		kirim(ch, 1); kirim(ch, 2); kirim(ch, 3)
+		tutup_saluran(ch)
+		s = 0
+		untuk x dalam ch s = s + x akhir
+		s
+		`, 6},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestMatchExpression(t *testing.T) {
+	tests := []vmTestCase{
+		{`cocokkan 0 kasus 0 "nol" kasus _ "lain" akhir`, "nol"},
+		{`cocokkan 5 kasus 0 "nol" kasus _ "lain" akhir`, "lain"},
+		{`cocokkan -3 kasus -3 "min" lainnya "lain" akhir`, "min"},
+		{`cocokkan "a" kasus 1 "satu" kasus "a" "huruf" akhir`, "huruf"},
+		{`cocokkan 1.5 kasus 1.5 benar akhir`, true},
+		{`cocokkan kosong kasus kosong 1 kasus _ 2 akhir`, 1},
+		{`cocokkan salah kasus benar 1 kasus salah 2 akhir`, 2},
+		{`cocokkan 7 kasus 1 "satu" akhir`, nil},
+		{`cocokkan 7 kasus n n * 2 akhir`, 14},
+		{`cocokkan [1, 2, 3] kasus [a, b] 0 kasus [a, b, c] a + b + c akhir`, 6},
+		{`cocokkan [1, 2, 3, 4] kasus [a, ...sisa] sisa akhir`, []int64{2, 3, 4}},
+		{`cocokkan [1] kasus [a, b, ...sisa] 0 kasus [a, ...] a akhir`, 1},
+		{`cocokkan [1, [2, 3]] kasus [1, [x, y]] x * y akhir`, 6},
+		{`cocokkan {"nama": "Budi", "umur": 20} kasus {nama: n, umur} jika umur > 17 n kasus _ "anak" akhir`, "Budi"},
+		{`cocokkan {"nama": "Ani", "umur": 9} kasus {nama: n, umur} jika umur > 17 n kasus _ "anak" akhir`, "anak"},
+		{`cocokkan {"x": 1} kasus {y} y kasus {x: 1} "satu" akhir`, "satu"},
+		{`cocokkan {1: "a"} kasus {1: v} v akhir`, "a"},
+		{`
+		struktur Titik
+		  x
+		  y
+		akhir
+		p = Titik(0, 5)
+		cocokkan p
+		  kasus Titik{x: 1, y} "bukan"
+		  kasus Titik{x: 0, y} y
+		akhir
+		`, 5},
+		{`
+		struktur A
+		  v
+		akhir
+		struktur B
+		  v
+		akhir
+		cocokkan B(3) kasus A{v} 0 kasus B{v} v akhir
+		`, 3},
+		{`cocokkan galat("gagal") kasus galat(pesan) pesan kasus _ "ok" akhir`, "gagal"},
+		{`cocokkan galat("gagal") kasus galat benar akhir`, true},
+		{`cocokkan galat(5) kasus galat(_, kode) kode akhir`, "E003"},
+		{`cocokkan galat("x") kasus galat("y") 1 kasus galat("x") 2 akhir`, 2},
+		{`cocokkan 10 kasus galat(p) p kasus n n akhir`, 10},
+		{`fungsi f(x) cocokkan x kasus [h, ...t] h + f(t) kasus [] 0 akhir akhir; f([1, 2, 3])`, 6},
+		{`x = 4; y = cocokkan x kasus 4 x * 10 akhir; y + 1`, 41},
+		// A failed arm leaves the variables it would bind alone
+		{`x = 5; cocokkan [1, 2] kasus [x, 3] 0 kasus _ 1 akhir; x`, 5},
+		{`x = 5; cocokkan 1 kasus x jika x > 1 0 kasus _ 1 akhir; x`, 5},
+		{`fungsi f() x = 5; cocokkan [1, 2] kasus [x, 3] 0 kasus [_, x] x + 1 akhir; x akhir; f()`, 2},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestTryExpression(t *testing.T) {
+	tests := []vmTestCase{
+		{`fungsi f() coba 5 akhir; f()`, 5},
+		{`fungsi f() x = coba 5; x + 1 akhir; f()`, 6},
+		{`fungsi f() x = coba galat("gagal"); x + 1 akhir; f()`, object.NewError("gagal", "", 0, 0)},
+		{`
+		fungsi bagi(a, b)
+		  jika b == 0 kembalikan galat("bagi nol") akhir
+		  a / b
+		akhir
+		fungsi hitung(a, b)
+		  hasil = coba bagi(a, b)
+		  hasil * 2
+		akhir
+		hasil = [hitung(10, 2), pesan_galat(hitung(1, 0))]
+		hasil
+		`, []interface{}{10, "bagi nol"}},
+		{`fungsi f() untuk x dalam [1, galat("g"), 3] coba x akhir; "selesai" akhir; pesan_galat(f())`, "g"},
+		{`coba galat("atas"); 5`, object.NewError("atas", "", 0, 0)},
+		{`coba 1; 5`, 5},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestUncaughtGalat(t *testing.T) {
+	tests := []struct {
+		input   string
+		message string
+		code    string
+	}{
+		{`x = coba galat("boom"); 5`, "boom", ""},
+		{`x = coba (desimal("1") + 0.5); 5`, "type mismatch: DECIMAL and FLOAT cannot be mixed, convert with desimal()", object.ErrCodeTypeMismatch},
+		// A failed field write outside of a function
+		{"struktur T\n  x\nakhir\nt = T(x: 1)\nt.z = 5\n1", "struktur T has no field 'z'", "RUNTIME_ERROR"},
+		// A destructuring mismatch outside of a function
+		{`[a, b] = [1]; a`, "destructuring mismatch: want an array of 2 elements for [a, b]", ""},
+		{`{n} = galat("kosong"); n`, "kosong", ""},
+	}
+
+	for _, tt := range tests {
+		comp := compiler.New()
+		if err := comp.Compile(parse(tt.input)); err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+		err := New(comp.Bytecode()).Run()
+
+		var uncaught *UncaughtError
+		if !errors.As(err, &uncaught) {
+			t.Fatalf("%q: expected an UncaughtError, got %v", tt.input, err)
+		}
+		if uncaught.Message != tt.message || uncaught.Code != tt.code {
+			t.Errorf("%q: wrong galat. want %q [%s], got %q [%s]", tt.input, tt.message, tt.code, uncaught.Message, uncaught.Code)
+		}
+	}
+}
+
 func TestFunctions(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -217,6 +384,230 @@ func TestFunctions(t *testing.T) {
 	runVmTests(t, tests)
 }
 
+func TestDefaultAndVariadicParameters(t *testing.T) {
+	tests := []vmTestCase{
+		{`fungsi f(a, b = 10) a + b akhir; f(1)`, 11},
+		{`fungsi f(a, b = 10) a + b akhir; f(1, 2)`, 3},
+		{`fungsi f(a, b = a * 2) a + b akhir; f(5)`, 15},
+		{`fungsi f(a = 1, b = 2) [a, b] akhir; f()`, []interface{}{1, 2}},
+		{`fungsi f(a = 1, b = 2) [a, b] akhir; f(kosong)`, []interface{}{nil, 2}},
+		{`fungsi f(a, ...sisa) sisa akhir; f(1, 2, 3)`, []interface{}{2, 3}},
+		{`fungsi f(a, ...sisa) sisa akhir; f(1)`, []interface{}{}},
+		{`fungsi f(a, b = 5, ...sisa) [a, b, panjang(sisa)] akhir; f(1)`, []interface{}{1, 5, 0}},
+		{`fungsi f(a, b = 5, ...sisa) [a, b, panjang(sisa)] akhir; f(1, 2, 3, 4)`, []interface{}{1, 2, 2}},
+		{`fungsi f(a, b = 10) a akhir; f()`, object.NewError("arg mismatch: want 1 to 2, got 0", "", 0, 0)},
+		{`fungsi f(a, b = 10) a akhir; f(1, 2, 3)`, object.NewError("arg mismatch: want 1 to 2, got 3", "", 0, 0)},
+		{`fungsi f(a, ...sisa) a akhir; f()`, object.NewError("arg mismatch: want at least 1, got 0", "", 0, 0)},
+		{`fungsi f(a) a akhir; f(1, 2)`, object.NewError("arg mismatch: want 1, got 2", "", 0, 0)},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestNamedArguments(t *testing.T) {
+	tests := []vmTestCase{
+		{`struktur Titik
+ x
+ y
+akhir
+t = Titik(y: 2, x: 1); [t.x, t.y]`, []interface{}{1, 2}},
+		{`struktur Titik
+ x
+ y
+akhir
+t = Titik(1, y: 2); t.y`, 2},
+		{`fungsi f(a, batas = 10) a * batas akhir; f(2, batas: 5)`, 10},
+		{`fungsi f(a, batas = 10) a * batas akhir; f(a: 3)`, 30},
+		{`fungsi f(a, ...sisa) [a, panjang(sisa)] akhir; f(1, 2, 3, a: 4)`, object.NewError("argument 'a' given more than once", "", 0, 0)},
+		{`fungsi f(a, b) a - b akhir; f(b: 1, a: 5)`, 4},
+		{`fungsi f(a) a akhir; f(z: 1)`, object.NewError("unknown argument 'z'", "", 0, 0)},
+		{`fungsi f(a, b) a akhir; f(b: 1)`, object.NewError("missing argument 'a'", "", 0, 0)},
+		{`struktur Titik
+ x
+ y
+akhir
+Titik(x: 1, x: 2)`, object.NewError("argument 'x' given more than once", "", 0, 0)},
+		{`struktur Titik
+ x
+ y
+akhir
+Titik(x: 1)`, object.NewError("missing argument 'y'", "", 0, 0)},
+		{`panjang(x: [1])`, object.NewError("builtin functions do not accept named arguments", "", 0, 0)},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestStructMethods(t *testing.T) {
+	titik := `struktur Titik
+ x
+ y
+ fungsi jumlah() ini.x + ini.y akhir
+ fungsi geser(dx, dy = 0) Titik(ini.x + dx, ini.y + dy) akhir
+ fungsi skala(k) ini.geser(ini.x * (k - 1), dy: ini.y * (k - 1)) akhir
+akhir
+`
+	tests := []vmTestCase{
+		{titik + `Titik(1, 2).jumlah()`, 3},
+		{titik + `p = Titik(1, 2).geser(10); [p.x, p.y]`, []interface{}{11, 2}},
+		{titik + `Titik(1, 2).geser(dy: 5, dx: 1).jumlah()`, 9},
+		{titik + `Titik(1, 2).skala(3).jumlah()`, 9},
+		{titik + `Titik(1, 2).hilang()`, object.NewError("struktur Titik has no method 'hilang'", "", 0, 0)},
+		{titik + `Titik(1, 2).geser()`, object.NewError("arg mismatch: want 2 to 3, got 1", "", 0, 0)},
+		{titik + `fungsi f(p) m = p.jumlah; m() akhir; f(Titik(1, 2))`, object.NewError("method jumlah must be called", "", 0, 0)},
+		// A function stored in a field is called without a receiver
+		{`struktur Kotak
+ f
+akhir
+Kotak(fungsi(a) a * 2 akhir).f(4)`, 8},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestStructFieldAssignment(t *testing.T) {
+	titik := `struktur Titik
+ x
+ y
+akhir
+t = Titik(1, 2)
+`
+	tests := []vmTestCase{
+		{titik + `t.x = 5; [t.x, t.y]`, []interface{}{5, 2}},
+		{titik + `t["y"] = 7; t.y`, 7},
+		{titik + `fungsi geser(p) p.x = p.x + 10; p akhir; geser(t).x`, 11},
+		{titik + `fungsi f(p) p.z = 1; "tidak sampai" akhir; f(t)`, object.NewError("struktur Titik has no field 'z'", "", 0, 0)},
+		{titik + `fungsi f(p) p[1] = 1; "tidak sampai" akhir; f(t)`, object.NewError("struct key must be string", "", 0, 0)},
+		// Error values can be stored like any other value
+		{titik + `t.x = galat("gagal"); adalah_galat(t.x)`, true},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestCompoundAssignment(t *testing.T) {
+	tests := []vmTestCase{
+		{`x = 10; x += 5; x`, 15},
+		{`x = 10; x -= 3; x *= 2; x /= 7; x`, 2},
+		{`s = "ab"; s += "c"; s`, "abc"},
+		{`a = [1, 2]; a[1] += 10; a`, []interface{}{1, 12}},
+		{`h = {"n": 1}; h["n"] *= 4; h.n -= 1; h["n"]`, 3},
+		{`struktur T
+ n
+akhir
+t = T(2); t.n += 3; t.n`, 5},
+		// The container and key are evaluated once
+		{`a = [0, 0]; i = 0; fungsi k() i += 1; 1 akhir; a[k()] += 5; [a, i]`, []interface{}{[]interface{}{0, 5}, 1}},
+		{`fungsi f() x = 1; x += 1; x akhir; f()`, 2},
+		{`fungsi f() x = 1; g = fungsi() x += 1 akhir; g(); x akhir; f()`, 2},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestDestructuring(t *testing.T) {
+	tests := []vmTestCase{
+		{`[a, b] = [1, 2]; a * 10 + b`, 12},
+		{`[a, ...sisa] = [1, 2, 3]; sisa`, []interface{}{2, 3}},
+		{`[x, [y, _]] = [1, [2, 3]]; x + y`, 3},
+		{`{n, m: k} = {"n": 1, "m": 2}; n + k`, 3},
+		{`struktur P
+ x
+ y
+akhir
+{x, y} = P(3, 4); P{y: q} = P(5, 6); x + y + q`, 13},
+		{`fungsi f() [a, b] = [4, 5]; a + b akhir; f()`, 9},
+		{`fungsi f() [a, b] = [1]; "tidak sampai" akhir; f()`,
+			object.NewError("destructuring mismatch: want an array of 2 elements for [a, b]", "", 0, 0)},
+		{`fungsi f() {n} = [1]; "tidak sampai" akhir; f()`,
+			object.NewError("destructuring mismatch: want a hash or struct with the keys of {n: n}", "", 0, 0)},
+		// A galat on the right-hand side is returned unchanged
+		{`fungsi g() galat("gagal") akhir; fungsi f() [a] = g(); a akhir; pesan_galat(f())`, "gagal"},
+		{`n = 0; fungsi f() {n, m} = {"n": 1} akhir; f(); n`, 0},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestConstants(t *testing.T) {
+	tests := []vmTestCase{
+		{`tetap BATAS = 10; BATAS * 2`, 20},
+		{`tetap SAPA = "halo, " + "dunia"; SAPA`, "halo, dunia"},
+		{`tetap PI = 3; fungsi luas(r) PI * r * r akhir; luas(2)`, 12},
+		{`tetap N = 2; f = fungsi() fungsi() N akhir akhir; f()()`, 2},
+		{`tetap DAFTAR = [1, 2]; DAFTAR[0] = 5; DAFTAR`, []interface{}{5, 2}},
+		{`fungsi f(n) tetap DUA = n * 2; DUA + 1 akhir; f(4)`, 9},
+		{`tetap MAKS = 9223372036854775807; MAKS`, 9223372036854775807},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestModuleConstantsAreReadOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "modul.fox")
+	if err := os.WriteFile(path, []byte("tetap BATAS = 10\nnilai = 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	use := `ambil "` + path + `"; `
+
+	// The compiler only sees writes through the module's own name; other
+	// names reach the same exports hash at runtime
+	tests := []vmTestCase{
+		{use + `m = modul; m["BATAS"] = 7`, object.NewError("cannot assign to constant BATAS", "", 0, 0)},
+		{use + `fungsi f(m) m.BATAS = 1 akhir; f(modul)`, object.NewError("cannot assign to constant BATAS", "", 0, 0)},
+		{use + `fungsi f(m) m.BATAS = 1 akhir; f(modul); modul.BATAS`, 10},
+		{use + `m = modul; hapus_kunci(m, "BATAS")`, object.NewError("a constant cannot be removed", object.ErrCodeRuntime, 0, 0)},
+		{use + `m = modul; m.nilai = 2; modul.nilai`, 2},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestStrictParameterGuards(t *testing.T) {
+	tests := []vmTestCase{
+		{`fungsi f(a: integer) a * 2 akhir; g = f; g(4)`, 8},
+		{`fungsi f(a: integer | galat) 1 akhir; g = f; g(galat("x"))`, 1},
+		{`fungsi f(a: integer) a akhir; g = f; g("4")`, object.NewError("type mismatch: argument a must be integer, got string", object.ErrCodeTypeMismatch, 0, 0)},
+		{`fungsi f(a: integer = "1") a akhir; g = f; g()`, object.NewError("type mismatch: argument a must be integer, got string", object.ErrCodeTypeMismatch, 0, 0)},
+		{"struktur T\n x\nakhir\nfungsi f(t: T) t.x akhir; g = f; g(T(1))", 1},
+		{`fungsi f(a, b: float) b akhir; g = f; g(1, 2)`, object.NewError("type mismatch: argument b must be float, got integer", object.ErrCodeTypeMismatch, 0, 0)},
+		{`fungsi f(a: desimal) a akhir; g = f; g(desimal("1.5")) == desimal("1.50")`, true},
+	}
+
+	runStrictVmTests(t, tests)
+}
+
+func TestEnums(t *testing.T) {
+	enum := "pilihan Status\n Menunggu\n Berjalan(pid)\n Selesai(kode, pesan)\nakhir\n"
+	tests := []vmTestCase{
+		{enum + "Status.Berjalan(7).pid", 7},
+		{enum + "Status.Selesai(pesan: \"ok\", kode: 0).pesan", "ok"},
+		{enum + "tipe(Status.Menunggu)", "Status"},
+		{enum + "tipe(Status.Berjalan(1))", "Status"},
+		{enum + "Status.Menunggu == Status.Menunggu", true},
+		{enum + "Status.Berjalan(1) == Status.Berjalan(1)", true},
+		{enum + "Status.Berjalan(1) == Status.Berjalan(2)", false},
+		{enum + "Status.Berjalan(1) != Status.Menunggu", true},
+		{enum + "Status.Berjalan(1) == Status.Berjalan", true},
+		{enum + "Status.Selesai == Status.Berjalan(1)", false},
+		{enum + "Status.Menunggu == \"Menunggu\"", false},
+		{enum + "x = Status.Berjalan(1); jika x == Status.Berjalan x.pid + 1 lainnya 0 akhir", 2},
+		{enum + "Status.Berjalan(1, 2)", object.NewError("struct init arg mismatch: want 1, got 2", "", 0, 0)},
+		{enum + "fungsi f() x = Status.Berjalan(1); x.pid = 2 akhir; f()", object.NewError("fields of a pilihan variant cannot be assigned", "", 0, 0)},
+		// The variant table is read-only even through another name
+		{enum + "fungsi f() s = Status; s.Baru = 7 akhir; f()", object.NewError("the variants of a pilihan cannot be assigned", "", 0, 0)},
+		{enum + "fungsi f() s = Status; s.Menunggu = 5 akhir; f(); panjang(Status)", 3},
+		{enum + "s = Status; hapus_kunci(s, \"Menunggu\")", object.NewError("the variants of a pilihan cannot be removed", object.ErrCodeRuntime, 0, 0)},
+	}
+
+	runVmTests(t, tests)
+
+	// The name is bound once, like a tetap constant
+	if err := compiler.New().Compile(parse(enum + "Status = 1")); err == nil {
+		t.Errorf("expected an error assigning to a pilihan")
+	}
+}
+
 func TestFunctionNoReturnValue(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -290,9 +681,21 @@ func runVmTests(t *testing.T, tests interface{}) {
 }
 
 func runVmTest(t *testing.T, input string, expected interface{}) {
+	runVmTestWith(t, compiler.New(), input, expected)
+}
+
+// runStrictVmTests runs the cases with the parameter checks of --strict.
+func runStrictVmTests(t *testing.T, tests []vmTestCase) {
+	for _, tt := range tests {
+		comp := compiler.New()
+		comp.Strict = true
+		runVmTestWith(t, comp, tt.input, tt.expected)
+	}
+}
+
+func runVmTestWith(t *testing.T, comp *compiler.Compiler, input string, expected interface{}) {
 	program := parse(input)
 
-	comp := compiler.New()
 	err := comp.Compile(program)
 	if err != nil {
 		t.Fatalf("compiler error: %s", err)
@@ -330,6 +733,28 @@ func testExpectedObject(t *testing.T, obj object.Object, expected interface{}) {
 		testBooleanObject(t, obj, expected)
 	case string:
 		testStringObject(t, obj, expected)
+	case *big.Int:
+		result, ok := obj.(*object.BigInt)
+		if !ok {
+			t.Errorf("object is not BigInt. got=%T (%+v)", obj, obj)
+			return
+		}
+		if result.GetValue().Cmp(expected) != 0 {
+			t.Errorf("object has wrong value. got=%s, want=%s", result.Inspect(), expected)
+		}
+	case object.DecimalValue:
+		result, ok := obj.(*object.Decimal)
+		if !ok {
+			if errObj, isErr := obj.(*object.Error); isErr {
+				t.Errorf("object is Error: %s", errObj.GetMessage())
+			} else {
+				t.Errorf("object is not Decimal. got=%T (%+v)", obj, obj)
+			}
+			return
+		}
+		if result.Inspect() != expected.String() {
+			t.Errorf("object has wrong value. got=%s, want=%s", result.Inspect(), expected)
+		}
 	case []int64:
 		result, ok := obj.(*object.Array)
 		if !ok {
@@ -384,6 +809,20 @@ func testExpectedObject(t *testing.T, obj object.Object, expected interface{}) {
 			}
 			testIntegerObject(t, val, expectedVal)
 		}
+	case []interface{}:
+		result, ok := obj.(*object.Array)
+		if !ok {
+			t.Errorf("object is not Array. got=%T (%+v)", obj, obj)
+			return
+		}
+		elements := result.GetElements()
+		if len(elements) != len(expected) {
+			t.Errorf("wrong num of elements. want=%d, got=%d", len(expected), len(elements))
+			return
+		}
+		for i, expectedVal := range expected {
+			testExpectedObject(t, elements[i], expectedVal)
+		}
 	case nil:
 		if obj == nil {
 			return
@@ -404,6 +843,9 @@ func testExpectedObject(t *testing.T, obj object.Object, expected interface{}) {
 		if result.GetMessage() != expected.GetMessage() {
 			t.Errorf("wrong error message. expected=%q, got=%q", expected.GetMessage(), result.GetMessage())
 		}
+		if expected.GetCode() != "" && result.GetCode() != expected.GetCode() {
+			t.Errorf("wrong error code. expected=%q, got=%q", expected.GetCode(), result.GetCode())
+		}
 	}
 }
 
@@ -0,0 +1,106 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/VzoelFox/morphlang/pkg/compiler"
+	"github.com/VzoelFox/morphlang/pkg/lexer"
+	"github.com/VzoelFox/morphlang/pkg/object"
+	"github.com/VzoelFox/morphlang/pkg/parser"
+)
+
+func TestASTJSONRoundTrip(t *testing.T) {
+	wd, _ := os.Getwd()
+	fixturesDir := filepath.Join(filepath.Dir(wd), "fixtures", "valid")
+
+	files, err := filepath.Glob(filepath.Join(fixturesDir, "*.fox"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no fixtures found in %s: %v", fixturesDir, err)
+	}
+
+	// Imports resolve against the working directory
+	if err := os.Chdir(fixturesDir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	for _, path := range files {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			content, _ := os.ReadFile(path)
+			p := parser.New(lexer.New(string(content)))
+			program := p.ParseProgram()
+			if len(p.Errors()) > 0 {
+				t.Fatalf("parser errors: %v", p.Errors())
+			}
+
+			data, err := json.Marshal(program)
+			if err != nil {
+				t.Fatalf("marshal failed: %v", err)
+			}
+
+			decoded := &parser.Program{}
+			if err := json.Unmarshal(data, decoded); err != nil {
+				t.Fatalf("unmarshal failed: %v", err)
+			}
+
+			again, err := json.Marshal(decoded)
+			if err != nil {
+				t.Fatalf("second marshal failed: %v", err)
+			}
+			if !bytes.Equal(data, again) {
+				t.Errorf("JSON changed after a round trip")
+			}
+
+			want := compileSummary(program)
+			got := compileSummary(decoded)
+			if got != want {
+				t.Errorf("bytecode differs after a round trip.\nwant:\n%s\ngot:\n%s", want, got)
+			}
+		})
+	}
+}
+
+func compileSummary(program *parser.Program) string {
+	comp := compiler.New()
+	if err := comp.Compile(program); err != nil {
+		return "error: " + err.Error()
+	}
+	bc := comp.Bytecode()
+
+	var out strings.Builder
+	out.WriteString(bc.Instructions.String())
+	for i, c := range bc.Constants {
+		// Function constants print their heap address; compare their code instead
+		if fn, ok := c.(*object.CompiledFunction); ok {
+			fmt.Fprintf(&out, "%d: fn/%d locals=%d\n%s", i, fn.NumParameters(), fn.NumLocals(),
+				compiler.Instructions(fn.Instructions()).String())
+			continue
+		}
+		fmt.Fprintf(&out, "%d: %s\n", i, c.Inspect())
+	}
+	return out.String()
+}
+
+func TestASTJSONRejectsWrongKinds(t *testing.T) {
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{`{"kind": "Identifier"}`, "expected Program"},
+		{`{"kind": "Program", "statements": [{"kind": "Nope"}]}`, "unknown node kind"},
+		{`{"kind": "Program", "statements": [{"kind": "Identifier"}]}`, "cannot be used as parser.Statement"},
+	}
+
+	for _, tt := range tests {
+		err := json.Unmarshal([]byte(tt.input), &parser.Program{})
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.input, tt.err, err)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package integration
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestREPL(t *testing.T) {
+	wd, _ := os.Getwd()
+	repoRoot := filepath.Dir(filepath.Dir(wd))
+	binPath := filepath.Join(t.TempDir(), "morphi")
+
+	buildCmd := exec.Command("go", "build", "-o", binPath, "./cmd/morphi")
+	buildCmd.Dir = repoRoot
+	buildCmd.Stderr = os.Stderr
+	if err := buildCmd.Run(); err != nil {
+		t.Fatalf("Failed to build morphi: %v", err)
+	}
+
+	input := strings.Join([]string{
+		"x = 5",
+		"fungsi tambah(a, b)",
+		"  kembalikan a + b",
+		"akhir",
+		"tambah(x, 10)",
+		":bytecode",
+		":ast",
+		":context",
+		":reset",
+		"x",
+		"a = 1; b = tidak_ada",
+		"a",
+		"",
+	}, "\n")
+
+	cmd := exec.Command(binPath)
+	cmd.Stdin = strings.NewReader(input)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("morphi failed: %v\n%s", err, out.String())
+	}
+	got := out.String()
+
+	expects := []string{
+		".. ",     // Continuation prompt inside the function body
+		">> 15\n", // Definitions survive between inputs
+		"OpCall",  // :bytecode of the last input
+		"tambah(x, 10)",
+		`"tambah"`, // :context lists the function
+		"Sesi direset.",
+		"undefined variable x", // :reset forgets it
+		"undefined variable tidak_ada",
+		"undefined variable a", // A failed input defines nothing
+	}
+	for _, want := range expects {
+		if !strings.Contains(got, want) {
+			t.Errorf("output does not contain %q.\nOutput:\n%s", want, got)
+		}
+	}
+}
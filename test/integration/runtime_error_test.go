@@ -0,0 +1,65 @@
+package integration
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestUncaughtGalatExitCode checks that a galat leaving the main program is
+// reported and fails the run instead of stopping it silently.
+func TestUncaughtGalatExitCode(t *testing.T) {
+	wd, _ := os.Getwd()
+	repoRoot := filepath.Dir(filepath.Dir(wd))
+	dir := t.TempDir()
+	binPath := filepath.Join(dir, "morph")
+
+	buildCmd := exec.Command("go", "build", "-o", binPath, "./cmd/morph")
+	buildCmd.Dir = repoRoot
+	buildCmd.Stderr = os.Stderr
+	if err := buildCmd.Run(); err != nil {
+		t.Fatalf("Failed to build morph: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"coba", "x = coba galat(\"boom\")\ncetak(\"sesudah\")\n", "uncaught galat: boom"},
+		{"destructuring", "[a, b] = [1]\ncetak(\"sesudah\")\n", "destructuring mismatch: want an array of 2 elements for [a, b]"},
+		{"field", "struktur T\n  x\nakhir\nt = T(x: 1)\nt.z = 5\ncetak(\"sesudah\")\n", "struktur T has no field 'z'"},
+		{"for-each", "untuk x dalam 5\n  cetak(x)\nakhir\ncetak(\"sesudah\")\n", "cannot iterate over integer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name+".fox")
+			if err := os.WriteFile(path, []byte(tt.source), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			cmd := exec.Command(binPath, "--vm", path)
+			cmd.Dir = dir
+			var out bytes.Buffer
+			cmd.Stdout = &out
+			cmd.Stderr = &out
+			err := cmd.Run()
+
+			var exitErr *exec.ExitError
+			if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+				t.Fatalf("expected exit code 1, got %v\nOutput: %s", err, out.String())
+			}
+			if !strings.Contains(out.String(), tt.want) {
+				t.Errorf("output does not contain %q.\nOutput: %s", tt.want, out.String())
+			}
+			if strings.Contains(out.String(), "sesudah") {
+				t.Errorf("program kept running after the galat.\nOutput: %s", out.String())
+			}
+		})
+	}
+}